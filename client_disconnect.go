@@ -0,0 +1,41 @@
+// Ortelius v11 package Microservice that handles creating and retrieving Dependencies
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const disconnectPollInterval = 250 * time.Millisecond // how often the connection of a request is checked for the client disconnecting
+
+// withClientDisconnect returns a context for the request that is cancelled when the client disconnects or the server
+// shuts down, as fasthttp only signals the latter. The cancel function must be called before the handler returns.
+func withClientDisconnect(c *fiber.Ctx) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	conn := c.Context().Conn()
+	shutdown := c.Context().Done()
+
+	go func() {
+		ticker := time.NewTicker(disconnectPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-shutdown:
+				cancel()
+				return
+			case <-ticker.C:
+				if connClosed(conn) {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	return ctx, cancel
+}
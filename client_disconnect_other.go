@@ -0,0 +1,11 @@
+//go:build !unix
+
+// Ortelius v11 package Microservice that handles creating and retrieving Dependencies
+package main
+
+import "net"
+
+// connClosed cannot peek at the connection on this platform, so the client is never considered to have disconnected
+func connClosed(_ net.Conn) bool {
+	return false
+}
@@ -0,0 +1,36 @@
+//go:build unix
+
+// Ortelius v11 package Microservice that handles creating and retrieving Dependencies
+package main
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+// connClosed checks if the peer has closed the connection by peeking at it without blocking, which
+// leaves any data sent on the connection, such as a pipelined request, for the server to read
+func connClosed(conn net.Conn) bool {
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return false
+	}
+
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return false
+	}
+
+	closed := false
+	buf := make([]byte, 1)
+
+	_ = raw.Read(func(fd uintptr) bool {
+		n, _, err := syscall.Recvfrom(int(fd), buf, syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+		closed = (n == 0 && err == nil) || errors.Is(err, syscall.ECONNRESET)
+
+		return true // done, rather than waiting for the connection to become readable
+	})
+
+	return closed
+}
@@ -0,0 +1,504 @@
+// Ortelius v11 package Microservice that handles creating and retrieving Dependencies
+package main
+
+import (
+	"context"
+	"crypto/sha1" //nolint:gosec // only used to derive vertex keys, not for security
+	"encoding/hex"
+	"strings"
+
+	"github.com/ortelius/scec-deppkg/models"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+// dependencyMaxDepth is the deepest that the dependencyGraph is traversed from the root component of an sbom
+const dependencyMaxDepth = 100
+
+// dependencyVertex represents a component of an sbom as a vertex of the dependencyGraph, where the vertices of each
+// sbom are kept apart so that the graph of an sbom is replaced as a whole when the sbom is uploaded again
+type dependencyVertex struct {
+	Key     string `json:"_key"`
+	SBOM    string `json:"sbom"`
+	Ref     string `json:"ref"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Purl    string `json:"purl,omitempty"`
+	Root    bool   `json:"root,omitempty"`
+}
+
+// dependencyEdge represents a relationship of the sbom from one component to another, which is DEPENDS_ON or CONTAINS
+type dependencyEdge struct {
+	From string `json:"_from"`
+	To   string `json:"_to"`
+	SBOM string `json:"sbom"`
+	Type string `json:"type"`
+}
+
+// DependencyPackage represents a package of an sbom along with whether the root component of the sbom
+// depends on it directly or transitively, and the shortest path from the root component that introduces it
+type DependencyPackage struct {
+	Key              string   `json:"key"`
+	CompID           string   `json:"compid"`
+	Ref              string   `json:"ref"`
+	Name             string   `json:"packagename"`
+	Version          string   `json:"packageversion"`
+	Purl             string   `json:"purl"`
+	Dependency       string   `json:"dependency"`
+	Depth            int      `json:"depth,omitempty"`
+	IntroductionPath []string `json:"introduction_path,omitempty"`
+}
+
+// DependencyNode represents a component in the dependency tree of another, where a component that is
+// reached again is deduped rather than listing its dependencies once more
+type DependencyNode struct {
+	Ref          string            `json:"ref"`
+	Name         string            `json:"packagename"`
+	Version      string            `json:"packageversion,omitempty"`
+	Purl         string            `json:"purl,omitempty"`
+	Relationship string            `json:"relationship,omitempty"`
+	Deduped      bool              `json:"deduped,omitempty"`
+	Dependencies []*DependencyNode `json:"dependencies"`
+}
+
+// dependencyRef returns the ref that identifies a component within the dependencyGraph of its sbom, which is
+// the ref of the component or, for a component without one such as a nested SPDX file, its purl or name and version
+func dependencyRef(ref, purl, name, version string) string {
+	switch {
+	case ref != "":
+		return ref
+	case purl != "":
+		return purl
+	case version != "":
+		return name + "@" + version
+	}
+
+	return name
+}
+
+// dependencyVertexKey returns the key of the vertex of the component of the sbom
+func dependencyVertexKey(sbomKey, ref string) string {
+	sum := sha1.Sum([]byte(ref)) //nolint:gosec // only used to derive vertex keys, not for security
+	return sbomKey + "-" + hex.EncodeToString(sum[:])
+}
+
+// ensureDependencyGraph creates the collections and dependencyGraph that the relationships of the sboms are stored in,
+// if they do not exist, which is done once at startup
+func ensureDependencyGraph(ctx context.Context) error {
+	collections := []struct {
+		name           string
+		collectionType arangodb.CollectionType
+	}{
+		{"sbomcomponents", arangodb.CollectionTypeDocument},
+		{"sbomdependencies", arangodb.CollectionTypeEdge},
+	}
+
+	for _, c := range collections {
+		col, err := ensureCollection(ctx, c.name, c.collectionType)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create collection %s", c.name)
+		}
+
+		// the vertices and edges of an sbom are replaced together when it is uploaded again
+		if _, _, err = col.EnsurePersistentIndex(ctx, []string{"sbom"}, nil); err != nil {
+			return errors.Wrapf(err, "failed to index %s", c.name)
+		}
+	}
+
+	exists, err := dbconn.Database.GraphExists(ctx, "dependencyGraph")
+	if err != nil {
+		return errors.Wrap(err, "failed to check for dependencyGraph")
+	}
+
+	if !exists {
+		definition := &arangodb.GraphDefinition{
+			Name:            "dependencyGraph",
+			EdgeDefinitions: []arangodb.EdgeDefinition{{Collection: "sbomdependencies", From: []string{"sbomcomponents"}, To: []string{"sbomcomponents"}}},
+		}
+
+		if _, err = dbconn.Database.CreateGraph(ctx, "dependencyGraph", definition, nil); err != nil {
+			return errors.Wrap(err, "failed to create dependencyGraph")
+		}
+	}
+
+	return nil
+}
+
+// storeDependencyGraph replaces the vertices and edges of the sbom in the dependencyGraph with its components,
+// including the root component, and the DEPENDS_ON and CONTAINS relationships between them, in a single
+// transaction so that the graph of the sbom is never left partly replaced
+func storeDependencyGraph(ctx context.Context, sbomKey string, sbom *models.NormalizedSBOM) error {
+	vertices := []dependencyVertex{}
+	ids := make(map[string]string) // vertex id of each ref
+
+	addVertex := func(c models.Component, root bool) {
+		ref := dependencyRef(c.Ref, c.Purl, c.Name, c.Version)
+		if _, ok := ids[ref]; ok {
+			return
+		}
+
+		vertex := dependencyVertex{
+			Key:     dependencyVertexKey(sbomKey, ref),
+			SBOM:    sbomKey,
+			Ref:     ref,
+			Name:    c.Name,
+			Version: c.Version,
+			Purl:    c.Purl,
+			Root:    root,
+		}
+
+		ids[ref] = "sbomcomponents/" + vertex.Key
+		vertices = append(vertices, vertex)
+	}
+
+	if sbom.Root != nil {
+		addVertex(*sbom.Root, true)
+	}
+
+	for _, c := range sbom.Components {
+		addVertex(c, false)
+	}
+
+	edges := []dependencyEdge{}
+	seen := make(map[dependencyEdge]bool)
+
+	for _, r := range sbom.Relationships {
+		if r.Type != models.RelationshipDependsOn && r.Type != models.RelationshipContains {
+			continue
+		}
+
+		from, to := ids[r.From], ids[r.To]
+
+		// relationships to elements that are not components, such as SPDX files, are not part of the graph
+		if from == "" || to == "" || from == to {
+			continue
+		}
+
+		edge := dependencyEdge{From: from, To: to, SBOM: sbomKey, Type: r.Type}
+
+		if !seen[edge] {
+			seen[edge] = true
+			edges = append(edges, edge)
+		}
+	}
+
+	queries := []struct {
+		aql        string
+		parameters map[string]interface{}
+	}{
+		{
+			`FOR edge IN sbomdependencies
+				FILTER edge.sbom == @sbom
+				REMOVE edge IN sbomdependencies`,
+			map[string]interface{}{"sbom": sbomKey},
+		},
+		{
+			`FOR vertex IN sbomcomponents
+				FILTER vertex.sbom == @sbom
+				REMOVE vertex IN sbomcomponents`,
+			map[string]interface{}{"sbom": sbomKey},
+		},
+		{
+			`FOR vertex IN @vertices
+				INSERT vertex INTO sbomcomponents`,
+			map[string]interface{}{"vertices": vertices},
+		},
+		{
+			`FOR edge IN @edges
+				INSERT edge INTO sbomdependencies`,
+			map[string]interface{}{"edges": edges},
+		},
+	}
+
+	collections := arangodb.TransactionCollections{Write: []string{"sbomcomponents", "sbomdependencies"}}
+
+	err := dbconn.Database.WithTransaction(ctx, collections, nil, nil, nil, func(ctx context.Context, t arangodb.Transaction) error {
+		for _, q := range queries {
+			cursor, err := t.Query(ctx, q.aql, &arangodb.QueryOptions{BindVars: q.parameters})
+			if err != nil {
+				return err
+			}
+
+			cursor.Close()
+		}
+
+		return nil
+	})
+
+	return errors.Wrap(err, "failed to store the dependency graph")
+}
+
+// getDependencies returns the packages of the sboms of the keys, classified as direct or transitive dependencies of the
+// root component of their sbom by a breadth first traversal of the dependencyGraph, which finds the shortest path to each
+func getDependencies(ctx context.Context, keys []string) ([]*DependencyPackage, error) {
+	packages := []*DependencyPackage{}
+
+	parameters := map[string]interface{}{
+		"keys": requestedSBOMs(keys),
+	}
+
+	aql := `FOR k IN @keys
+				FOR sbom IN sbom
+				FILTER sbom._key == k.key OR sbom.cid == k.key
+				RETURN {
+					"key": sbom._key,
+					"requested": k.compid,
+					"components": (FOR v IN sbomcomponents FILTER v.sbom == sbom._key RETURN v),
+					"edges": (FOR e IN sbomdependencies FILTER e.sbom == sbom._key RETURN e)
+				}`
+
+	cursor, err := dbconn.Database.Query(ctx, aql, &arangodb.QueryOptions{BindVars: parameters})
+	if err != nil {
+		logger.Sugar().Errorf("Failed to run dependencies query: %v", err)
+		return nil, errors.Wrap(err, "failed to run dependencies query")
+	}
+
+	defer cursor.Close() // close the cursor when returning from this function
+
+	for cursor.HasMore() { // sbom found
+
+		var doc struct {
+			Key        string             `json:"key"`
+			Requested  string             `json:"requested"`
+			Components []dependencyVertex `json:"components"`
+			Edges      []dependencyEdge   `json:"edges"`
+		}
+
+		if _, err = cursor.ReadDocument(ctx, &doc); err != nil {
+			logger.Sugar().Errorf("Failed to read dependencies document: %v", err)
+			return nil, errors.Wrap(err, "failed to read dependencies document")
+		}
+
+		vertices := make([]models.DependencyVertex, 0, len(doc.Components))
+		edges := make([]models.DependencyEdge, 0, len(doc.Edges))
+
+		for _, v := range doc.Components {
+			vertices = append(vertices, models.DependencyVertex{ID: "sbomcomponents/" + v.Key, Name: v.Name, Version: v.Version, Root: v.Root})
+		}
+
+		for _, e := range doc.Edges {
+			edges = append(edges, models.DependencyEdge{From: e.From, To: e.To})
+		}
+
+		paths := models.ClassifyDependencies(vertices, edges, dependencyMaxDepth)
+
+		for _, v := range doc.Components {
+			path, ok := paths["sbomcomponents/"+v.Key]
+			if !ok { // the root component
+				continue
+			}
+
+			packages = append(packages, &DependencyPackage{
+				Key:              doc.Key,
+				CompID:           doc.Requested,
+				Ref:              v.Ref,
+				Name:             v.Name,
+				Version:          v.Version,
+				Purl:             v.Purl,
+				Dependency:       path.Dependency,
+				Depth:            path.Depth,
+				IntroductionPath: path.IntroductionPath,
+			})
+		}
+	}
+
+	return packages, nil
+}
+
+// getDependencyTree returns the dependency tree of the component of the sbom, or of its root component when
+// no ref is given, where the sbom has no root component the trees of the components that nothing depends on are returned
+func getDependencyTree(ctx context.Context, key, ref string, depth int) ([]*DependencyNode, error) {
+	requested := requestedSBOMs([]string{key})
+	if len(requested) == 0 {
+		return []*DependencyNode{}, nil
+	}
+
+	parameters := map[string]interface{}{
+		"key": requested[0]["key"],
+	}
+
+	aql := `FOR sbom IN sbom
+				FILTER sbom._key == @key OR sbom.cid == @key
+				LIMIT 1
+				RETURN {
+					"components": (FOR v IN sbomcomponents FILTER v.sbom == sbom._key RETURN v),
+					"edges": (FOR e IN sbomdependencies FILTER e.sbom == sbom._key RETURN e)
+				}`
+
+	cursor, err := dbconn.Database.Query(ctx, aql, &arangodb.QueryOptions{BindVars: parameters})
+	if err != nil {
+		logger.Sugar().Errorf("Failed to run dependency tree query: %v", err)
+		return nil, errors.Wrap(err, "failed to run dependency tree query")
+	}
+
+	defer cursor.Close() // close the cursor when returning from this function
+
+	var doc struct {
+		Components []dependencyVertex `json:"components"`
+		Edges      []dependencyEdge   `json:"edges"`
+	}
+
+	if !cursor.HasMore() {
+		return []*DependencyNode{}, nil
+	}
+
+	if _, err = cursor.ReadDocument(ctx, &doc); err != nil {
+		logger.Sugar().Errorf("Failed to read dependency tree document: %v", err)
+		return nil, errors.Wrap(err, "failed to read dependency tree document")
+	}
+
+	vertices := make(map[string]dependencyVertex, len(doc.Components))
+	outbound := make(map[string][]dependencyEdge)
+	inbound := make(map[string]bool)
+
+	for _, v := range doc.Components {
+		vertices["sbomcomponents/"+v.Key] = v
+	}
+
+	for _, e := range doc.Edges {
+		outbound[e.From] = append(outbound[e.From], e)
+		inbound[e.To] = true
+	}
+
+	starts := []string{}
+	orphans := []string{} // components that nothing depends on or contains
+
+	for _, v := range doc.Components {
+		id := "sbomcomponents/" + v.Key
+
+		switch {
+		case ref != "":
+			if v.Ref == ref || v.Purl == ref {
+				starts = append(starts, id)
+			}
+		case v.Root:
+			starts = append(starts, id)
+		case !inbound[id]:
+			orphans = append(orphans, id)
+		}
+	}
+
+	if ref == "" && len(starts) == 0 {
+		starts = orphans
+	}
+
+	visited := make(map[string]bool)
+
+	var expand func(id, relationship string, level int) *DependencyNode
+
+	expand = func(id, relationship string, level int) *DependencyNode {
+		v := vertices[id]
+		node := &DependencyNode{
+			Ref:          v.Ref,
+			Name:         v.Name,
+			Version:      v.Version,
+			Purl:         v.Purl,
+			Relationship: relationship,
+			Dependencies: []*DependencyNode{},
+		}
+
+		// a component is expanded once, so that the tree stays the size of the graph and cycles end
+		if visited[id] {
+			node.Deduped = len(outbound[id]) > 0
+			return node
+		}
+
+		visited[id] = true
+
+		if level >= depth {
+			return node
+		}
+
+		for _, e := range outbound[id] {
+			if _, ok := vertices[e.To]; ok {
+				node.Dependencies = append(node.Dependencies, expand(e.To, e.Type, level+1))
+			}
+		}
+
+		return node
+	}
+
+	trees := make([]*DependencyNode, 0, len(starts))
+
+	for _, id := range starts {
+		trees = append(trees, expand(id, "", 0))
+	}
+
+	return trees, nil
+}
+
+// dependencyPaths returns the classification and shortest introduction path of each package of the sboms of the keys,
+// keyed by the sbom key and the ref of the package in the dependencyGraph
+func dependencyPaths(ctx context.Context, keys []string) (map[string]map[string]*DependencyPackage, error) {
+	packages, err := getDependencies(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]map[string]*DependencyPackage)
+
+	for _, pkg := range packages {
+		if paths[pkg.Key] == nil {
+			paths[pkg.Key] = make(map[string]*DependencyPackage)
+		}
+
+		paths[pkg.Key][pkg.Ref] = pkg
+	}
+
+	return paths, nil
+}
+
+// GetDependencies godoc
+// @Summary Get the direct and transitive dependencies of an SBOM
+// @Description Get each package of the sboms, classified as a direct or transitive dependency of the root component of its sbom,
+// @Description along with the shortest path from the root component that introduces it. A package is unknown when the sbom has
+// @Description no root component or no relationships that lead to the package.
+// @Tags dependencies
+// @Produce json
+// @Param key query string true "comma separated sbom keys or component ids"
+// @Success 200
+// @Router /msapi/dependencies [get]
+func GetDependencies(c *fiber.Ctx) error {
+	packages, err := getDependencies(c.Context(), strings.Split(c.Query("key"), ","))
+	if err != nil {
+		logger.Sugar().Errorf("getDependencies returned %v", err)
+		packages = []*DependencyPackage{}
+	}
+
+	data := map[string]interface{}{
+		"data": packages,
+	}
+	return c.JSON(data)
+}
+
+// GetDependencyTree godoc
+// @Summary Get the dependency tree of a component of an SBOM
+// @Description Get the components that a component of the sbom depends on or contains, and theirs in turn, up to the depth.
+// @Description The tree is of the root component of the sbom unless the ref or purl of a component is given, and a component
+// @Description that is reached again is marked as deduped rather than listing its dependencies once more.
+// @Tags dependencies
+// @Produce json
+// @Param key query string true "sbom key or component id"
+// @Param ref query string false "ref or purl of the component, by default the root component of the sbom"
+// @Param depth query int false "deepest level of the tree, by default 100"
+// @Success 200
+// @Router /msapi/dependencies/tree [get]
+func GetDependencyTree(c *fiber.Ctx) error {
+	depth := c.QueryInt("depth", dependencyMaxDepth)
+	if depth <= 0 {
+		depth = dependencyMaxDepth
+	}
+
+	trees, err := getDependencyTree(c.Context(), c.Query("key"), c.Query("ref"), depth)
+	if err != nil {
+		logger.Sugar().Errorf("getDependencyTree returned %v", err)
+		trees = []*DependencyNode{}
+	}
+
+	data := map[string]interface{}{
+		"data": trees,
+	}
+	return c.JSON(data)
+}
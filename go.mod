@@ -3,12 +3,16 @@ module github.com/ortelius/scec-deppkg
 go 1.24.2
 
 require (
+	github.com/CycloneDX/cyclonedx-go v0.9.2
 	github.com/arangodb/go-driver/v2 v2.1.4
 	github.com/gofiber/fiber/v2 v2.52.9
 	github.com/ortelius/scec-commons v0.1.47
 	github.com/package-url/packageurl-go v0.1.3
+	github.com/pandatix/go-cvss v0.6.2
 	github.com/swaggo/swag v1.16.6
 	golang.org/x/exp v0.0.0-20250811191247-51f88131bc50
+	golang.org/x/mod v0.27.0
+	google.golang.org/protobuf v1.36.9
 )
 
 require (
@@ -21,7 +25,6 @@ require (
 	github.com/swaggo/files/v2 v2.0.2 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
 	go.uber.org/zap v1.27.0 // indirect
-	golang.org/x/mod v0.27.0 // indirect
 	golang.org/x/net v0.43.0 // indirect
 	golang.org/x/sync v0.16.0 // indirect
 	golang.org/x/text v0.28.0 // indirect
@@ -0,0 +1,364 @@
+// Ortelius v11 package Microservice that handles creating and retrieving Dependencies
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ortelius/scec-deppkg/models"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+	"github.com/pkg/errors"
+)
+
+const osvImportBatchSize = 500 // number of vulnerabilities written to the database per query
+
+// OSVImport represents a run of the import-osv command, which is recorded in the osvimports collection
+type OSVImport struct {
+	Key       string   `json:"_key"`
+	Revision  string   `json:"revision"`
+	Sources   []string `json:"sources"`
+	Started   string   `json:"started"`
+	Finished  string   `json:"finished"`
+	Added     int      `json:"added"`
+	Updated   int      `json:"updated"`
+	Unchanged int      `json:"unchanged"`
+	Invalid   int      `json:"invalid"`
+}
+
+// osvImporter writes the vulnerabilities into the vulns collection and links the purls of their affected packages through the vulnGraph
+type osvImporter struct {
+	ctx     context.Context
+	edges   string // edge collection of the vulnGraph from purls to vulns
+	batcher *models.OSVBatcher
+	result  OSVImport
+}
+
+// osvImportKey returns the key that an import is recorded by, which is the time that it finished to the nanosecond along
+// with a hash of its sources, so that imports which finish within the same second are each recorded in the order they finished
+func osvImportKey(finished time.Time, sources []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(sources, "\n")))
+
+	return finished.UTC().Format("20060102T150405.000000000Z") + "-" + hex.EncodeToString(sum[:4])
+}
+
+// ensureCollection returns the collection, creating it with the type if it does not exist
+func ensureCollection(ctx context.Context, name string, collectionType arangodb.CollectionType) (arangodb.Collection, error) {
+	exists, err := dbconn.Database.CollectionExists(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		return dbconn.Database.Collection(ctx, name)
+	}
+
+	return dbconn.Database.CreateCollection(ctx, name, &arangodb.CreateCollectionProperties{Type: collectionType})
+}
+
+// ensureVulnGraph creates the collections and vulnGraph used by GetCVEs if they do not exist, returning
+// the name of the edge collection from purls to vulns, which is read from the graph if it already exists
+func ensureVulnGraph(ctx context.Context) (string, error) {
+	for _, name := range []string{"vulns", "purls", "osvimports"} {
+		if _, err := ensureCollection(ctx, name, arangodb.CollectionTypeDocument); err != nil {
+			return "", errors.Wrapf(err, "failed to create collection %s", name)
+		}
+	}
+
+	purls, err := dbconn.Database.Collection(ctx, "purls")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to open collection purls")
+	}
+
+	unique := true
+
+	if _, _, err = purls.EnsurePersistentIndex(ctx, []string{"purl"}, &arangodb.CreatePersistentIndexOptions{Unique: &unique}); err != nil {
+		return "", errors.Wrap(err, "failed to index purls")
+	}
+
+	exists, err := dbconn.Database.GraphExists(ctx, "vulnGraph")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to check for vulnGraph")
+	}
+
+	if exists {
+		graph, err := dbconn.Database.Graph(ctx, "vulnGraph", nil)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to open vulnGraph")
+		}
+
+		for _, def := range graph.EdgeDefinitions() {
+			if strings.Contains(strings.Join(def.From, ","), "purls") && strings.Contains(strings.Join(def.To, ","), "vulns") {
+				return def.Collection, nil
+			}
+		}
+
+		return "", errors.New("vulnGraph does not have an edge definition from purls to vulns")
+	}
+
+	if _, err = ensureCollection(ctx, "purl2vulns", arangodb.CollectionTypeEdge); err != nil {
+		return "", errors.Wrap(err, "failed to create collection purl2vulns")
+	}
+
+	definition := &arangodb.GraphDefinition{
+		Name:            "vulnGraph",
+		EdgeDefinitions: []arangodb.EdgeDefinition{{Collection: "purl2vulns", From: []string{"purls"}, To: []string{"vulns"}}},
+	}
+
+	if _, err = dbconn.Database.CreateGraph(ctx, "vulnGraph", definition, nil); err != nil {
+		return "", errors.Wrap(err, "failed to create vulnGraph")
+	}
+
+	return "purl2vulns", nil
+}
+
+// loadModified reads the modified time of the vulnerabilities that are already stored
+func (imp *osvImporter) loadModified() (map[string]time.Time, error) {
+	modified := make(map[string]time.Time)
+
+	aql := `FOR vuln IN vulns
+				RETURN { "key": vuln._key, "modified": vuln.modified }`
+
+	cursor, err := dbconn.Database.Query(imp.ctx, aql, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run modified query")
+	}
+
+	defer cursor.Close()
+
+	for cursor.HasMore() {
+		var doc struct {
+			Key      string    `json:"key"`
+			Modified time.Time `json:"modified"`
+		}
+
+		if _, err := cursor.ReadDocument(imp.ctx, &doc); err != nil {
+			return nil, errors.Wrap(err, "failed to read modified document")
+		}
+
+		modified[doc.Key] = doc.Modified
+	}
+
+	return modified, nil
+}
+
+// add validates a vulnerability read from the source and queues it to be written, unless it has not been modified since it was last imported
+func (imp *osvImporter) add(name string, data []byte) error {
+	entry, err := models.ParseOSVEntry(data)
+	if err != nil {
+		imp.result.Invalid++
+		logger.Sugar().Warnf("Skipping invalid OSV entry %s: %v", name, err)
+
+		return nil
+	}
+
+	return imp.batcher.Add(entry)
+}
+
+// write stores a batch of vulnerabilities, replacing the edges of each with those of its current affected packages
+func (imp *osvImporter) write(batch []models.OSVEntry) error {
+	docs := make([]map[string]interface{}, 0, len(batch))
+	ids := make([]string, 0, len(batch))
+	links := []map[string]string{}
+
+	for _, entry := range batch {
+		id := "vulns/" + entry.Key
+
+		docs = append(docs, entry.Doc)
+		ids = append(ids, id)
+
+		for _, purl := range entry.Vuln.AffectedPURLs() {
+			links = append(links, map[string]string{"purl": purl, "vuln": id})
+		}
+	}
+
+	queries := []struct {
+		aql        string
+		parameters map[string]interface{}
+	}{
+		{
+			`FOR doc IN @docs
+				UPSERT { _key: doc._key } INSERT doc REPLACE doc IN vulns`,
+			map[string]interface{}{"docs": docs},
+		},
+		{
+			`FOR edge IN @@edges
+				FILTER edge._to IN @ids
+				REMOVE edge IN @@edges`,
+			map[string]interface{}{"@edges": imp.edges, "ids": ids},
+		},
+		{
+			`FOR purl IN UNIQUE(@links[*].purl)
+				UPSERT { purl: purl } INSERT { purl: purl } UPDATE {} IN purls`,
+			map[string]interface{}{"links": links},
+		},
+		{
+			`FOR link IN @links
+				LET purl = FIRST(FOR p IN purls FILTER p.purl == link.purl RETURN p._id)
+				INSERT { _from: purl, _to: link.vuln } INTO @@edges`,
+			map[string]interface{}{"@edges": imp.edges, "links": links},
+		},
+	}
+
+	for _, q := range queries {
+		cursor, err := dbconn.Database.Query(imp.ctx, q.aql, &arangodb.QueryOptions{BindVars: q.parameters})
+		if err != nil {
+			return errors.Wrap(err, "failed to write OSV entries")
+		}
+
+		cursor.Close()
+	}
+
+	return nil
+}
+
+// walkOSV calls fn with the name and content of each .json file within the path, which is either a zip archive, such as
+// all.zip or the archive of a single ecosystem, or a directory of OSV json files, i.e. one per ecosystem
+func walkOSV(path string, fn func(name string, data []byte) error) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		return filepath.WalkDir(path, func(name string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(name, ".json") {
+				return err
+			}
+
+			data, err := os.ReadFile(name)
+			if err != nil {
+				return err
+			}
+
+			return fn(name, data)
+		})
+	}
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+
+	defer archive.Close()
+
+	for _, f := range archive.File {
+		if f.FileInfo().IsDir() || !strings.HasSuffix(f.Name, ".json") {
+			continue
+		}
+
+		r, err := f.Open()
+		if err != nil {
+			return err
+		}
+
+		data, err := io.ReadAll(r)
+		r.Close()
+
+		if err != nil {
+			return err
+		}
+
+		if err := fn(path+"!"+f.Name, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ImportOSV implements the import-osv command, which imports OSV all.zip archives or directories of OSV json
+// into the vulns collection along with the purls and vulnGraph edges of their affected packages.
+//
+// Only the vulnerabilities whose modified time differs from the stored one are written, unless -full is given,
+// and the import is recorded in the osvimports collection with a revision, which defaults to the latest modified time.
+func ImportOSV(args []string) error {
+	flags := flag.NewFlagSet("import-osv", flag.ContinueOnError)
+	full := flags.Bool("full", false, "replace every vulnerability, even if it has not been modified")
+	revision := flags.String("revision", "", "revision of the OSV data to record, i.e. the etag or date of all.zip")
+
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s import-osv [-full] [-revision REV] <all.zip|directory>...\n", filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("no OSV archives or directories given")
+	}
+
+	ctx := context.Background()
+
+	edges, err := ensureVulnGraph(ctx)
+	if err != nil {
+		return err
+	}
+
+	imp := &osvImporter{
+		ctx:   ctx,
+		edges: edges,
+		result: OSVImport{
+			Sources: flags.Args(),
+			Started: formatTime(time.Now()),
+		},
+	}
+
+	modified, err := imp.loadModified()
+	if err != nil {
+		return err
+	}
+
+	imp.batcher = models.NewOSVBatcher(modified, *full, osvImportBatchSize, imp.write)
+
+	for _, path := range flags.Args() {
+		if err := walkOSV(path, imp.add); err != nil {
+			return errors.Wrapf(err, "failed to import %s", path)
+		}
+	}
+
+	if err := imp.batcher.Flush(); err != nil {
+		return err
+	}
+
+	imp.result.Revision = imp.batcher.Revision
+	imp.result.Added = imp.batcher.Added
+	imp.result.Updated = imp.batcher.Updated
+	imp.result.Unchanged = imp.batcher.Unchanged
+
+	if *revision != "" {
+		imp.result.Revision = *revision
+	}
+
+	finished := time.Now()
+
+	imp.result.Finished = formatTime(finished)
+	imp.result.Key = osvImportKey(finished, imp.result.Sources)
+
+	imports, err := dbconn.Database.Collection(ctx, "osvimports")
+	if err != nil {
+		return errors.Wrap(err, "failed to open collection osvimports")
+	}
+
+	if _, err := imports.CreateDocument(ctx, imp.result); err != nil {
+		return errors.Wrap(err, "failed to record the import")
+	}
+
+	logger.Sugar().Infof("Imported OSV revision %s: %d added, %d updated, %d unchanged, %d invalid",
+		imp.result.Revision, imp.result.Added, imp.result.Updated, imp.result.Unchanged, imp.result.Invalid)
+
+	return nil
+}
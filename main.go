@@ -6,19 +6,25 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"mime"
 	"net/http"
 	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"runtime"
 	"runtime/debug"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	_ "github.com/ortelius/scec-deppkg/docs"
 	"github.com/ortelius/scec-deppkg/models"
 
 	"github.com/arangodb/go-driver/v2/arangodb"
 	"github.com/arangodb/go-driver/v2/arangodb/shared"
-	"github.com/goark/go-cvss/v2/metric"
-	metric_v3 "github.com/goark/go-cvss/v3/metric"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/compress"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -31,6 +37,9 @@ import (
 var logger = database.InitLogger()
 var dbconn = database.InitializeDatabase()
 var licensesMap = make(map[string]License)
+var idPreference = models.ParseIDPreference(database.GetEnvDefault("VULN_ID_PREFERENCE", "CVE,GHSA")) // order of the ids used as the primary id of aliased vulnerabilities
+var sourceMap = models.NewSourceMap()                                                                 // binary to source packages for sboms that do not record the source package
+var cpeIndex = models.NewCPEIndex()                                                                   // cves imported from nvd feeds for matching components by cpe
 
 // License represents the structure of each license in the JSON data
 type License struct {
@@ -49,6 +58,121 @@ type Licenses struct {
 	Licenses []License `json:"licenses"`
 }
 
+// PackageCVE represents a vulnerability found in a package, along with the lowest version that fixes it
+// and the version recommended to upgrade to, which is the lowest that fixes every vulnerability in the package,
+// the severity entry that the score of the vulnerability was calculated from and when it was published,
+// last modified and withdrawn, and whether the package is a direct or transitive dependency along with the
+// shortest path from the root component of the sbom that introduces it
+type PackageCVE struct {
+	*model.PackageCVE
+	Aliases            []string            `json:"aliases,omitempty"`
+	Related            []string            `json:"related,omitempty"`
+	FixedIn            string              `json:"fixed_in"`
+	RecommendedVersion string              `json:"recommended_version"`
+	NoFix              bool                `json:"no_fix"`
+	Published          string              `json:"published,omitempty"`
+	Modified           string              `json:"modified,omitempty"`
+	Withdrawn          string              `json:"withdrawn,omitempty"`
+	ScoreSource        string              `json:"score_source,omitempty"`
+	ScoreType          models.SeverityType `json:"score_type,omitempty"`
+	ScoreVector        string              `json:"score_vector,omitempty"`
+	MatchMethod        string              `json:"match_method"`
+	MatchCriteria      string              `json:"match_criteria,omitempty"`
+	Dependency         string              `json:"dependency,omitempty"`
+	IntroductionPath   []string            `json:"introduction_path,omitempty"`
+}
+
+// Methods of matching a package to a vulnerability
+const (
+	MatchMethodPurl = "purl" // the purl of the package was found in the advisory
+	MatchMethodName = "name" // the name of the package was found in the advisory, as it has no purl
+	MatchMethodCPE  = "cpe"  // the cpe of the package matched the configurations of a NVD CVE
+)
+
+// SourceMapResult represents the number of binary packages imported into the source package mapping
+type SourceMapResult struct {
+	Imported int `json:"imported"`
+	Total    int `json:"total"`
+}
+
+// SBOMDocument represents an SBOM as it is stored, along with its components and relationships,
+// which are normalized from whichever format the SBOM was uploaded in so that they are queried the same way
+type SBOMDocument struct {
+	*model.SBOM
+	Normalized *models.NormalizedSBOM `json:"normalized,omitempty"`
+}
+
+// sbomComponentsAQL is the AQL expression for the normalized components of the sbom, which falls back to
+// reading the CycloneDX components of the content for sboms that were stored before they were normalized
+const sbomComponentsAQL = `(sbom.normalized != null ? sbom.normalized.components : (
+					FOR c IN NOT_NULL(sbom.content.components, [])
+						RETURN merge(c, {
+							"licenses": (
+								FOR lic IN NOT_NULL(c.licenses, [])
+									FILTER lic.license != null
+									RETURN LENGTH(lic.license.id) > 0 ? lic.license.id : SPLIT(lic.license.name, "----")[0]
+							)
+						})
+				))`
+
+// sbomOperatingSystemAQL is the AQL expression for the operating system of the sbom, i.e. "debian-12"
+const sbomOperatingSystemAQL = `(sbom.normalized != null ? sbom.normalized.os : FIRST(
+					FOR c IN APPEND([sbom.content.metadata.component], sbom.content.components)
+						FILTER c.type == "operating-system"
+						RETURN CONCAT_SEPARATOR("-", c.name, c.version)
+				))`
+
+// IndeterminateCVE represents a vulnerability that could not be checked against a package,
+// such as when the package version or the affected versions fail to parse
+type IndeterminateCVE struct {
+	Key        string             `json:"key"`
+	CompID     string             `json:"compid"`
+	Name       string             `json:"packagename"`
+	Version    string             `json:"packageversion"`
+	Purl       string             `json:"purl"`
+	CVE        string             `json:"cve"`
+	Reason     string             `json:"reason"`
+	ParseError *models.ParseError `json:"parse_error,omitempty"`
+}
+
+// VersionCompareResult represents the result of comparing two versions for an ecosystem
+type VersionCompareResult struct {
+	Ecosystem models.Ecosystem `json:"ecosystem"`
+	A         string           `json:"a"`
+	B         string           `json:"b"`
+	Result    int              `json:"result"`
+}
+
+// VersionSortRequest represents a list of versions to be sorted for an ecosystem
+type VersionSortRequest struct {
+	Ecosystem  models.Ecosystem `json:"ecosystem"`
+	Versions   []string         `json:"versions"`
+	Descending bool             `json:"descending,omitempty"`
+}
+
+// VersionInRangeRequest represents a version, or commit for a GIT range, to be checked against
+// either an OSV range or a constraint written in the native syntax of the ecosystem
+type VersionInRangeRequest struct {
+	Ecosystem  models.Ecosystem `json:"ecosystem"`
+	Version    string           `json:"version"`
+	Commit     string           `json:"commit,omitempty"`
+	Range      *models.Range    `json:"range,omitempty"`
+	Constraint string           `json:"constraint,omitempty"`
+}
+
+// SBOMError represents an SBOM that could not be read, including where it failed to parse
+type SBOMError struct {
+	Error      string                 `json:"error"`
+	ParseError *models.SBOMParseError `json:"parse_error,omitempty"`
+}
+
+// VersionError represents a version request that could not be evaluated,
+// including the details of any version or range that failed to parse
+type VersionError struct {
+	Error       string               `json:"error"`
+	ParseErrors []*models.ParseError `json:"parse_errors,omitempty"`
+}
+
 // fetchAndParseLicenses fetches the JSON data from the URL and parses it into a map
 func fetchAndParseLicenses(licensesMap map[string]License) {
 
@@ -150,16 +274,8 @@ func GetPackages(c *fiber.Ctx) error {
 
 	// query all the package in the collection
 	aql := `FOR sbom IN sbom
-			FOR packages IN sbom.content.components
-				LET lics = LENGTH(packages.licenses) > 0
-				? (FOR lic IN packages.licenses
-					FILTER LENGTH(packages.licenses) > 0
-						LET id = LENGTH(lic.license.id) > 0
-						? lic.license.id
-						: SPLIT(lic.license.name, "----")[0]
-						RETURN id
-					)
-				: [""]
+			FOR packages IN ` + sbomComponentsAQL + `
+				LET lics = LENGTH(packages.licenses) > 0 ? packages.licenses : [""]
 
 				LET pkgType = SPLIT(SPLIT(packages.purl, ":")[1], "/")[0]
 
@@ -176,16 +292,8 @@ func GetPackages(c *fiber.Ctx) error {
 
 	if pkgversion != "" {
 		aql = `FOR sbom IN sbom
-		FOR packages IN sbom.content.components
-			LET lics = LENGTH(packages.licenses) > 0
-			? (FOR lic IN packages.licenses
-				FILTER LENGTH(packages.licenses) > 0
-					LET id = LENGTH(lic.license.id) > 0
-					? lic.license.id
-					: SPLIT(lic.license.name, "----")[0]
-					RETURN id
-				)
-			: [""]
+		FOR packages IN ` + sbomComponentsAQL + `
+			LET lics = LENGTH(packages.licenses) > 0 ? packages.licenses : [""]
 
 			LET pkgType = SPLIT(SPLIT(packages.purl, ":")[1], "/")[0]
 
@@ -238,7 +346,10 @@ func GetPackages(c *fiber.Ctx) error {
 // @Tags package
 // @Accept */*
 // @Produce json
+// @Param withdrawn query bool false "include withdrawn vulnerabilities"
+// @Param asof query string false "only report vulnerabilities published by this RFC 3339 timestamp or YYYY-MM-DD date"
 // @Success 200
+// @Failure 400
 // @Router /msapi/package/:key [get]
 func GetPackages4SBOM(c *fiber.Ctx) error {
 
@@ -259,18 +370,49 @@ func GetPackages4SBOM(c *fiber.Ctx) error {
 		return c.JSON(data)
 	}
 
-	cvedata, err := GetCVEs(keys)
+	filter := models.VulnerabilityFilter{
+		IncludeWithdrawn: c.QueryBool("withdrawn", false),
+	}
+
+	if asof := c.Query("asof"); asof != "" {
+		var err error
+
+		if filter.AsOf, err = parseAsOf(asof); err != nil {
+			return c.Status(fiber.StatusBadRequest).Send([]byte(err.Error()))
+		}
+	}
+
+	// the evaluation is abandoned if the client disconnects before it is finished
+	ctx, cancel := withClientDisconnect(c)
+	defer cancel()
+
+	cvedata, indeterminate, err := GetCVEs(ctx, keys, filter)
 
 	if err != nil {
 		logger.Sugar().Errorf("GetCVEs returned %v", err)
 	}
 
 	data := map[string]interface{}{
-		"data": cvedata,
+		"data":          cvedata,
+		"indeterminate": indeterminate,
 	}
 	return c.JSON(data)
 }
 
+// parseAsOf parses an RFC 3339 timestamp or a date, where a date is treated as the end of that day in UTC
+func parseAsOf(asof string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, asof); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.DateOnly, asof)
+	if err != nil {
+		return t, errors.Errorf("invalid asof %q: expected an RFC 3339 timestamp or a YYYY-MM-DD date", asof)
+	}
+
+	return t.Add(24*time.Hour - time.Nanosecond), nil
+}
+
 // GetLicenses will return a list of packages and corresponding licenses
 func GetLicenses(keys []string) []*model.PackageLicense {
 	var cursor arangodb.Cursor            // db cursor for rows
@@ -297,17 +439,9 @@ func GetLicenses(keys []string) []*model.PackageLicense {
 		// query the packages that match the key or name
 		aql := `FOR sbom IN sbom
 			FILTER sbom._key == @key OR sbom.cid == @key
-			FOR packages IN sbom.content.components
+			FOR packages IN ` + sbomComponentsAQL + `
 				FILTER LENGTH(packages.name) > 0
-				LET lics = LENGTH(packages.licenses) > 0
-				? (FOR lic IN packages.licenses
-					FILTER LENGTH(packages.licenses) > 0
-						LET id = LENGTH(lic.license.id) > 0
-						? lic.license.id
-						: SPLIT(lic.license.name, "----")[0]
-						RETURN id
-					)
-				: [""]
+				LET lics = LENGTH(packages.licenses) > 0 ? packages.licenses : [""]
 
 				LET pkgType = SPLIT(SPLIT(packages.purl, ":")[1], "/")[0]
 
@@ -358,7 +492,7 @@ func Purl2Comp(dhurl string, cookies []*http.Cookie, key string) {
 
 	aql := `FOR sbom IN sbom
 			FILTER sbom._key == @key OR sbom.cid == @key
-			FOR packages IN sbom.content.components
+			FOR packages IN ` + sbomComponentsAQL + `
 				LET purl = packages.purl != null ? packages.purl : CONCAT("pkg:swid/", packages.swid.name, "@", packages.swid.version, "?tag_id=", packages.swid.tagId)
 
 				RETURN {
@@ -390,6 +524,10 @@ func Purl2Comp(dhurl string, cookies []*http.Cookie, key string) {
 
 		purl := PurlPayload{Purl: pkg.Purl}
 
+		if canonical, err := models.CanonicalPURL(pkg.Purl); err == nil {
+			purl.Purl = canonical
+		}
+
 		// Marshal the JSON data into a byte array
 		jsonData, err := json.Marshal(purl)
 		if err != nil {
@@ -442,13 +580,157 @@ func Purl2Comp(dhurl string, cookies []*http.Cookie, key string) {
 	}
 }
 
-// GetCVEs will return a list of packages that have CVEs
-func GetCVEs(keys []string) ([]*model.PackageCVE, error) {
-	var cursor arangodb.Cursor        // db cursor for rows
-	var purlCursor arangodb.Cursor    // db cursor for rows
-	var err error                     // for error handling
-	var ctx = context.Background()    // use default database context
-	packages := []*model.PackageCVE{} // list of packages in the SBOM
+// formatTime formats the time as RFC 3339 in UTC, or as an empty string if it is not set
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+// resolveSource returns the source package that the package was built from, using the upstream or source qualifier
+// of its purl, then the properties emitted by syft or trivy, then the imported binary to source mapping
+func resolveSource(pkgInfo models.PackageInfo, properties []models.ComponentProperty) models.SourcePackage {
+	if pkgInfo.Source.Name != "" {
+		return pkgInfo.Source
+	}
+
+	props := make(map[string]string, len(properties))
+
+	for _, p := range properties {
+		props[p.Name] = p.Value
+	}
+
+	if source := models.SourceFromProperties(props); source.Name != "" {
+		return source
+	}
+
+	source, _ := sourceMap.Lookup(models.Ecosystem(pkgInfo.Ecosystem), pkgInfo.Name)
+
+	return source
+}
+
+// newPackageCVE creates the cve row for a group of vulnerabilities affecting the package, using the summary of
+// the primary record and the highest score of any of the records, as databases often score the same flaw differently
+func newPackageCVE(pkg *model.PackageCVE, group models.VulnerabilityGroup, osvPkg models.PackageDetails) *PackageCVE {
+	cvepkg := model.NewPackageCVE()
+
+	cvepkg.Key = pkg.Key
+	cvepkg.CompID = pkg.CompID
+	cvepkg.Language = pkg.Language
+	cvepkg.Name = pkg.Name
+	cvepkg.URL = pkg.URL
+	cvepkg.Purl = pkg.Purl
+	cvepkg.Version = pkg.Version
+	cvepkg.CVE = group.ID
+	cvepkg.Summary = group.Primary().Summary
+
+	row := &PackageCVE{
+		PackageCVE: cvepkg,
+		Aliases:    group.Aliases,
+		Related:    group.Related,
+		Published:  formatTime(group.Primary().Published),
+		Modified:   formatTime(group.Primary().Modified),
+		Withdrawn:  formatTime(group.Primary().Withdrawn),
+	}
+
+	score := models.VulnerabilityScore{Severity: "None"}
+
+	// each vulnerability is scored on its own, from the highest scoring of its severities
+	for _, vuln := range group.Vulnerabilities {
+		if s := models.ScoreVulnerability(vuln, osvPkg); s.Source != "" && (score.Source == "" || s.Score > score.Score) {
+			score = s
+		}
+
+		if cvepkg.Summary == "" {
+			cvepkg.Summary = vuln.Summary
+		}
+	}
+
+	cvepkg.Score = score.Score
+	cvepkg.Severity = score.Severity
+
+	row.ScoreSource = score.Source
+	row.ScoreType = score.Type
+	row.ScoreVector = score.Vector
+
+	return row
+}
+
+// cpeFindings returns the cve rows for the NVD CVEs whose configurations match the cpe of the package,
+// other than those already reported for the package by their ID or one of their aliases
+func cpeFindings(pkg *model.PackageCVE, cpe string, filter models.VulnerabilityFilter, reported map[string]bool) []*PackageCVE {
+	parsed, err := models.ParseCPE(cpe)
+	if err != nil {
+		logger.Sugar().Warnf("Failed to parse the cpe of %s: %v", pkg.Name, err)
+		return nil
+	}
+
+	matches := []models.CPEMatch{}
+
+	for _, m := range cpeIndex.Match(parsed, pkg.Version) {
+		if !filter.Includes(m.Vulnerability) || reported[m.Vulnerability.ID] {
+			continue
+		}
+
+		if slices.ContainsFunc(m.Vulnerability.Aliases, func(alias string) bool { return reported[alias] }) {
+			continue
+		}
+
+		matches = append(matches, m)
+	}
+
+	recommended := models.RecommendedCPEVersion(matches)
+	rows := []*PackageCVE{}
+
+	for _, m := range matches {
+		row := newPackageCVE(pkg, models.VulnerabilityGroup{ID: m.Vulnerability.ID, Vulnerabilities: []models.Vulnerability{m.Vulnerability}}, models.PackageDetails{})
+
+		row.FixedIn = m.FixedIn
+		row.RecommendedVersion = recommended
+		row.NoFix = row.FixedIn == ""
+		row.MatchMethod = MatchMethodCPE
+		row.MatchCriteria = m.Criteria
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// cveComponent represents a component of an sbom along with the package that its advisories are evaluated for
+type cveComponent struct {
+	pkg         *model.PackageCVE
+	cpe         string
+	osvPkgs     []models.PackageDetails // the package, and the source package it was built from if that differs
+	purl        string                  // purl of the package
+	purls       []string                // base purls that the advisories of the packages are linked to in the vulnGraph
+	names       []string                // names that the advisories are looked up by when the package has no valid purl
+	ref         string                  // ref of the component in the dependencyGraph of its sbom
+	graphed     bool                    // the sbom has relationships stored in the dependencyGraph
+	matchMethod string
+}
+
+// cveResult represents the outcome of evaluating the advisories of a component
+type cveResult struct {
+	packages      []*PackageCVE
+	indeterminate []*IndeterminateCVE
+}
+
+// evalWorkers returns the number of components evaluated concurrently by GetCVEs
+func evalWorkers() int {
+	if workers, err := strconv.Atoi(database.GetEnvDefault("VULN_EVAL_WORKERS", "")); err == nil && workers > 0 {
+		return workers
+	}
+
+	return runtime.GOMAXPROCS(0)
+}
+
+// requestedSBOMs returns the sbom key of each of the keys along with the key as it was requested, where
+// each key is an sbom key or component id that may have an "ap", "av", "co" or "cv" prefix
+func requestedSBOMs(keys []string) []map[string]string {
+	requested := []map[string]string{}
 
 	for _, key := range keys {
 
@@ -462,168 +744,565 @@ func GetCVEs(keys []string) ([]*model.PackageCVE, error) {
 		key = strings.ReplaceAll(key, "co", "")
 		key = strings.ReplaceAll(key, "cv", "")
 
-		parameters := map[string]interface{}{ // parameters
-			"key": key,
-		}
+		requested = append(requested, map[string]string{"key": key, "compid": compid})
+	}
 
-		aql := `FOR sbom IN sbom
-				FILTER sbom._key == @key OR sbom.cid == @key
-				FOR packages IN sbom.content.components
+	return requested
+}
+
+// getSBOMComponents returns the components of the sboms of the keys with a single query, where
+// each key is an sbom key or component id that may have an "ap", "av", "co" or "cv" prefix
+func getSBOMComponents(ctx context.Context, keys []string) ([]*cveComponent, error) {
+	parameters := map[string]interface{}{ // parameters
+		"keys": requestedSBOMs(keys),
+	}
+
+	aql := `FOR k IN @keys
+				FOR sbom IN sbom
+				FILTER sbom._key == k.key OR sbom.cid == k.key
+				LET os = ` + sbomOperatingSystemAQL + `
+				LET graphed = LENGTH(FOR edge IN sbomdependencies FILTER edge.sbom == sbom._key LIMIT 1 RETURN 1) > 0
+				FOR packages IN ` + sbomComponentsAQL + `
 					LET purl = packages.purl != null ? packages.purl : CONCAT("pkg:swid/", packages.swid.name, "@", packages.swid.version, "?tag_id=", packages.swid.tagId)
 
 					RETURN {
 						"key": sbom._key,
+						"requested": k.compid,
 						"packagename": packages.name,
 						"packageversion": packages.version,
 						"purl": purl,
 						"cve": "",
-						"pkgtype": SPLIT(SPLIT(packages.purl, ":")[1], "/")[0]
+						"pkgtype": SPLIT(SPLIT(packages.purl, ":")[1], "/")[0],
+						"distro": os,
+						"properties": packages.properties,
+						"cpe": packages.cpe,
+						"ref": packages.ref,
+						"componentpurl": packages.purl,
+						"graphed": graphed
 						}`
 
-		// run the query with patameters
-		if purlCursor, err = dbconn.Database.Query(ctx, aql, &arangodb.QueryOptions{BindVars: parameters}); err != nil {
-			logger.Sugar().Errorf("Failed to run purlCursor query: %v", err)
-			return nil, errors.Wrap(err, "failed to run purlCursor query")
+	// run the query with patameters
+	purlCursor, err := dbconn.Database.Query(ctx, aql, &arangodb.QueryOptions{BindVars: parameters})
+	if err != nil {
+		logger.Sugar().Errorf("Failed to run purlCursor query: %v", err)
+		return nil, errors.Wrap(err, "failed to run purlCursor query")
+	}
+
+	defer purlCursor.Close() // close the cursor when returning from this function
+
+	components := []*cveComponent{}
+
+	for purlCursor.HasMore() { // list of purls
+
+		// the operating system of the sbom is read alongside the package for distro release matching,
+		// and the component properties for the source package that the package was built from
+		doc := struct {
+			*model.PackageCVE
+			Requested  string                     `json:"requested"`
+			Distro     string                     `json:"distro"`
+			Properties []models.ComponentProperty `json:"properties"`
+			CPE        string                     `json:"cpe"`
+			Ref        string                     `json:"ref"`
+			Component  string                     `json:"componentpurl"`
+			Graphed    bool                       `json:"graphed"`
+		}{PackageCVE: model.NewPackageCVE()}
+
+		if _, err = purlCursor.ReadDocument(ctx, &doc); err != nil {
+			logger.Sugar().Errorf("Failed to read purlCursor document: %v", err)
+			return nil, errors.Wrap(err, "failed to read purlCursor document")
 		}
 
-		defer purlCursor.Close() // close the cursor when returning from this function
+		pkg := doc.PackageCVE
 
-		for purlCursor.HasMore() { // list of purls
-			cvelist := make(map[string]bool)
+		purl := pkg.Purl
+		pkg.CompID = doc.Requested
 
-			pkg := model.NewPackageCVE()
+		pkgInfo, _ := models.PURLToPackage(purl)
 
-			if _, err = purlCursor.ReadDocument(ctx, &pkg); err != nil {
-				logger.Sugar().Errorf("Failed to read purlCursor document: %v", err)
-				return nil, errors.Wrap(err, "failed to read purlCursor document")
-			}
+		osvPkg := models.PackageDetails{
+			Name:      pkgInfo.Name,
+			Version:   pkgInfo.Version,
+			Commit:    pkgInfo.Commit,
+			Ecosystem: models.Ecosystem(pkgInfo.Ecosystem),
+			CompareAs: models.Ecosystem(pkgInfo.Ecosystem),
+			Distro:    pkgInfo.Distro,
+		}
 
-			purl := pkg.Purl
-			pkg.CompID = compid
+		// distro packages without a distro qualifier are from the operating system of the sbom, if it has one,
+		// while the packages of language ecosystems (i.e. npm or PyPI) are not tied to a release of the distro
+		if osvPkg.Distro == "" && models.IsDistroEcosystem(osvPkg.Ecosystem) {
+			osvPkg.Distro = doc.Distro
+		}
+
+		comp := &cveComponent{
+			pkg:         pkg,
+			cpe:         doc.CPE,
+			purl:        purl,
+			ref:         dependencyRef(doc.Ref, doc.Component, pkg.Name, pkg.Version),
+			graphed:     doc.Graphed,
+			matchMethod: MatchMethodName,
+		}
 
-			pkgInfo, _ := models.PURLToPackage(purl)
+		comp.addPackage(osvPkg, purl)
 
-			osvPkg := models.PackageDetails{
-				Name:      pkgInfo.Name,
-				Version:   pkgInfo.Version,
-				Commit:    pkgInfo.Commit,
-				Ecosystem: models.Ecosystem(pkgInfo.Ecosystem),
-				CompareAs: models.Ecosystem(pkgInfo.Ecosystem),
+		// distro advisories are mostly keyed by the source package, though some are keyed by the binary package,
+		// so binary packages are matched as both themselves and the source package they were built from
+		if source := resolveSource(pkgInfo, doc.Properties); source.Name != "" && source.Name != pkgInfo.Name {
+			sourcePkg := osvPkg
+			sourcePkg.Name = source.Name
+
+			if source.Version != "" {
+				sourcePkg.Version = source.Version
 			}
 
-			parameters = map[string]interface{}{ // parameters
-				"name": pkgInfo.Name,
+			sourcePurl, _ := models.SourcePURL(purl, source.Name)
+			comp.addPackage(sourcePkg, sourcePurl)
+		}
+
+		// the advisories of the packages are keyed by their purls, rather than their names, when the purls are valid
+		if len(comp.purls) > 0 {
+			comp.names = nil
+			comp.matchMethod = MatchMethodPurl
+		}
+
+		components = append(components, comp)
+	}
+
+	return components, nil
+}
+
+// queryVulnerabilities fetches the advisories of the components with a single query, returning them with their
+// events parsed, keyed by the base purls they are linked to in the vulnGraph and the affected package names they match
+func queryVulnerabilities(ctx context.Context, components []*cveComponent) (map[string][]*models.IndexedVulnerability, error) {
+	purls := []string{}
+	names := []string{}
+
+	for _, comp := range components {
+		purls = append(purls, comp.purls...)
+		names = append(names, comp.names...)
+	}
+
+	found := make(map[string][]*models.IndexedVulnerability)
+
+	if len(purls) == 0 && len(names) == 0 {
+		return found, nil
+	}
+
+	parameters := map[string]interface{}{ // parameters
+		"purls": models.UniquePURLs(purls),
+		"names": names,
+	}
+
+	aql := `LET linked = (
+				FOR p IN purls
+					FILTER p.purl IN @purls
+					FOR vuln IN 1..1 OUTBOUND p._id GRAPH 'vulnGraph'
+						RETURN {key: vuln._key, vuln: vuln, purl: p.purl}
+			)
+			LET named = (
+				FOR vuln IN vulns
+					FILTER LENGTH(@names) > 0 AND LENGTH(INTERSECTION(@names, vuln.affected[*].package.name)) > 0
+					RETURN {key: vuln._key, vuln: vuln, purl: null}
+			)
+			FOR l IN APPEND(linked, named)
+				COLLECT key = l.key INTO g = l
+				RETURN {
+					"vuln": merge({ID: key}, FIRST(g).vuln),
+					"purls": REMOVE_VALUE(UNIQUE(g[*].purl), null)
+				}`
+
+	cursor, err := dbconn.Database.Query(ctx, aql, &arangodb.QueryOptions{BindVars: parameters})
+	if err != nil {
+		logger.Sugar().Errorf("Failed to run cursor query: %v", err)
+		return nil, errors.Wrap(err, "failed to run cursor query")
+	}
+
+	defer cursor.Close() // close the cursor when returning from this function
+
+	requestedNames := make(map[string]bool)
+
+	for _, name := range names {
+		requestedNames[name] = true
+	}
+
+	for cursor.HasMore() { // vuln found
+
+		var doc struct {
+			Vuln  models.Vulnerability `json:"vuln"`
+			Purls []string             `json:"purls"`
+		}
+
+		if _, err = cursor.ReadDocument(ctx, &doc); err != nil {
+			logger.Sugar().Errorf("Failed to read cursor document: %v", err)
+			return nil, errors.Wrap(err, "failed to read cursor document")
+		}
+
+		iv := models.NewIndexedVulnerability(doc.Vuln)
+		keys := make(map[string]bool)
+
+		for _, purl := range doc.Purls {
+			keys[purl] = true
+		}
+
+		for _, affected := range doc.Vuln.Affected {
+			if requestedNames[affected.Package.Name] {
+				keys["name:"+affected.Package.Name] = true
 			}
+		}
+
+		for key := range keys {
+			found[key] = append(found[key], iv)
+		}
+	}
 
-			aql = `FOR vuln IN vulns
-						FILTER @name in (vuln.affected[*].package.name)
-						RETURN DISTINCT merge({ID: vuln._key}, vuln)`
+	return found, nil
+}
 
-			if len(strings.TrimSpace(purl)) > 0 {
-				// Split the purl string by "@" and "?"
-				parts := strings.Split(purl, "@")
-				parts = strings.Split(parts[0], "?")
+// addPackage adds a package that the component is matched as, along with the base purls and names that its advisories
+// are looked up by, where names are matched in the canonical form of the ecosystem (i.e. PEP 503 for PyPI),
+// while the advisories are looked up by both the name as given and its canonical form
+func (comp *cveComponent) addPackage(osvPkg models.PackageDetails, purl string) {
+	names := []string{osvPkg.Name}
+	osvPkg = models.NormalizePackage(osvPkg)
 
-				// The first part before "@" and "?" is in parts[0]
-				purl := parts[0]
+	if osvPkg.Name != names[0] {
+		names = append(names, osvPkg.Name)
+	}
 
-				parameters = map[string]interface{}{ // parameters
-					"purl": purl,
-				}
+	comp.osvPkgs = append(comp.osvPkgs, osvPkg)
+	comp.names = append(comp.names, names...)
+
+	// the advisories of the package are keyed by its purl without the version and qualifiers
+	if base, err := models.PURLBase(purl); err == nil {
+		purls := []string{base}
+
+		if normalized, err := models.PackageToPURL(models.PackageInfo{Name: osvPkg.Name, Ecosystem: string(osvPkg.Ecosystem)}); err == nil {
+			purls = append(purls, normalized)
+		}
 
-				aql = `LET purlDoc = (
-							FOR p IN purls
-							FILTER p.purl == @purl
-							RETURN p
-						)
-						FILTER LENGTH(purlDoc) > 0
+		comp.purls = models.UniquePURLs(append(comp.purls, purls...))
+	}
+}
 
-						FOR vuln, edge, path IN 1..1 OUTBOUND purlDoc[0]._id GRAPH 'vulnGraph'
-							RETURN DISTINCT merge({ID: vuln._key}, vuln)`
+// candidates returns the advisories that may affect the component from those that were fetched for all of the components
+func (comp *cveComponent) candidates(found map[string][]*models.IndexedVulnerability) []*models.IndexedVulnerability {
+	candidates := []*models.IndexedVulnerability{}
+
+	for _, purl := range comp.purls {
+		candidates = append(candidates, found[purl]...)
+	}
 
+	for _, name := range comp.names {
+		candidates = append(candidates, found["name:"+name]...)
+	}
+
+	return candidates
+}
+
+// evaluate checks the component for each of its candidate advisories, returning the cve rows of those that affect
+// it and the advisories that could not be evaluated, limited to the vulnerabilities included by the filter.
+//
+// The package is checked first, then the source package it was built from, where an advisory that was already reported,
+// directly or through one of its aliases, is not checked again.
+func (comp *cveComponent) evaluate(candidates []*models.IndexedVulnerability, filter models.VulnerabilityFilter) cveResult {
+	pkg := comp.pkg
+
+	result := cveResult{packages: []*PackageCVE{}, indeterminate: []*IndeterminateCVE{}}
+
+	reported := make(map[string]bool)
+	pkgIndeterminate := []*IndeterminateCVE{} // vulnerabilities that could not be evaluated for the package
+	undetermined := make(map[string]bool)
+
+	for _, osvPkg := range comp.osvPkgs {
+		cvelist := make(map[string]bool)
+		affecting := []models.Vulnerability{} // vulnerabilities affecting the package
+
+		for _, iv := range candidates { // vuln found
+			vuln := iv.Vulnerability
+
+			if cvelist[vuln.ID] || reported[vuln.ID] || !filter.Includes(vuln) {
+				continue
 			}
 
-			// run the query with patameters
-			if cursor, err = dbconn.Database.Query(ctx, aql, &arangodb.QueryOptions{BindVars: parameters}); err != nil {
-				logger.Sugar().Errorf("Failed to run cursor query: %v", err)
-				return nil, errors.Wrap(err, "failed to run cursor query")
+			if slices.ContainsFunc(vuln.Aliases, func(alias string) bool { return reported[alias] }) {
+				continue
 			}
 
-			score := 0.0
-			severity := ""
-			defer cursor.Close() // close the cursor when returning from this function
+			cvelist[vuln.ID] = true
 
-			for cursor.HasMore() { // vuln found
+			isAffected, err := iv.IsAffected(osvPkg)
+			if err != nil {
+				if !undetermined[vuln.ID] {
+					undetermined[vuln.ID] = true
+
+					var parseErr *models.ParseError
+					errors.As(err, &parseErr)
+
+					pkgIndeterminate = append(pkgIndeterminate, &IndeterminateCVE{
+						Key:        pkg.Key,
+						CompID:     pkg.CompID,
+						Name:       pkg.Name,
+						Version:    pkg.Version,
+						Purl:       pkg.Purl,
+						CVE:        vuln.ID,
+						Reason:     err.Error(),
+						ParseError: parseErr,
+					})
+				}
 
-				var vuln models.Vulnerability
+				continue
+			}
 
-				if _, err = cursor.ReadDocument(ctx, &vuln); err != nil {
-					logger.Sugar().Errorf("Failed to read cursor document: %v", err)
-					return nil, errors.Wrap(err, "failed to read cursor document")
-				}
+			if isAffected && vuln.ID != "" {
+				affecting = append(affecting, vuln)
+			}
+		}
+
+		// records for the same flaw (i.e. a GHSA and the CVE it aliases) are reported once under their primary ID
+		groups := models.GroupVulnerabilities(affecting, idPreference)
+
+		remediation, err := models.RemediateGroups(osvPkg, groups)
+		if err != nil {
+			logger.Sugar().Warnf("Failed to find remediation for %s as %s: %v", comp.purl, osvPkg.Name, err)
+		}
+
+		for _, group := range groups {
+			row := newPackageCVE(pkg, group, osvPkg)
+
+			row.FixedIn = remediation.FixedIn[group.ID]
+			row.RecommendedVersion = remediation.RecommendedVersion
+			row.NoFix = row.FixedIn == ""
+			row.MatchMethod = comp.matchMethod
+
+			for _, id := range group.IDs() {
+				reported[id] = true
+			}
+
+			result.packages = append(result.packages, row)
+		}
+	}
+
+	// the cpe of the package is matched against the NVD CVEs as well, which finds the vulnerabilities of packages
+	// that are unknown to osv, such as vendor binaries and c/c++ libraries, and those that osv has no record of
+	if comp.cpe != "" {
+		for _, row := range cpeFindings(pkg, comp.cpe, filter, reported) {
+			reported[row.CVE] = true
+			result.packages = append(result.packages, row)
+		}
+	}
+
+	// a record that could not be evaluated does not need reporting if an alias of it was found to affect the package
+	for _, ind := range pkgIndeterminate {
+		if !reported[ind.CVE] {
+			result.indeterminate = append(result.indeterminate, ind)
+		}
+	}
+
+	return result
+}
+
+// GetCVEs will return a list of packages that have CVEs, along with the
+// vulnerabilities that could not be determined to affect a package or not, limited to the
+// vulnerabilities included by the filter (by default, those that have not been withdrawn).
+//
+// The components of all of the keys are read with a single query, and their advisories are found in the
+// in-memory index once it has been loaded, or otherwise fetched from the vulnGraph with a single query.
+// The components are evaluated by a bounded pool of workers, which stop when the context is cancelled.
+//
+// Packages are also matched against the imported NVD CVEs by their cpe, if they have one, where a CVE that was
+// already found by osv is reported once with the match method of the osv finding.
+func GetCVEs(ctx context.Context, keys []string, filter models.VulnerabilityFilter) ([]*PackageCVE, []*IndeterminateCVE, error) {
+	packages := []*PackageCVE{}            // list of packages in the SBOM
+	indeterminate := []*IndeterminateCVE{} // list of vulnerabilities that could not be evaluated
+
+	components, err := getSBOMComponents(ctx, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the in-memory index answers the lookup by the same purls and names as the vulnGraph,
+	// without a round trip to the database, once it has been loaded
+	indexed := vulnIndex.Ready()
+	found := make(map[string][]*models.IndexedVulnerability)
+
+	if !indexed {
+		if found, err = queryVulnerabilities(ctx, components); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	results := make([]cveResult, len(components))
+	work := make(chan int)
+
+	var wg sync.WaitGroup
 
-				if !cvelist[vuln.ID] && models.IsAffected(vuln, osvPkg) {
-					cvepkg := model.NewPackageCVE()
-
-					cvelist[vuln.ID] = true
-
-					cvepkg.Key = pkg.Key
-					cvepkg.CompID = pkg.CompID
-					cvepkg.Language = pkg.Language
-					cvepkg.Name = pkg.Name
-					cvepkg.URL = pkg.URL
-					cvepkg.Purl = pkg.Purl
-					cvepkg.Version = pkg.Version
-					cvepkg.CVE = vuln.ID
-					cvepkg.Summary = vuln.Summary
-
-					if len(vuln.Severity) > 0 {
-						if vuln.Severity[0].Type == "CVSS_V3" {
-							if bm, err := metric_v3.NewBase().Decode(vuln.Severity[0].Score); err == nil {
-								if bm.Score() > score {
-									score = bm.Score()
-									severity = bm.Severity().String()
-								}
-							}
-						} else {
-							if bm, err := metric.NewBase().Decode(vuln.Severity[0].Score); err == nil {
-								if bm.Score() > score {
-									score = bm.Score()
-									severity = bm.Severity().String()
-								}
-							}
-						}
-					}
-					cvepkg.Score = score
-					cvepkg.Severity = severity
-
-					if severity == "" {
-						cvepkg.Severity = "None"
-					}
-
-					if cvepkg.CVE != "" {
-						packages = append(packages, cvepkg)
-					}
+	for range min(evalWorkers(), len(components)) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range work {
+				comp := components[i]
+
+				if indexed {
+					results[i] = comp.evaluate(vulnIndex.Lookup(comp.purls, comp.names), filter)
+				} else {
+					results[i] = comp.evaluate(comp.candidates(found), filter)
 				}
 			}
+		}()
+	}
+
+dispatch:
+	for i := range components {
+		select {
+		case work <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+
+	close(work)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, errors.Wrap(err, "evaluation of the components was cancelled")
+	}
+
+	// the findings are attributed to how the package is introduced into the root component of its sbom,
+	// which is only queried for the sboms that have relationships stored in the dependencyGraph
+	graphed := []string{}
+
+	for _, comp := range components {
+		if comp.graphed && !slices.Contains(graphed, comp.pkg.CompID) {
+			graphed = append(graphed, comp.pkg.CompID)
 		}
 	}
 
+	paths := make(map[string]map[string]*DependencyPackage)
+
+	if len(graphed) > 0 {
+		if paths, err = dependencyPaths(ctx, graphed); err != nil {
+			logger.Sugar().Warnf("Failed to get the dependency paths: %v", err)
+		}
+	}
+
+	for i, result := range results {
+		if dep, ok := paths[components[i].pkg.Key][components[i].ref]; ok {
+			for _, p := range result.packages {
+				p.Dependency = dep.Dependency
+				p.IntroductionPath = dep.IntroductionPath
+			}
+		}
+
+		packages = append(packages, result.packages...)
+		indeterminate = append(indeterminate, result.indeterminate...)
+	}
+
 	sort.Slice(packages, func(i, j int) bool {
 		a, b := packages[i], packages[j]
 		return a.Score > b.Score || (a.Score == b.Score && (a.Name < b.Name || (a.Name == b.Name && a.Version < b.Version)))
 	})
 
-	return packages, nil
+	return packages, indeterminate, nil
+}
+
+// canonicalizePURLs replaces the purl of each component, including those nested within
+// other components and the metadata component, with its canonical form
+func canonicalizePURLs(node interface{}) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for k, v := range n {
+			if purl, ok := v.(string); ok && k == "purl" {
+				if canonical, err := models.CanonicalPURL(purl); err == nil {
+					n[k] = canonical
+				}
+
+				continue
+			}
+
+			canonicalizePURLs(v)
+		}
+	case []interface{}:
+		for _, v := range n {
+			canonicalizePURLs(v)
+		}
+	}
+}
+
+// canonicalizeSBOMPURLs returns the SBOM content with every purl in its canonical form
+func canonicalizeSBOMPURLs(content json.RawMessage) (json.RawMessage, error) {
+	if len(content) == 0 {
+		return content, nil
+	}
+
+	var doc interface{}
+
+	// numbers are kept as they were written rather than converted to floats
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	canonicalizePURLs(doc)
+
+	// the "&" between qualifiers is kept as it is rather than escaped
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+// sbomMediaType returns the media type of the posted sbom, without its parameters
+func sbomMediaType(c *fiber.Ctx) string {
+	mediaType, _, err := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(mediaType)
+}
+
+// sendSBOMError responds with a 400 containing the error and, if the sbom failed to parse, where it failed
+func sendSBOMError(c *fiber.Ctx, err error) error {
+	res := SBOMError{Error: err.Error()}
+
+	var perr *models.SBOMParseError
+	if errors.As(err, &perr) {
+		res.ParseError = perr
+	}
+
+	return c.Status(fiber.StatusBadRequest).JSON(res)
 }
 
 // NewSBOM godoc
 // @Summary Upload an SBOM
-// @Description Create a new SBOM and persist it
+// @Description Create a new SBOM and persist it. The SBOM may be CycloneDX JSON or SPDX 2.3 JSON,
+// @Description whose packages, licenses and relationships are normalized into the same form as CycloneDX components.
+// @Description An SPDX tag-value document (text/spdx) or SPDX 3.0 JSON-LD document (application/ld+json) is posted as the body,
+// @Description with the key as a query parameter, and a parse error points to the line or element that failed to parse.
+// @Description A CycloneDX XML (application/vnd.cyclonedx+xml) or protobuf (application/x.vnd.cyclonedx+protobuf) BOM is posted
+// @Description the same way and stored as the CycloneDX JSON of the same spec version.
+// @Description JSON that is neither CycloneDX nor SPDX is stored as it is, while a CycloneDX or SPDX sbom that fails to parse is rejected.
 // @Tags sbom
 // @Accept application/json
+// @Accept text/spdx
+// @Accept application/ld+json
+// @Accept application/vnd.cyclonedx+xml
+// @Accept application/x.vnd.cyclonedx+protobuf
+// @Param key query string false "key of the sbom, when it is not posted as JSON shaped like model.SBOM"
 // @Produce json
 // @Success 200
+// @Failure 400
 // @Router /msapi/sbom [post]
 func NewSBOM(c *fiber.Ctx) error {
 
@@ -631,12 +1310,71 @@ func NewSBOM(c *fiber.Ctx) error {
 	var ctx = context.Background() // use default database context
 	sbom := model.NewSBOM()        // define a package to be returned
 
-	if err = c.BodyParser(sbom); err != nil { // parse the JSON into the package object
-		return c.Status(503).Send([]byte(err.Error()))
+	// sboms that are not JSON shaped like model.SBOM are posted as they are, with the key as a query parameter,
+	// where the content type says what format the sbom is in, so it must be normalized without an error
+	explicit := true
+
+	switch sbomMediaType(c) {
+	case "text/spdx", "text/x-spdx", "text/spdx+tag-value":
+		if sbom.Content, err = models.SPDXTagValueToJSON(bytes.NewReader(c.Body())); err != nil {
+			return sendSBOMError(c, err)
+		}
+
+		sbom.Key = c.Query("key")
+	case "application/ld+json", "application/spdx+ld+json":
+		if err = models.CheckSPDX3Context(c.Body()); err != nil {
+			return sendSBOMError(c, err)
+		}
+
+		sbom.Content = append(json.RawMessage{}, c.Body()...)
+		sbom.Key = c.Query("key")
+	case "application/vnd.cyclonedx+xml":
+		if sbom.Content, err = models.CycloneDXXMLToJSON(bytes.NewReader(c.Body())); err != nil {
+			return sendSBOMError(c, err)
+		}
+
+		sbom.Key = c.Query("key")
+	case "application/x.vnd.cyclonedx+protobuf":
+		if sbom.Content, err = models.CycloneDXProtobufToJSON(c.Body()); err != nil {
+			return sendSBOMError(c, err)
+		}
+
+		sbom.Key = c.Query("key")
+	default:
+		if err = c.BodyParser(sbom); err != nil { // parse the JSON into the package object
+			return c.Status(503).Send([]byte(err.Error()))
+		}
+
+		explicit = false
 	}
 
 	key := sbom.Key // save the key from the postgresdb if passed in json data
 
+	doc := SBOMDocument{SBOM: sbom}
+
+	// the format is detected so that the components of CycloneDX and SPDX sboms are stored in the same form,
+	// while JSON in any other format is stored as it is, without normalized components, as it was before,
+	// unless the content type said that it was an sbom
+	if len(sbom.Content) > 0 {
+		if doc.Normalized, err = models.NormalizeSBOM(sbom.Content); err != nil {
+			if explicit || !errors.Is(err, models.ErrUnknownSBOMFormat) || !json.Valid(sbom.Content) {
+				return sendSBOMError(c, err)
+			}
+
+			logger.Sugar().Warnf("Storing the sbom %s without normalizing it: %v", sbom.Key, err)
+		}
+	}
+
+	// store the purls of an sbom in their canonical form so that equivalent purls are matched and deduplicated,
+	// while JSON that is not an sbom is stored as it was posted
+	if doc.Normalized != nil {
+		if content, err := canonicalizeSBOMPURLs(sbom.Content); err == nil {
+			sbom.Content = content
+		} else {
+			logger.Sugar().Warnf("Failed to canonicalize the sbom purls: %v", err)
+		}
+	}
+
 	// for backward compatibility skip creating a NFT if the compid is part of the POST
 	// this will enable mapping of the sbom to the compid in the postgresdb
 
@@ -664,11 +1402,18 @@ func NewSBOM(c *fiber.Ctx) error {
 	}
 
 	// update existing docs and add if missing
-	if _, err = dbconn.Collections["sbom"].CreateDocumentWithOptions(ctx, sbom, options); err != nil {
+	if _, err = dbconn.Collections["sbom"].CreateDocumentWithOptions(ctx, doc, options); err != nil {
 		logger.Sugar().Errorf("Failed to create document: %v", err)
 	}
 
 	logger.Sugar().Infof("Created document in collection '%s' in db '%s' key='%s'\n", dbconn.Collections["sbom"].Name(), dbconn.Database.Name(), sbom.Key)
+
+	// the relationships between the components are stored as edges for the dependency queries
+	if doc.Normalized != nil {
+		if err = storeDependencyGraph(ctx, sbom.Key, doc.Normalized); err != nil {
+			logger.Sugar().Errorf("Failed to store the dependency graph: %v", err)
+		}
+	}
 	/*
 		dhurl := c.BaseURL()
 
@@ -777,16 +1522,296 @@ func HealthCheck(c *fiber.Ctx) error {
 	return c.SendString("OK")
 }
 
+// ImportSourceMap godoc
+// @Summary Import a binary to source package mapping
+// @Description Import the binary to source package mapping from a dpkg status (/var/lib/dpkg/status) or apk installed (/lib/apk/db/installed) file,
+// @Description which is used to match Debian, Ubuntu and Alpine packages against their advisories when the SBOM does not record the source package.
+// @Tags sourcemap
+// @Accept text/plain
+// @Produce json
+// @Success 200
+// @Failure 400
+// @Router /msapi/sourcemap [post]
+func ImportSourceMap(c *fiber.Ctx) error {
+	imported, err := sourceMap.Load(bytes.NewReader(c.Body()))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(SourceMapResult{Imported: imported, Total: sourceMap.Len()})
+}
+
+// loadNVDFeeds imports the comma separated list of NVD CVE JSON 2.0 feeds, where a directory
+// imports each of the .json and .json.gz feeds within it
+func loadNVDFeeds(paths string) {
+	for _, path := range strings.Split(paths, ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+
+		files := []string{path}
+
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			plain, _ := filepath.Glob(filepath.Join(path, "*.json"))
+			compressed, _ := filepath.Glob(filepath.Join(path, "*.json.gz"))
+			files = append(plain, compressed...)
+		}
+
+		for _, file := range files {
+			f, err := os.Open(file)
+			if err != nil {
+				logger.Sugar().Errorf("Failed to open NVD feed %s: %v", file, err)
+				continue
+			}
+
+			imported, err := cpeIndex.Load(f)
+			f.Close()
+
+			if err != nil {
+				logger.Sugar().Errorf("Failed to load NVD feed %s: %v", file, err)
+				continue
+			}
+
+			logger.Sugar().Infof("Loaded %d CVEs from NVD feed %s", imported, file)
+		}
+	}
+}
+
+// loadSourceMaps imports the comma separated list of dpkg status and apk installed files
+func loadSourceMaps(files string) {
+	for _, file := range strings.Split(files, ",") {
+		if file = strings.TrimSpace(file); file == "" {
+			continue
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			logger.Sugar().Errorf("Failed to open source map %s: %v", file, err)
+			continue
+		}
+
+		imported, err := sourceMap.Load(f)
+		f.Close()
+
+		if err != nil {
+			logger.Sugar().Errorf("Failed to load source map %s: %v", file, err)
+			continue
+		}
+
+		logger.Sugar().Infof("Loaded %d binary packages from source map %s", imported, file)
+	}
+}
+
+// versionEcosystem returns the ecosystem used to compare versions, ignoring any release suffix such as "Debian:11"
+func versionEcosystem(ecosystem models.Ecosystem) models.Ecosystem {
+	name, _, _ := strings.Cut(string(ecosystem), ":")
+
+	return models.Ecosystem(name)
+}
+
+// sendVersionError responds with a 400 containing the errors and the details of any that are parse errors
+func sendVersionError(c *fiber.Ctx, errs ...error) error {
+	res := VersionError{}
+	messages := []string{}
+
+	for _, err := range errs {
+		var perr *models.ParseError
+
+		if errors.As(err, &perr) {
+			res.ParseErrors = append(res.ParseErrors, perr)
+		}
+
+		messages = append(messages, err.Error())
+	}
+
+	res.Error = strings.Join(messages, "; ")
+
+	return c.Status(fiber.StatusBadRequest).JSON(res)
+}
+
+// CompareVersions godoc
+// @Summary Compare two versions
+// @Description Compare versions a and b using the ordering of the ecosystem, returning -1 if a < b, 0 if a == b or 1 if a > b.
+// @Tags version
+// @Accept */*
+// @Produce json
+// @Param ecosystem query string true "OSV ecosystem, i.e. npm, PyPI or Debian"
+// @Param a query string true "first version"
+// @Param b query string true "second version"
+// @Success 200
+// @Failure 400
+// @Router /msapi/version/compare [get]
+func CompareVersions(c *fiber.Ctx) error {
+	res := VersionCompareResult{
+		Ecosystem: models.Ecosystem(c.Query("ecosystem")),
+		A:         c.Query("a"),
+		B:         c.Query("b"),
+	}
+
+	ecosystem := versionEcosystem(res.Ecosystem)
+
+	a, aerr := models.Parse(res.A, ecosystem)
+	b, berr := models.Parse(res.B, ecosystem)
+
+	if aerr != nil || berr != nil {
+		var errs []error
+
+		for _, err := range []error{aerr, berr} {
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		return sendVersionError(c, errs...)
+	}
+
+	res.Result = a.Compare(b)
+
+	return c.JSON(res)
+}
+
+// SortVersions godoc
+// @Summary Sort a list of versions
+// @Description Sort the versions using the ordering of the ecosystem, in ascending order unless descending is true.
+// @Tags version
+// @Accept application/json
+// @Produce json
+// @Success 200
+// @Failure 400
+// @Router /msapi/version/sort [post]
+func SortVersions(c *fiber.Ctx) error {
+	req := VersionSortRequest{}
+
+	if err := c.BodyParser(&req); err != nil {
+		return sendVersionError(c, err)
+	}
+
+	ecosystem := versionEcosystem(req.Ecosystem)
+	versions := make([]models.Version, 0, len(req.Versions))
+
+	var errs []error
+
+	// parse every version upfront so that each one is only parsed once
+	for _, str := range req.Versions {
+		v, err := models.Parse(str, ecosystem)
+		if err != nil {
+			// errors other than parse errors (i.e. an unsupported ecosystem) apply to every version
+			var perr *models.ParseError
+			if !errors.As(err, &perr) {
+				return sendVersionError(c, err)
+			}
+
+			errs = append(errs, err)
+
+			continue
+		}
+
+		versions = append(versions, v)
+	}
+
+	if len(errs) > 0 {
+		return sendVersionError(c, errs...)
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		if req.Descending {
+			return versions[i].Compare(versions[j]) > 0
+		}
+
+		return versions[i].Compare(versions[j]) < 0
+	})
+
+	req.Versions = make([]string, 0, len(versions))
+
+	for _, v := range versions {
+		req.Versions = append(req.Versions, v.String())
+	}
+
+	return c.JSON(req)
+}
+
+// VersionInRange godoc
+// @Summary Check if a version is in a range
+// @Description Check if a version is within an OSV range, using the same evaluation as the CVE matching, or within a constraint written in the native syntax of the ecosystem (i.e. "^1.2.3" or "[1.0,2.0)") or as "vers:".
+// @Tags version
+// @Accept application/json
+// @Produce json
+// @Success 200
+// @Failure 400
+// @Router /msapi/version/inrange [post]
+func VersionInRange(c *fiber.Ctx) error {
+	req := VersionInRangeRequest{}
+
+	if err := c.BodyParser(&req); err != nil {
+		return sendVersionError(c, err)
+	}
+
+	ecosystem := versionEcosystem(req.Ecosystem)
+
+	var inrange bool
+	var err error
+
+	switch {
+	case req.Range != nil:
+		if req.Version == "" && req.Commit == "" {
+			return sendVersionError(c, errors.New("a version or commit is required"))
+		}
+
+		// parse the version upfront so that it is reported rather than treated as not in range
+		if req.Version != "" {
+			if _, err = models.Parse(req.Version, ecosystem); err != nil {
+				return sendVersionError(c, err)
+			}
+		}
+
+		inrange, err = models.RangeContains(*req.Range, models.PackageDetails{
+			Version:   req.Version,
+			Commit:    req.Commit,
+			Ecosystem: ecosystem,
+			CompareAs: ecosystem,
+		})
+	case req.Constraint != "":
+		var vr models.VersionRange
+
+		if vr, err = models.ParseVersionRange(req.Constraint, ecosystem); err != nil {
+			return sendVersionError(c, err)
+		}
+
+		inrange, err = vr.ContainsStr(req.Version)
+	default:
+		return sendVersionError(c, errors.New("a range or constraint is required"))
+	}
+
+	if err != nil {
+		return sendVersionError(c, err)
+	}
+
+	data := map[string]interface{}{
+		"ecosystem": req.Ecosystem,
+		"version":   req.Version,
+		"commit":    req.Commit,
+		"inrange":   inrange,
+	}
+	return c.JSON(data)
+}
+
 // setupRoutes defines maps the routes to the functions
 func setupRoutes(app *fiber.App) {
 
-	app.Get("/swagger/*", swagger.HandlerDefault) // handle displaying the swagger
-	app.Get("/msapi/packages", GetPackages)       // list of packages
-	app.Get("/msapi/package", GetPackages4SBOM)   // get all the packages in an sbom based on a key
-	app.Get("/msapi/sbomtype", SBOMType)          // tell client that this microservice supports a full SBOM on the SBOM Post
-	app.Post("/msapi/package", NewSBOM)           // save a sbom, if compid is defined then add to comp2sbom graph
-	app.Post("/msapi/provenance", NewProvenance)  // save a single package
-	app.Get("/health", HealthCheck)               // kubernetes health check
+	app.Get("/swagger/*", swagger.HandlerDefault)          // handle displaying the swagger
+	app.Get("/msapi/packages", GetPackages)                // list of packages
+	app.Get("/msapi/package", GetPackages4SBOM)            // get all the packages in an sbom based on a key
+	app.Get("/msapi/sbomtype", SBOMType)                   // tell client that this microservice supports a full SBOM on the SBOM Post
+	app.Post("/msapi/package", NewSBOM)                    // save a sbom, if compid is defined then add to comp2sbom graph
+	app.Post("/msapi/provenance", NewProvenance)           // save a single package
+	app.Get("/msapi/version/compare", CompareVersions)     // compare two versions for an ecosystem
+	app.Post("/msapi/version/sort", SortVersions)          // sort a list of versions for an ecosystem
+	app.Post("/msapi/version/inrange", VersionInRange)     // check if a version is within an OSV range or native constraint
+	app.Post("/msapi/sourcemap", ImportSourceMap)          // import a dpkg status or apk installed file for binary to source package matching
+	app.Get("/msapi/vulnindex", GetVulnIndexMetrics)       // size and staleness of the in-memory vulnerability index
+	app.Get("/msapi/dependencies", GetDependencies)        // direct and transitive dependencies of the sboms with their introduction paths
+	app.Get("/msapi/dependencies/tree", GetDependencyTree) // dependency tree of a component of an sbom
+	app.Get("/health", HealthCheck)                        // kubernetes health check
 }
 
 // @title Ortelius v11 Package Microservice
@@ -810,6 +1835,15 @@ func setupRoutes(app *fiber.App) {
 // @host localhost:3000
 // @BasePath /msapi/package
 func main() {
+	// the import-osv command fills the vulns collection and vulnGraph instead of running the microservice
+	if len(os.Args) > 1 && os.Args[1] == "import-osv" {
+		if err := ImportOSV(os.Args[2:]); err != nil {
+			logger.Sugar().Fatalf("Failed to import OSV: %v", err)
+		}
+
+		return
+	}
+
 	port := ":" + database.GetEnvDefault("MS_PORT", "8081") // database port
 	app := fiber.New()
 	app.Use(compress.New())
@@ -820,6 +1854,34 @@ func main() {
 	}))
 
 	fetchAndParseLicenses(licensesMap)
+
+	// evaluate GIT ranges against the local bare repos, if there are any
+	if gitDir := database.GetEnvDefault("GIT_REPOS_DIR", ""); gitDir != "" {
+		models.SetCommitAncestry(models.NewBareRepoAncestry(gitDir))
+	}
+
+	// binary to source package mappings for sboms without the source package, if there are any
+	loadSourceMaps(database.GetEnvDefault("SOURCE_MAP_FILES", ""))
+
+	// nvd cves for matching packages by cpe, if there are any
+	loadNVDFeeds(database.GetEnvDefault("NVD_FEEDS", ""))
+
+	// the relationships of the sboms are stored in the dependencyGraph when they are uploaded
+	if err := ensureDependencyGraph(context.Background()); err != nil {
+		logger.Sugar().Fatalf("Failed to create the dependencyGraph: %v", err)
+	}
+
+	// vulnerabilities held in memory so that packages are evaluated without a query each, unless disabled
+	if enabled, _ := strconv.ParseBool(database.GetEnvDefault("VULN_INDEX", "true")); enabled {
+		interval, err := time.ParseDuration(database.GetEnvDefault("VULN_INDEX_REFRESH", "5m"))
+		if err != nil || interval <= 0 {
+			logger.Sugar().Warnf("Invalid VULN_INDEX_REFRESH, refreshing every 5m: %v", err)
+			interval = 5 * time.Minute
+		}
+
+		go refreshVulnIndexEvery(interval)
+	}
+
 	setupRoutes(app) // define the routes for this microservice
 
 	if err := app.Listen(port); err != nil { // start listening for incoming connections
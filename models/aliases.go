@@ -0,0 +1,179 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultIDPreference prefers CVE IDs, then GHSA IDs, over ecosystem specific IDs such as PYSEC or RUSTSEC
+var DefaultIDPreference = IDPreference{"CVE", "GHSA"}
+
+// IDPreference defines the order of the ID prefixes used to choose the primary ID of a
+// group of aliases, where IDs without a listed prefix come after those with one
+type IDPreference []string
+
+// ParseIDPreference parses a comma separated list of ID prefixes, i.e. "CVE,GHSA"
+func ParseIDPreference(str string) IDPreference {
+	var pref IDPreference
+
+	for _, prefix := range strings.Split(str, ",") {
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			pref = append(pref, strings.ToUpper(prefix))
+		}
+	}
+
+	if len(pref) == 0 {
+		return DefaultIDPreference
+	}
+
+	return pref
+}
+
+// rank returns the position of the prefix of the ID in the preference
+func (pref IDPreference) rank(id string) int {
+	for i, prefix := range pref {
+		if strings.HasPrefix(strings.ToUpper(id), prefix+"-") {
+			return i
+		}
+	}
+
+	return len(pref)
+}
+
+// Primary returns the most preferred of the IDs, using the lowest ID
+// when more than one has the same prefix so the choice is stable
+func (pref IDPreference) Primary(ids []string) string {
+	primary := ""
+
+	for _, id := range ids {
+		if primary == "" || pref.rank(id) < pref.rank(primary) ||
+			(pref.rank(id) == pref.rank(primary) && id < primary) {
+			primary = id
+		}
+	}
+
+	return primary
+}
+
+// VulnerabilityGroup defines the vulnerability records that describe the same flaw,
+// identified by their primary ID along with every other ID they are known by, and the
+// IDs of the flaws that the records are related to but which are not the same flaw
+type VulnerabilityGroup struct {
+	ID              string          `json:"id"`
+	Aliases         []string        `json:"aliases,omitempty"`
+	Related         []string        `json:"related,omitempty"`
+	Vulnerabilities []Vulnerability `json:"-"`
+}
+
+// Primary returns the record with the primary ID of the group, or the first record if
+// the primary ID is only known as an alias (i.e. a GHSA record for a CVE not in the database)
+func (g VulnerabilityGroup) Primary() Vulnerability {
+	for _, v := range g.Vulnerabilities {
+		if v.ID == g.ID {
+			return v
+		}
+	}
+
+	return g.Vulnerabilities[0]
+}
+
+// IDs returns the primary ID of the group along with its aliases
+func (g VulnerabilityGroup) IDs() []string {
+	return append([]string{g.ID}, g.Aliases...)
+}
+
+// GroupVulnerabilities merges the vulnerabilities that are linked to each other through their
+// Aliases, including transitively, such as a GHSA and a PYSEC record that both alias the same CVE,
+// and chooses the primary ID of each group by the preference.
+//
+// The Related IDs of the records are reported as the related IDs of their group, rather than merged
+// into it, as OSV uses them for flaws that are closely related but not the same, i.e. a fix that was
+// incomplete and the CVE for the remaining flaw.
+//
+// The groups are returned in the order of their first record in the given vulnerabilities.
+func GroupVulnerabilities(vulns []Vulnerability, pref IDPreference) []VulnerabilityGroup {
+	parent := make(map[string]string)
+
+	var find func(id string) string
+	find = func(id string) string {
+		if _, ok := parent[id]; !ok {
+			parent[id] = id
+		}
+
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+
+		return parent[id]
+	}
+
+	union := func(a, b string) {
+		if ra, rb := find(a), find(b); ra != rb {
+			parent[rb] = ra
+		}
+	}
+
+	for _, v := range vulns {
+		find(v.ID)
+
+		for _, alias := range v.Aliases {
+			union(v.ID, alias)
+		}
+	}
+
+	var groups []VulnerabilityGroup
+
+	index := make(map[string]int)
+
+	for _, v := range vulns {
+		root := find(v.ID)
+
+		i, ok := index[root]
+		if !ok {
+			i = len(groups)
+			index[root] = i
+
+			groups = append(groups, VulnerabilityGroup{})
+		}
+
+		groups[i].Vulnerabilities = append(groups[i].Vulnerabilities, v)
+	}
+
+	// every ID that has been seen is a member of the group of its root, not just those with records
+	members := make(map[string][]string)
+
+	for id := range parent {
+		root := find(id)
+		members[root] = append(members[root], id)
+	}
+
+	for root, i := range index {
+		ids := members[root]
+		sort.Strings(ids)
+
+		groups[i].ID = pref.Primary(ids)
+
+		for _, id := range ids {
+			if id != groups[i].ID {
+				groups[i].Aliases = append(groups[i].Aliases, id)
+			}
+		}
+
+		related := make(map[string]bool)
+
+		for _, v := range groups[i].Vulnerabilities {
+			for _, id := range v.Related {
+				if find(id) != root && !related[id] {
+					related[id] = true
+					groups[i].Related = append(groups[i].Related, id)
+				}
+			}
+		}
+
+		sort.Strings(groups[i].Related)
+	}
+
+	return groups
+}
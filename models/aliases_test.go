@@ -0,0 +1,161 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+// aliasedVulnerability returns a vulnerability with the aliases and related IDs
+func aliasedVulnerability(id string, aliases []string, related []string) Vulnerability {
+	return Vulnerability{ID: id, Aliases: aliases, Related: related}
+}
+
+// groupIDs returns the primary ID, aliases, related IDs and record IDs of each of the groups
+func groupIDs(groups []VulnerabilityGroup) [][4][]string {
+	ids := [][4][]string{}
+
+	for _, g := range groups {
+		records := []string{}
+
+		for _, v := range g.Vulnerabilities {
+			records = append(records, v.ID)
+		}
+
+		ids = append(ids, [4][]string{{g.ID}, g.Aliases, g.Related, records})
+	}
+
+	return ids
+}
+
+func TestGroupVulnerabilities(t *testing.T) {
+	tests := []struct {
+		name  string
+		vulns []Vulnerability
+		pref  IDPreference
+		want  [][4][]string // primary ID, aliases, related IDs and record IDs of each group
+	}{
+		{
+			name: "records that alias each other",
+			vulns: []Vulnerability{
+				aliasedVulnerability("GHSA-aaaa", []string{"CVE-2024-0001"}, nil),
+				aliasedVulnerability("CVE-2024-0001", []string{"GHSA-aaaa"}, nil),
+			},
+			pref: DefaultIDPreference,
+			want: [][4][]string{{{"CVE-2024-0001"}, {"GHSA-aaaa"}, nil, {"GHSA-aaaa", "CVE-2024-0001"}}},
+		},
+		{
+			name: "transitive aliases",
+			vulns: []Vulnerability{
+				aliasedVulnerability("PYSEC-2024-1", []string{"GHSA-aaaa"}, nil),
+				aliasedVulnerability("GHSA-aaaa", []string{"CVE-2024-0001"}, nil),
+				aliasedVulnerability("OSV-2024-2", []string{"CVE-2024-0001"}, nil),
+			},
+			pref: DefaultIDPreference,
+			want: [][4][]string{{
+				{"CVE-2024-0001"},
+				{"GHSA-aaaa", "OSV-2024-2", "PYSEC-2024-1"},
+				nil,
+				{"PYSEC-2024-1", "GHSA-aaaa", "OSV-2024-2"},
+			}},
+		},
+		{
+			name: "separate flaws in the order of their first record",
+			vulns: []Vulnerability{
+				aliasedVulnerability("GHSA-bbbb", nil, nil),
+				aliasedVulnerability("GHSA-aaaa", []string{"CVE-2024-0001"}, nil),
+			},
+			pref: DefaultIDPreference,
+			want: [][4][]string{
+				{{"GHSA-bbbb"}, nil, nil, {"GHSA-bbbb"}},
+				{{"CVE-2024-0001"}, {"GHSA-aaaa"}, nil, {"GHSA-aaaa"}},
+			},
+		},
+		{
+			name: "related IDs are not merged",
+			vulns: []Vulnerability{
+				aliasedVulnerability("GHSA-aaaa", []string{"CVE-2024-0001"}, []string{"CVE-2024-0002", "GHSA-bbbb"}),
+				aliasedVulnerability("GHSA-bbbb", []string{"CVE-2024-0002"}, []string{"CVE-2024-0001"}),
+				aliasedVulnerability("GHSA-cccc", []string{"CVE-2024-0001"}, []string{"GHSA-aaaa"}),
+			},
+			pref: DefaultIDPreference,
+			want: [][4][]string{
+				{{"CVE-2024-0001"}, {"GHSA-aaaa", "GHSA-cccc"}, {"CVE-2024-0002", "GHSA-bbbb"}, {"GHSA-aaaa", "GHSA-cccc"}},
+				{{"CVE-2024-0002"}, {"GHSA-bbbb"}, {"CVE-2024-0001"}, {"GHSA-bbbb"}},
+			},
+		},
+		{
+			name: "preference of ecosystem IDs",
+			vulns: []Vulnerability{
+				aliasedVulnerability("GHSA-aaaa", []string{"CVE-2024-0001", "RUSTSEC-2024-0001"}, nil),
+			},
+			pref: ParseIDPreference("rustsec, ghsa"),
+			want: [][4][]string{{{"RUSTSEC-2024-0001"}, {"CVE-2024-0001", "GHSA-aaaa"}, nil, {"GHSA-aaaa"}}},
+		},
+		{
+			name: "lowest ID of the same prefix",
+			vulns: []Vulnerability{
+				aliasedVulnerability("GHSA-zzzz", []string{"CVE-2024-0002", "CVE-2024-0001"}, nil),
+			},
+			pref: DefaultIDPreference,
+			want: [][4][]string{{{"CVE-2024-0001"}, {"CVE-2024-0002", "GHSA-zzzz"}, nil, {"GHSA-zzzz"}}},
+		},
+		{
+			name: "no listed prefix",
+			vulns: []Vulnerability{
+				aliasedVulnerability("PYSEC-2024-2", []string{"OSV-2024-1"}, nil),
+			},
+			pref: DefaultIDPreference,
+			want: [][4][]string{{{"OSV-2024-1"}, {"PYSEC-2024-2"}, nil, {"PYSEC-2024-2"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := groupIDs(GroupVulnerabilities(tt.vulns, tt.pref)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GroupVulnerabilities = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVulnerabilityGroupPrimary(t *testing.T) {
+	groups := GroupVulnerabilities([]Vulnerability{
+		{ID: "GHSA-aaaa", Aliases: []string{"CVE-2024-0001"}, Summary: "only known as an alias"},
+		{ID: "PYSEC-2024-1", Aliases: []string{"GHSA-bbbb"}},
+		{ID: "GHSA-bbbb", Summary: "the primary record"},
+	}, DefaultIDPreference)
+
+	if len(groups) != 2 {
+		t.Fatalf("GroupVulnerabilities = %d groups, want 2", len(groups))
+	}
+
+	if got := groups[0].Primary(); got.ID != "GHSA-aaaa" {
+		t.Errorf("Primary = %s, want the first record as CVE-2024-0001 has none", got.ID)
+	}
+
+	if got := groups[1].Primary(); got.ID != "GHSA-bbbb" {
+		t.Errorf("Primary = %s, want the record of the primary ID", got.ID)
+	}
+
+	if got := groups[1].IDs(); !reflect.DeepEqual(got, []string{"GHSA-bbbb", "PYSEC-2024-1"}) {
+		t.Errorf("IDs = %v, want [GHSA-bbbb PYSEC-2024-1]", got)
+	}
+}
+
+func TestParseIDPreference(t *testing.T) {
+	tests := []struct {
+		str  string
+		want IDPreference
+	}{
+		{"CVE,GHSA", IDPreference{"CVE", "GHSA"}},
+		{" ghsa , , pysec ", IDPreference{"GHSA", "PYSEC"}},
+		{"", DefaultIDPreference},
+		{" , ", DefaultIDPreference},
+	}
+
+	for _, tt := range tests {
+		if got := ParseIDPreference(tt.str); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseIDPreference(%q) = %v, want %v", tt.str, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+// Package cdxpb defines the messages of the CycloneDX 1.6 protobuf format, generated from the bom-1.6.proto
+// of the CycloneDX specification. The field numbers of the earlier formats are kept by 1.6, so BOMs of the
+// 1.3 to 1.5 formats are read with the same messages.
+package cdxpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative "--go_opt=Mbom-1.6.proto=github.com/ortelius/scec-deppkg/models/cdxpb;cdxpb" bom-1.6.proto
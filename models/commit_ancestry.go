@@ -0,0 +1,256 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownRepository defines the error for a repository that the CommitAncestry does not have
+var ErrUnknownRepository = errors.New("unknown repository")
+
+// ErrInvalidCommit defines the error for a commit that is not an abbreviated or full hexadecimal hash
+var ErrInvalidCommit = errors.New("invalid commit")
+
+// ErrNoCommitAncestry defines the error for a "GIT" range that cannot be evaluated as no CommitAncestry is set
+var ErrNoCommitAncestry = errors.New("no commit ancestry to evaluate GIT ranges")
+
+// CommitAncestry defines the interface used to evaluate "GIT" ranges, which
+// describe the affected commits by their position in the repository history
+type CommitAncestry interface {
+	// IsAncestor reports if the ancestor commit is reachable from the
+	// descendant commit in the given repository
+	IsAncestor(repo, ancestor, descendant string) (bool, error)
+}
+
+var commitAncestry CommitAncestry
+var commitAncestryLock sync.RWMutex
+
+// SetCommitAncestry sets the CommitAncestry used by IsAffected to evaluate "GIT" ranges.
+//
+// If no CommitAncestry is set, "GIT" ranges cannot be evaluated for a package with
+// a commit, so the match is indeterminate unless another range shows it is affected.
+func SetCommitAncestry(ca CommitAncestry) {
+	commitAncestryLock.Lock()
+	defer commitAncestryLock.Unlock()
+
+	commitAncestry = ca
+}
+
+func getCommitAncestry() CommitAncestry {
+	commitAncestryLock.RLock()
+	defer commitAncestryLock.RUnlock()
+
+	return commitAncestry
+}
+
+// BareRepoAncestry is a CommitAncestry backed by local bare clones (or mirrors)
+// of the repositories, laid out under Dir by host and path, i.e. a repo of
+// https://github.com/openssl/openssl is expected at Dir/github.com/openssl/openssl.git
+type BareRepoAncestry struct {
+	Dir string
+}
+
+// NewBareRepoAncestry returns a BareRepoAncestry for the given directory
+func NewBareRepoAncestry(dir string) *BareRepoAncestry {
+	return &BareRepoAncestry{Dir: dir}
+}
+
+// repoPath maps the repository url to the location of its bare clone
+func (b *BareRepoAncestry) repoPath(repo string) (string, error) {
+	u, err := url.Parse(strings.TrimPrefix(repo, "git+"))
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("%w %s", ErrUnknownRepository, repo)
+	}
+
+	path := filepath.Join(b.Dir, u.Host, filepath.FromSlash(strings.Trim(u.Path, "/")))
+
+	if !strings.HasSuffix(path, ".git") {
+		path += ".git"
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("%w %s", ErrUnknownRepository, repo)
+	}
+
+	return path, nil
+}
+
+// isCommitHash checks if the commit is an abbreviated or full SHA-1 hash, so that it cannot be read as an option by git
+func isCommitHash(commit string) bool {
+	return MustCompile(`^[0-9a-fA-F]{7,40}$`).MatchString(commit)
+}
+
+// IsAncestor reports if the ancestor commit is reachable from the descendant commit
+// using "git merge-base --is-ancestor"
+func (b *BareRepoAncestry) IsAncestor(repo, ancestor, descendant string) (bool, error) {
+	for _, commit := range []string{ancestor, descendant} {
+		if !isCommitHash(commit) {
+			return false, fmt.Errorf("%w %q", ErrInvalidCommit, commit)
+		}
+	}
+
+	path, err := b.repoPath(repo)
+	if err != nil {
+		return false, err
+	}
+
+	//nolint:gosec // the commits are checked to be hashes and passed as arguments, not interpreted by a shell
+	cmd := exec.Command("git", "--git-dir", path, "merge-base", "--is-ancestor", "--end-of-options", ancestor, descendant)
+
+	err = cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+
+	// an exit code of 1 means the commit is not an ancestor, anything else is an error
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+
+	return false, fmt.Errorf("failed to compare commits %s and %s in %s: %w", ancestor, descendant, repo, err)
+}
+
+// rangeContainsCommit checks if the package commit is within the "GIT" range,
+// which is the case when it descends from an introduced commit without also
+// descending from a fixed commit (or a commit after a last_affected commit)
+// that itself descends from that introduced commit.
+//
+// See: https://ossf.github.io/osv-schema/#affectedrangestype-field
+func rangeContainsCommit(ar Range, pkg PackageDetails) (bool, error) {
+	ca := getCommitAncestry()
+
+	if pkg.Commit == "" || ar.Repo == "" || len(ar.Events) == 0 {
+		return false, nil
+	}
+
+	if ca == nil {
+		return false, fmt.Errorf("%w in %s", ErrNoCommitAncestry, ar.Repo)
+	}
+
+	// a commit is considered to be an ancestor of itself
+	isAncestor := func(ancestor, descendant string) (bool, error) {
+		if ancestor == descendant {
+			return true, nil
+		}
+
+		return ca.IsAncestor(ar.Repo, ancestor, descendant)
+	}
+
+	var limits []string
+
+	for _, e := range ar.Events {
+		if e.Limit != "" {
+			limits = append(limits, e.Limit)
+		}
+	}
+
+	if len(limits) > 0 {
+		withinLimit, err := commitWithinLimits(pkg.Commit, limits, isAncestor)
+		if err != nil || !withinLimit {
+			return false, err
+		}
+	}
+
+	for _, e := range ar.Events {
+		if e.Introduced == "" {
+			continue
+		}
+
+		if e.Introduced != "0" {
+			introduced, err := isAncestor(e.Introduced, pkg.Commit)
+			if err != nil {
+				return false, err
+			}
+
+			if !introduced {
+				continue
+			}
+		}
+
+		resolved, err := commitResolvedSince(ar.Events, e.Introduced, pkg.Commit, isAncestor)
+		if err != nil {
+			return false, err
+		}
+
+		if !resolved {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// commitWithinLimits checks that the commit comes strictly before at least one of the limits
+func commitWithinLimits(commit string, limits []string, isAncestor func(string, string) (bool, error)) (bool, error) {
+	for _, limit := range limits {
+		if limit == "*" {
+			return true, nil
+		}
+
+		if limit == commit {
+			continue
+		}
+
+		before, err := isAncestor(commit, limit)
+		if err != nil {
+			return false, err
+		}
+
+		if before {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// commitResolvedSince checks if the commit descends from a fixed commit, or from a
+// commit after a last_affected commit, which itself descends from the introduced commit
+func commitResolvedSince(events []Event, introduced, commit string, isAncestor func(string, string) (bool, error)) (bool, error) {
+	for _, e := range events {
+		resolvedBy := e.Fixed
+
+		if resolvedBy == "" {
+			// the last affected commit itself is still affected
+			if e.LastAffected == "" || e.LastAffected == commit {
+				continue
+			}
+
+			resolvedBy = e.LastAffected
+		}
+
+		resolved, err := isAncestor(resolvedBy, commit)
+		if err != nil {
+			return false, err
+		}
+
+		if !resolved {
+			continue
+		}
+
+		if introduced == "0" {
+			return true, nil
+		}
+
+		// the fix only applies if it came after the introduced commit
+		after, err := isAncestor(introduced, resolvedBy)
+		if err != nil {
+			return false, err
+		}
+
+		if after {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
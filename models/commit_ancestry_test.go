@@ -0,0 +1,160 @@
+package models
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// linearAncestry is a CommitAncestry of a single repository with a linear history, in the order of the commits
+type linearAncestry []string
+
+func (la linearAncestry) IsAncestor(_, ancestor, descendant string) (bool, error) {
+	a, d := -1, -1
+
+	for i, c := range la {
+		if c == ancestor {
+			a = i
+		}
+		if c == descendant {
+			d = i
+		}
+	}
+
+	if a == -1 || d == -1 {
+		return false, ErrInvalidCommit
+	}
+
+	return a <= d, nil
+}
+
+func gitVulnerability(events ...Event) Vulnerability {
+	return Vulnerability{
+		ID: "TEST-GIT",
+		Affected: []Affected{{
+			Package: Package{Ecosystem: "Go", Name: "example.com/pkg"},
+			Ranges:  []Range{{Type: RangeGit, Repo: "https://example.com/pkg", Events: events}},
+		}},
+	}
+}
+
+func TestRangeContainsCommit(t *testing.T) {
+	history := linearAncestry{"aaaaaaa", "bbbbbbb", "ccccccc", "ddddddd", "eeeeeee"}
+
+	SetCommitAncestry(history)
+	defer SetCommitAncestry(nil)
+
+	tests := []struct {
+		events []Event
+		commit string
+		want   bool
+	}{
+		{[]Event{{Introduced: "bbbbbbb"}, {Fixed: "ddddddd"}}, "aaaaaaa", false},
+		{[]Event{{Introduced: "bbbbbbb"}, {Fixed: "ddddddd"}}, "bbbbbbb", true},
+		{[]Event{{Introduced: "bbbbbbb"}, {Fixed: "ddddddd"}}, "ccccccc", true},
+		{[]Event{{Introduced: "bbbbbbb"}, {Fixed: "ddddddd"}}, "ddddddd", false},
+		{[]Event{{Introduced: "0"}, {Fixed: "ccccccc"}}, "aaaaaaa", true},
+		{[]Event{{Introduced: "0"}, {LastAffected: "ccccccc"}}, "ccccccc", true},
+		{[]Event{{Introduced: "0"}, {LastAffected: "ccccccc"}}, "ddddddd", false},
+		{[]Event{{Introduced: "0"}, {Limit: "ccccccc"}}, "bbbbbbb", true},
+		{[]Event{{Introduced: "0"}, {Limit: "ccccccc"}}, "ccccccc", false},
+	}
+
+	for _, tt := range tests {
+		got, err := IsAffected(gitVulnerability(tt.events...), PackageDetails{Name: "example.com/pkg", Commit: tt.commit, Ecosystem: "Go", CompareAs: "Go"})
+		if err != nil {
+			t.Errorf("IsAffected(%v, %s) returned %v", tt.events, tt.commit, err)
+		}
+
+		if got != tt.want {
+			t.Errorf("IsAffected(%v, %s) = %v, want %v", tt.events, tt.commit, got, tt.want)
+		}
+	}
+}
+
+func TestRangeContainsCommitWithoutAncestry(t *testing.T) {
+	SetCommitAncestry(nil)
+
+	v := gitVulnerability(Event{Introduced: "0"}, Event{Fixed: "ccccccc"})
+
+	got, err := IsAffected(v, PackageDetails{Name: "example.com/pkg", Commit: "aaaaaaa", Ecosystem: "Go", CompareAs: "Go"})
+	if got || !errors.Is(err, ErrNoCommitAncestry) {
+		t.Errorf("IsAffected without a CommitAncestry = %v, %v, want false and ErrNoCommitAncestry", got, err)
+	}
+
+	// packages without a commit have nothing for the range to be evaluated against
+	got, err = IsAffected(v, PackageDetails{Name: "example.com/pkg", Version: "1.0.0", Ecosystem: "Go", CompareAs: "Go"})
+	if got || err != nil {
+		t.Errorf("IsAffected without a commit = %v, %v, want false and no error", got, err)
+	}
+}
+
+func TestBareRepoAncestryRejectsInvalidCommits(t *testing.T) {
+	b := NewBareRepoAncestry(t.TempDir())
+
+	for _, commit := range []string{"--output=/tmp/x", "-h", "HEAD", "abc", "aaaaaaa bbbbbbb", strings.Repeat("a", 41)} {
+		if _, err := b.IsAncestor("https://example.com/pkg", commit, "aaaaaaa"); !errors.Is(err, ErrInvalidCommit) {
+			t.Errorf("IsAncestor(%q, ...) returned %v, want ErrInvalidCommit", commit, err)
+		}
+
+		if _, err := b.IsAncestor("https://example.com/pkg", "aaaaaaa", commit); !errors.Is(err, ErrInvalidCommit) {
+			t.Errorf("IsAncestor(..., %q) returned %v, want ErrInvalidCommit", commit, err)
+		}
+	}
+}
+
+func TestBareRepoAncestry(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	work := filepath.Join(dir, "work")
+
+	git := func(args ...string) string {
+		t.Helper()
+
+		cmd := exec.Command("git", args...)
+		cmd.Dir = work
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+
+		return strings.TrimSpace(string(out))
+	}
+
+	if err := os.MkdirAll(work, 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	git("init", "-q")
+
+	var commits []string
+
+	for range 3 {
+		git("commit", "-q", "--allow-empty", "-m", "commit")
+		commits = append(commits, git("rev-parse", "HEAD"))
+	}
+
+	git("clone", "-q", "--bare", ".", filepath.Join(dir, "repos", "example.com", "pkg.git"))
+
+	b := NewBareRepoAncestry(filepath.Join(dir, "repos"))
+
+	if ok, err := b.IsAncestor("https://example.com/pkg", commits[0], commits[2]); err != nil || !ok {
+		t.Errorf("IsAncestor(first, last) = %v, %v, want true", ok, err)
+	}
+
+	if ok, err := b.IsAncestor("https://example.com/pkg", commits[2][:12], commits[0]); err != nil || ok {
+		t.Errorf("IsAncestor(last, first) = %v, %v, want false", ok, err)
+	}
+
+	if _, err := b.IsAncestor("https://example.com/other", commits[0], commits[2]); !errors.Is(err, ErrUnknownRepository) {
+		t.Errorf("IsAncestor of an unknown repository returned %v, want ErrUnknownRepository", err)
+	}
+}
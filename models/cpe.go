@@ -0,0 +1,205 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidCPE defines the error for a string that is not a CPE 2.3 formatted string or CPE 2.2 URI
+var ErrInvalidCPE = errors.New("invalid cpe")
+
+// Logical values of a CPE attribute
+const (
+	CPEAny           = "*"
+	CPENotApplicable = "-"
+)
+
+// CPE defines the attributes of a CPE name, which are kept in the escaped form of a
+// CPE 2.3 formatted string, so that "*" and "?" are wildcards unless escaped
+//
+// See https://nvlpubs.nist.gov/nistpubs/Legacy/IR/nistir7695.pdf
+type CPE struct {
+	Part      string `json:"part"`
+	Vendor    string `json:"vendor"`
+	Product   string `json:"product"`
+	Version   string `json:"version"`
+	Update    string `json:"update"`
+	Edition   string `json:"edition"`
+	Language  string `json:"language"`
+	SWEdition string `json:"sw_edition"`
+	TargetSW  string `json:"target_sw"`
+	TargetHW  string `json:"target_hw"`
+	Other     string `json:"other"`
+}
+
+// attributes returns the attributes of the CPE in the order of a formatted string
+func (c CPE) attributes() []string {
+	return []string{
+		c.Part, c.Vendor, c.Product, c.Version, c.Update, c.Edition,
+		c.Language, c.SWEdition, c.TargetSW, c.TargetHW, c.Other,
+	}
+}
+
+// String returns the CPE as a CPE 2.3 formatted string
+func (c CPE) String() string {
+	return "cpe:2.3:" + strings.Join(c.attributes(), ":")
+}
+
+// splitCPE splits a CPE 2.3 formatted string on the colons that are not escaped
+func splitCPE(str string) []string {
+	var parts []string
+
+	start := 0
+
+	for i := 0; i < len(str); i++ {
+		switch str[i] {
+		case '\\':
+			i++
+		case ':':
+			parts = append(parts, str[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, str[start:])
+}
+
+// newCPE creates a CPE from its attributes, where missing and empty attributes are ANY
+func newCPE(attrs []string) CPE {
+	values := make([]string, 11)
+
+	for i := range values {
+		values[i] = CPEAny
+
+		if i < len(attrs) && attrs[i] != "" {
+			values[i] = strings.ToLower(attrs[i])
+		}
+	}
+
+	return CPE{
+		values[0], values[1], values[2], values[3], values[4], values[5],
+		values[6], values[7], values[8], values[9], values[10],
+	}
+}
+
+// ParseCPE parses a CPE 2.3 formatted string, i.e. "cpe:2.3:a:openssl:openssl:3.0.7:*:*:*:*:*:*:*",
+// or a CPE 2.2 URI, i.e. "cpe:/a:openssl:openssl:3.0.7"
+func ParseCPE(str string) (CPE, error) {
+	str = strings.TrimSpace(str)
+
+	if attrs, ok := strings.CutPrefix(str, "cpe:2.3:"); ok {
+		// trailing components are sometimes left out, in which case they are ANY
+		parts := splitCPE(attrs)
+		if len(parts) < 3 || len(parts) > 11 {
+			return CPE{}, fmt.Errorf("%w %q: expected at most 13 components, including the vendor and product", ErrInvalidCPE, str)
+		}
+
+		return newCPE(parts), nil
+	}
+
+	if attrs, ok := strings.CutPrefix(str, "cpe:/"); ok {
+		parts := strings.Split(attrs, ":")
+		if len(parts) > 7 {
+			return CPE{}, fmt.Errorf("%w %q: expected at most 7 components", ErrInvalidCPE, str)
+		}
+
+		for i, p := range parts {
+			decoded, err := url.PathUnescape(p)
+			if err != nil {
+				return CPE{}, fmt.Errorf("%w %q: %w", ErrInvalidCPE, str, err)
+			}
+
+			// a URI has no wildcards, so anything that would be one in a formatted string is escaped
+			parts[i] = MustCompile(`([^A-Za-z0-9_])`).ReplaceAllString(decoded, `\$1`)
+		}
+
+		return newCPE(parts), nil
+	}
+
+	return CPE{}, fmt.Errorf("%w %q", ErrInvalidCPE, str)
+}
+
+// unescapeCPE removes the escaping of an attribute value
+func unescapeCPE(value string) string {
+	return MustCompile(`\\(.)`).ReplaceAllString(value, "$1")
+}
+
+// cpeValuePattern converts an attribute value containing wildcards into a regular expression
+func cpeValuePattern(value string) string {
+	var sb strings.Builder
+
+	sb.WriteString("^")
+
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '\\':
+			if i+1 < len(value) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(value[i : i+1]))
+			}
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	sb.WriteString("$")
+
+	return sb.String()
+}
+
+// matchCPEValue checks if the attribute value of a component matches that of a match criteria,
+// where the criteria may be ANY, NA or contain wildcards
+func matchCPEValue(criteria, value string) bool {
+	switch {
+	case criteria == CPEAny:
+		return true
+	case criteria == CPENotApplicable || value == CPENotApplicable:
+		return criteria == value
+	case strings.ContainsAny(strings.ReplaceAll(criteria, `\\`, ""), "*?"):
+		return MustCompile(cpeValuePattern(criteria)).MatchString(value)
+	}
+
+	return unescapeCPE(criteria) == unescapeCPE(value)
+}
+
+// MatchesCriteria checks if the CPE of a component matches a CPE match criteria, apart from its version.
+//
+// The part, vendor and product must always match, while the other attributes match if they
+// are ANY in the component, as a component CPE rarely has all of its attributes set.
+func (c CPE) MatchesCriteria(criteria CPE) bool {
+	attrs, criteriaAttrs := c.attributes(), criteria.attributes()
+
+	for i := range attrs {
+		// the version is compared by the caller, as it may be a range
+		if i == 3 {
+			continue
+		}
+
+		if i > 2 && attrs[i] == CPEAny {
+			continue
+		}
+
+		if !matchCPEValue(criteriaAttrs[i], attrs[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compareCPEVersions compares two versions of a CPE, which have no ecosystem, using the semver like ordering
+func compareCPEVersions(a, b string) int {
+	v := SemverVersion{ParseSemverLikeVersion(unescapeCPE(a), -1)}
+	w := SemverVersion{ParseSemverLikeVersion(unescapeCPE(b), -1)}
+
+	return v.compare(w)
+}
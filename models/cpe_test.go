@@ -0,0 +1,253 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseCPE(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"cpe:2.3:a:openssl:openssl:3.0.7:*:*:*:*:*:*:*", "cpe:2.3:a:openssl:openssl:3.0.7:*:*:*:*:*:*:*"},
+		{"cpe:2.3:a:OpenSSL:OpenSSL:3.0.7", "cpe:2.3:a:openssl:openssl:3.0.7:*:*:*:*:*:*:*"},
+		{"cpe:2.3:a:apache:log4j", "cpe:2.3:a:apache:log4j:*:*:*:*:*:*:*:*"},
+		{`cpe:2.3:a:vendor:product\:name:1.0`, `cpe:2.3:a:vendor:product\:name:1.0:*:*:*:*:*:*:*`},
+		{"cpe:/a:openssl:openssl:3.0.7", "cpe:2.3:a:openssl:openssl:3\\.0\\.7:*:*:*:*:*:*:*"},
+		{"cpe:/a:haxx:curl:7.64.0:-", "cpe:2.3:a:haxx:curl:7\\.64\\.0:\\-:*:*:*:*:*:*"},
+	}
+
+	for _, tt := range tests {
+		cpe, err := ParseCPE(tt.input)
+		if err != nil {
+			t.Errorf("ParseCPE(%q) returned an error: %v", tt.input, err)
+
+			continue
+		}
+
+		if got := cpe.String(); got != tt.want {
+			t.Errorf("ParseCPE(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseCPEErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"openssl:openssl:3.0.7",
+		"cpe:2.3:a:openssl",
+		"cpe:2.3:a:b:c:d:e:f:g:h:i:j:k:l",
+		"cpe:/a:b:c:d:e:f:g:h",
+		"cpe:/a:b:%zz",
+	}
+
+	for _, input := range tests {
+		if _, err := ParseCPE(input); !errors.Is(err, ErrInvalidCPE) {
+			t.Errorf("ParseCPE(%q) = %v, want ErrInvalidCPE", input, err)
+		}
+	}
+}
+
+func TestCPEMatchesCriteria(t *testing.T) {
+	tests := []struct {
+		cpe      string
+		criteria string
+		want     bool
+	}{
+		{"cpe:2.3:a:openssl:openssl:3.0.7", "cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*", true},
+		{"cpe:2.3:a:openssl:openssl:3.0.7", "cpe:2.3:a:openssl:libressl:*:*:*:*:*:*:*:*", false},
+		{"cpe:2.3:a:openssl:openssl:3.0.7", "cpe:2.3:o:openssl:openssl:*:*:*:*:*:*:*:*", false},
+		{"cpe:2.3:a:openssl:openssl:3.0.7", "cpe:2.3:a:open*:openssl:*:*:*:*:*:*:*:*", true},
+		{"cpe:2.3:a:openssl:openssl:3.0.7", "cpe:2.3:a:opens?l:openssl:*:*:*:*:*:*:*:*", true},
+		{"cpe:2.3:a:apache:log4j:2.14.1", "cpe:2.3:a:apache:log4j:*:beta1:*:*:*:*:*:*", true},
+		{"cpe:2.3:a:apache:log4j:2.14.1:rc1", "cpe:2.3:a:apache:log4j:*:beta1:*:*:*:*:*:*", false},
+		{"cpe:2.3:a:apache:log4j:2.14.1:-", "cpe:2.3:a:apache:log4j:*:-:*:*:*:*:*:*", true},
+		{"cpe:2.3:a:apache:log4j:2.14.1:-", "cpe:2.3:a:apache:log4j:*:beta1:*:*:*:*:*:*", false},
+		{"cpe:2.3:a:nodejs:node.js:18.0.0:*:*:*:*:*:*:*", "cpe:2.3:a:nodejs:node.js:*:*:*:*:*:*:*:*", true},
+	}
+
+	for _, tt := range tests {
+		cpe, err := ParseCPE(tt.cpe)
+		if err != nil {
+			t.Fatalf("ParseCPE(%q) returned an error: %v", tt.cpe, err)
+		}
+
+		criteria, err := ParseCPE(tt.criteria)
+		if err != nil {
+			t.Fatalf("ParseCPE(%q) returned an error: %v", tt.criteria, err)
+		}
+
+		if got := cpe.MatchesCriteria(criteria); got != tt.want {
+			t.Errorf("%s matches %s = %v, want %v", tt.cpe, tt.criteria, got, tt.want)
+		}
+	}
+}
+
+func TestNVDCPEMatchVersions(t *testing.T) {
+	tests := []struct {
+		match   NVDCPEMatch
+		version string
+		want    bool
+	}{
+		{NVDCPEMatch{Criteria: "cpe:2.3:a:openssl:openssl:3.0.7:*:*:*:*:*:*:*"}, "3.0.7", true},
+		{NVDCPEMatch{Criteria: "cpe:2.3:a:openssl:openssl:3.0.7:*:*:*:*:*:*:*"}, "3.0.8", false},
+		{NVDCPEMatch{Criteria: "cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*", VersionStartIncluding: "3.0.0", VersionEndExcluding: "3.0.8"}, "3.0.0", true},
+		{NVDCPEMatch{Criteria: "cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*", VersionStartIncluding: "3.0.0", VersionEndExcluding: "3.0.8"}, "3.0.7", true},
+		{NVDCPEMatch{Criteria: "cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*", VersionStartIncluding: "3.0.0", VersionEndExcluding: "3.0.8"}, "3.0.8", false},
+		{NVDCPEMatch{Criteria: "cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*", VersionStartIncluding: "3.0.0", VersionEndExcluding: "3.0.8"}, "1.1.1", false},
+		{NVDCPEMatch{Criteria: "cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*", VersionStartExcluding: "1.0.0", VersionEndIncluding: "1.1.1"}, "1.0.0", false},
+		{NVDCPEMatch{Criteria: "cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*", VersionStartExcluding: "1.0.0", VersionEndIncluding: "1.1.1"}, "1.1.1", true},
+		{NVDCPEMatch{Criteria: "cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*", VersionEndExcluding: "3.0.8"}, CPEAny, false},
+		{NVDCPEMatch{Criteria: "cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*"}, CPEAny, true},
+	}
+
+	cpe, err := ParseCPE("cpe:2.3:a:openssl:openssl")
+	if err != nil {
+		t.Fatalf("ParseCPE returned an error: %v", err)
+	}
+
+	for _, tt := range tests {
+		if got := tt.match.Matches(cpe, tt.version); got != tt.want {
+			t.Errorf("%+v matches %s = %v, want %v", tt.match, tt.version, got, tt.want)
+		}
+	}
+}
+
+// nvdFixture is a NVD CVE JSON 2.0 feed with a range, a platform bound configuration and a rejected CVE
+const nvdFixture = `{
+	"vulnerabilities": [
+		{"cve": {
+			"id": "CVE-2023-0286",
+			"published": "2023-02-08T20:15:23.973",
+			"lastModified": "2024-02-04T09:15:09.113",
+			"vulnStatus": "Modified",
+			"descriptions": [{"lang": "en", "value": "type confusion in X.400 address processing"}],
+			"metrics": {"cvssMetricV31": [{"source": "nvd@nist.gov", "type": "Primary", "cvssData": {"vectorString": "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:H"}}]},
+			"configurations": [{"nodes": [{"operator": "OR", "cpeMatch": [
+				{"vulnerable": true, "criteria": "cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*", "versionStartIncluding": "3.0.0", "versionEndExcluding": "3.0.8"},
+				{"vulnerable": true, "criteria": "cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*", "versionStartIncluding": "1.1.1", "versionEndExcluding": "1.1.1t"}
+			]}]}]
+		}},
+		{"cve": {
+			"id": "CVE-2023-0464",
+			"published": "2023-03-22T17:15:13.063",
+			"lastModified": "2024-02-04T09:15:09.113",
+			"vulnStatus": "Modified",
+			"configurations": [{"operator": "AND", "nodes": [
+				{"operator": "OR", "cpeMatch": [{"vulnerable": true, "criteria": "cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*", "versionStartIncluding": "3.0.0", "versionEndExcluding": "3.0.9"}]},
+				{"operator": "OR", "cpeMatch": [{"vulnerable": false, "criteria": "cpe:2.3:o:linux:linux_kernel:-:*:*:*:*:*:*:*"}]}
+			]}]
+		}},
+		{"cve": {
+			"id": "CVE-2023-9999",
+			"published": "2023-03-22T17:15:13.063",
+			"lastModified": "2023-04-01T00:00:00.000",
+			"vulnStatus": "Rejected",
+			"configurations": [{"nodes": [{"operator": "OR", "cpeMatch": [
+				{"vulnerable": true, "criteria": "cpe:2.3:a:haxx:curl:*:*:*:*:*:*:*:*", "versionEndExcluding": "8.0.0"}
+			]}]}]
+		}}
+	]
+}`
+
+func TestCPEIndexMatch(t *testing.T) {
+	idx := NewCPEIndex()
+
+	n, err := idx.Load(strings.NewReader(nvdFixture))
+	if err != nil {
+		t.Fatalf("Load returned an error: %v", err)
+	}
+
+	if n != 3 || idx.Len() != 3 {
+		t.Fatalf("Load added %d CVEs, and the index has %d, want 3", n, idx.Len())
+	}
+
+	tests := []struct {
+		cpe         string
+		version     string
+		want        map[string]string
+		recommended string
+	}{
+		{"cpe:2.3:a:openssl:openssl", "3.0.7", map[string]string{"CVE-2023-0286": "3.0.8", "CVE-2023-0464": "3.0.9"}, "3.0.9"},
+		{"cpe:2.3:a:openssl:openssl:3.0.8", "", map[string]string{"CVE-2023-0464": "3.0.9"}, "3.0.9"},
+		{"cpe:2.3:a:openssl:openssl", "3.0.9", map[string]string{}, ""},
+		{"cpe:2.3:a:openssl:openssl", "", map[string]string{}, ""},
+		{"cpe:2.3:a:openssl:libressl", "3.0.7", map[string]string{}, ""},
+		{"cpe:2.3:a:haxx:curl", "7.88.0", map[string]string{"CVE-2023-9999": "8.0.0"}, "8.0.0"},
+	}
+
+	for _, tt := range tests {
+		cpe, err := ParseCPE(tt.cpe)
+		if err != nil {
+			t.Fatalf("ParseCPE(%q) returned an error: %v", tt.cpe, err)
+		}
+
+		matches := idx.Match(cpe, tt.version)
+
+		got := make(map[string]string)
+		for _, m := range matches {
+			got[m.Vulnerability.ID] = m.FixedIn
+		}
+
+		if len(got) != len(tt.want) {
+			t.Errorf("Match(%s, %q) = %v, want %v", tt.cpe, tt.version, got, tt.want)
+		}
+
+		for id, fixedIn := range tt.want {
+			if got[id] != fixedIn {
+				t.Errorf("Match(%s, %q) fixed %s in %q, want %q", tt.cpe, tt.version, id, got[id], fixedIn)
+			}
+		}
+
+		if recommended := RecommendedCPEVersion(matches); recommended != tt.recommended {
+			t.Errorf("RecommendedCPEVersion of %s at %q = %q, want %q", tt.cpe, tt.version, recommended, tt.recommended)
+		}
+	}
+}
+
+func TestNVDCVEToVulnerability(t *testing.T) {
+	idx := NewCPEIndex()
+
+	if _, err := idx.Load(strings.NewReader(nvdFixture)); err != nil {
+		t.Fatalf("Load returned an error: %v", err)
+	}
+
+	cpe, err := ParseCPE("cpe:2.3:a:openssl:openssl:3.0.7")
+	if err != nil {
+		t.Fatalf("ParseCPE returned an error: %v", err)
+	}
+
+	for _, m := range idx.Match(cpe, "") {
+		if m.Vulnerability.ID != "CVE-2023-0286" {
+			continue
+		}
+
+		v := m.Vulnerability
+
+		if v.Details != "type confusion in X.400 address processing" {
+			t.Errorf("Details = %q", v.Details)
+		}
+
+		if len(v.Severity) != 1 || v.Severity[0].Type != SeverityCVSSV3 {
+			t.Errorf("Severity = %+v, want a single CVSS_V3 score", v.Severity)
+		}
+
+		if v.Published.IsZero() || !v.Withdrawn.IsZero() {
+			t.Errorf("Published = %v, Withdrawn = %v", v.Published, v.Withdrawn)
+		}
+
+		return
+	}
+
+	t.Errorf("CVE-2023-0286 did not match %s", cpe)
+}
+
+func TestNVDCVERejected(t *testing.T) {
+	v := NVDCVE{ID: "CVE-2023-9999", LastModified: "2023-04-01T00:00:00.000", VulnStatus: "Rejected"}.ToVulnerability()
+
+	if v.Withdrawn.IsZero() || !v.Withdrawn.Equal(v.Modified) {
+		t.Errorf("a rejected CVE should be withdrawn when it was last modified, got %v", v.Withdrawn)
+	}
+}
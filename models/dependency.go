@@ -0,0 +1,105 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+// How a package is depended on by the root component of its sbom
+const (
+	DependencyDirect     = "direct"     // the root component depends on the package itself
+	DependencyTransitive = "transitive" // the package is a dependency of another dependency of the root component
+	DependencyUnknown    = "unknown"    // the sbom has no root component, or no path from it to the package
+)
+
+// DependencyVertex defines a component of an sbom in its dependency graph, identified by its ID within the graph
+type DependencyVertex struct {
+	ID      string
+	Name    string
+	Version string
+	Root    bool
+}
+
+// DependencyEdge defines a DEPENDS_ON or CONTAINS relationship of an sbom from one vertex ID to another
+type DependencyEdge struct {
+	From string
+	To   string
+}
+
+// DependencyPath defines whether the root component of an sbom depends on a component directly or transitively,
+// along with the depth of the component and the name and version of each component on the path that introduces it
+type DependencyPath struct {
+	Dependency       string
+	Depth            int
+	IntroductionPath []string
+}
+
+// label returns the name and version of the component, as shown in an introduction path
+func (v DependencyVertex) label() string {
+	if v.Version == "" {
+		return v.Name
+	}
+
+	return v.Name + "@" + v.Version
+}
+
+// ClassifyDependencies classifies each component of an sbom other than its root component, keyed by vertex ID, by a breadth
+// first traversal of the edges from the root component up to the depth, which finds the shortest path to each component
+// and visits each component once, so that cycles end. Of the paths of the same length, the one through the edges that
+// come first is used.
+//
+// Every component is unknown when the sbom has no root component, as are those that cannot be reached from it.
+func ClassifyDependencies(vertices []DependencyVertex, edges []DependencyEdge, depth int) map[string]DependencyPath {
+	paths := make(map[string]DependencyPath, len(vertices))
+	byID := make(map[string]DependencyVertex, len(vertices))
+	root := ""
+
+	for _, v := range vertices {
+		byID[v.ID] = v
+
+		if v.Root && root == "" {
+			root = v.ID
+		} else {
+			paths[v.ID] = DependencyPath{Dependency: DependencyUnknown}
+		}
+	}
+
+	if root == "" {
+		return paths
+	}
+
+	outbound := make(map[string][]string)
+
+	for _, e := range edges {
+		outbound[e.From] = append(outbound[e.From], e.To)
+	}
+
+	introduced := map[string][]string{root: {byID[root].label()}}
+	queue := []string{root}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		path := introduced[id]
+		if len(path) > depth {
+			continue
+		}
+
+		for _, to := range outbound[id] {
+			v, ok := byID[to]
+			if _, seen := introduced[to]; seen || !ok {
+				continue
+			}
+
+			introduced[to] = append(append(make([]string, 0, len(path)+1), path...), v.label())
+			queue = append(queue, to)
+
+			dependency := DependencyTransitive
+			if len(path) == 1 {
+				dependency = DependencyDirect
+			}
+
+			paths[to] = DependencyPath{Dependency: dependency, Depth: len(path), IntroductionPath: introduced[to]}
+		}
+	}
+
+	return paths
+}
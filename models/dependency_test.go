@@ -0,0 +1,103 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClassifyDependencies(t *testing.T) {
+	vertex := func(id, version string) DependencyVertex {
+		return DependencyVertex{ID: id, Name: id, Version: version, Root: id == "app"}
+	}
+
+	// edges returns the edges of the pairs of vertex IDs
+	edges := func(pairs ...string) []DependencyEdge {
+		e := []DependencyEdge{}
+		for i := 0; i+1 < len(pairs); i += 2 {
+			e = append(e, DependencyEdge{From: pairs[i], To: pairs[i+1]})
+		}
+
+		return e
+	}
+
+	tests := []struct {
+		name     string
+		vertices []DependencyVertex
+		edges    []DependencyEdge
+		depth    int
+		want     map[string]DependencyPath
+	}{
+		{
+			name:     "diamond",
+			vertices: []DependencyVertex{vertex("app", "1.0"), vertex("a", "1.0"), vertex("b", "2.0"), vertex("c", "3.0")},
+			edges:    edges("app", "a", "app", "b", "a", "c", "b", "c"),
+			depth:    100,
+			want: map[string]DependencyPath{
+				"a": {DependencyDirect, 1, []string{"app@1.0", "a@1.0"}},
+				"b": {DependencyDirect, 1, []string{"app@1.0", "b@2.0"}},
+				"c": {DependencyTransitive, 2, []string{"app@1.0", "a@1.0", "c@3.0"}},
+			},
+		},
+		{
+			name:     "diamond with a shortcut",
+			vertices: []DependencyVertex{vertex("app", "1.0"), vertex("a", "1.0"), vertex("b", "2.0"), vertex("c", "3.0")},
+			edges:    edges("app", "a", "a", "b", "b", "c", "app", "c"),
+			depth:    100,
+			want: map[string]DependencyPath{
+				"a": {DependencyDirect, 1, []string{"app@1.0", "a@1.0"}},
+				"b": {DependencyTransitive, 2, []string{"app@1.0", "a@1.0", "b@2.0"}},
+				"c": {DependencyDirect, 1, []string{"app@1.0", "c@3.0"}},
+			},
+		},
+		{
+			name:     "cycle",
+			vertices: []DependencyVertex{vertex("app", "1.0"), vertex("a", "1.0"), vertex("b", "")},
+			edges:    edges("app", "a", "a", "b", "b", "a", "b", "app"),
+			depth:    100,
+			want: map[string]DependencyPath{
+				"a": {DependencyDirect, 1, []string{"app@1.0", "a@1.0"}},
+				"b": {DependencyTransitive, 2, []string{"app@1.0", "a@1.0", "b"}},
+			},
+		},
+		{
+			name:     "component that cannot be reached",
+			vertices: []DependencyVertex{vertex("app", "1.0"), vertex("a", "1.0"), vertex("b", "1.0"), vertex("c", "1.0")},
+			edges:    edges("app", "a", "b", "c", "a", "missing"),
+			depth:    100,
+			want: map[string]DependencyPath{
+				"a": {DependencyDirect, 1, []string{"app@1.0", "a@1.0"}},
+				"b": {Dependency: DependencyUnknown},
+				"c": {Dependency: DependencyUnknown},
+			},
+		},
+		{
+			name:     "no root component",
+			vertices: []DependencyVertex{vertex("a", "1.0"), vertex("b", "1.0")},
+			edges:    edges("a", "b"),
+			depth:    100,
+			want: map[string]DependencyPath{
+				"a": {Dependency: DependencyUnknown},
+				"b": {Dependency: DependencyUnknown},
+			},
+		},
+		{
+			name:     "deeper than the depth",
+			vertices: []DependencyVertex{vertex("app", "1.0"), vertex("a", "1.0"), vertex("b", "1.0"), vertex("c", "1.0")},
+			edges:    edges("app", "a", "a", "b", "b", "c"),
+			depth:    2,
+			want: map[string]DependencyPath{
+				"a": {DependencyDirect, 1, []string{"app@1.0", "a@1.0"}},
+				"b": {DependencyTransitive, 2, []string{"app@1.0", "a@1.0", "b@1.0"}},
+				"c": {Dependency: DependencyUnknown},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyDependencies(tt.vertices, tt.edges, tt.depth); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ClassifyDependencies = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
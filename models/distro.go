@@ -0,0 +1,131 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"strings"
+)
+
+// distroCodenames maps the codenames of Debian and Ubuntu releases to their version
+//
+//nolint:gochecknoglobals // this is read-only and the nicest implementation
+var distroCodenames = map[string]string{
+	"jessie":   "8",
+	"stretch":  "9",
+	"buster":   "10",
+	"bullseye": "11",
+	"bookworm": "12",
+	"trixie":   "13",
+	"forky":    "14",
+	"trusty":   "14.04",
+	"xenial":   "16.04",
+	"bionic":   "18.04",
+	"focal":    "20.04",
+	"jammy":    "22.04",
+	"kinetic":  "22.10",
+	"lunar":    "23.04",
+	"mantic":   "23.10",
+	"noble":    "24.04",
+	"oracular": "24.10",
+	"plucky":   "25.04",
+}
+
+// distroPURLTypes defines the purl types of the packages of a distro, rather than of a language ecosystem
+//
+//nolint:gochecknoglobals // this is read-only and the nicest implementation
+var distroPURLTypes = map[string]bool{
+	"apk": true,
+	"deb": true,
+	"rpm": true,
+}
+
+// IsDistroEcosystem checks if the ecosystem is that of the packages of a distro (i.e. "Debian", "Alpine:v3.18" or
+// the "rpm:redhat" of a purl type without a mapping), which are the packages that come from the operating system
+// of an sbom, as opposed to those of a language ecosystem such as npm or PyPI
+func IsDistroEcosystem(ecosystem Ecosystem) bool {
+	name, _, _ := strings.Cut(string(ecosystem), ":")
+
+	if m, ok := getEcosystemMapping(Ecosystem(name)); ok {
+		return distroPURLTypes[m.Type]
+	}
+
+	return distroPURLTypes[name]
+}
+
+// isDistroRelease checks if the string is the number (i.e. "11" or "v3.18") or codename of a release
+func isDistroRelease(str string) bool {
+	if _, ok := distroCodenames[str]; ok {
+		return true
+	}
+
+	str = strings.TrimPrefix(str, "v")
+
+	return str != "" && str[0] >= '0' && str[0] <= '9'
+}
+
+// DistroRelease normalizes the release of a distro, as found in the "distro" purl qualifier
+// (i.e. "debian-11.6", "bookworm", "ubuntu-22.04" or "alpine-3.18.4") or in the ecosystem
+// of an advisory (i.e. "Debian:11", "Ubuntu:Pro:22.04:LTS" or "Alpine:v3.18"), into the
+// form used by the advisories for that ecosystem, so that the two can be compared
+func DistroRelease(ecosystem Ecosystem, distro string) string {
+	release, fallback := "", ""
+
+	for _, part := range strings.Split(strings.ToLower(strings.TrimSpace(distro)), ":") {
+		// skip the ecosystem and any qualifiers of the release, such as "Pro", "FIPS-updates" or "LTS"
+		if part == strings.ToLower(string(ecosystem)) || part == "pro" || part == "lts" || part == "" {
+			continue
+		}
+
+		if fallback == "" {
+			fallback = part
+		}
+
+		// the release may follow the name of the distro, i.e. "debian-11.6" or "rocky-9.3"
+		if i := strings.LastIndex(part, "-"); i != -1 && isDistroRelease(part[i+1:]) {
+			part = part[i+1:]
+		}
+
+		if isDistroRelease(part) {
+			release = part
+
+			break
+		}
+	}
+
+	if release == "" {
+		release = strings.TrimPrefix(fallback, strings.ToLower(string(ecosystem))+"-")
+	}
+
+	if version, ok := distroCodenames[release]; ok {
+		release = version
+	}
+
+	components := strings.Split(strings.TrimPrefix(release, "v"), ".")
+
+	//nolint:exhaustive // Using strings to specify ecosystem instead of lockfile types
+	switch ecosystem {
+	case EcosystemDebian, EcosystemRockyLinux, EcosystemAlmaLinux:
+		// Debian, Rocky Linux and AlmaLinux advisories are for the major release only, i.e. "Debian:11" or "Rocky Linux:9"
+		return components[0]
+	case EcosystemUbuntu:
+		return strings.Join(components[:min(len(components), 2)], ".")
+	case EcosystemAlpine:
+		// Alpine advisories are for the branch, i.e. "Alpine:v3.18"
+		return "v" + strings.Join(components[:min(len(components), 2)], ".")
+	}
+
+	return release
+}
+
+// affectsDistro checks if the release of the affected ecosystem (i.e. "11" for "Debian:11") is for the
+// same release as the distro of the package, where advisories without a release apply to every release
+// and packages without a distro are matched regardless of the release
+func affectsDistro(affected Affected, pkg PackageDetails) bool {
+	_, release, hasRelease := strings.Cut(string(affected.Package.Ecosystem), ":")
+
+	if !hasRelease || release == "" || pkg.Distro == "" {
+		return true
+	}
+
+	return DistroRelease(pkg.Ecosystem, release) == DistroRelease(pkg.Ecosystem, pkg.Distro)
+}
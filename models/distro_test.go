@@ -0,0 +1,94 @@
+package models
+
+import "testing"
+
+func TestDistroRelease(t *testing.T) {
+	tests := []struct {
+		ecosystem Ecosystem
+		distro    string
+		want      string
+	}{
+		{EcosystemDebian, "debian-11.6", "11"},
+		{EcosystemDebian, "debian-12", "12"},
+		{EcosystemDebian, "bookworm", "12"},
+		{EcosystemDebian, "Debian:11", "11"},
+		{EcosystemDebian, "debian-trixie", "13"},
+		{EcosystemUbuntu, "ubuntu-22.04", "22.04"},
+		{EcosystemUbuntu, "ubuntu-22.04.3", "22.04"},
+		{EcosystemUbuntu, "jammy", "22.04"},
+		{EcosystemUbuntu, "Ubuntu:22.04:LTS", "22.04"},
+		{EcosystemUbuntu, "Ubuntu:Pro:18.04:LTS", "18.04"},
+		{EcosystemUbuntu, "Ubuntu:Pro:FIPS-updates:22.04:LTS", "22.04"},
+		{EcosystemAlpine, "alpine-3.18.4", "v3.18"},
+		{EcosystemAlpine, "Alpine:v3.18", "v3.18"},
+		{EcosystemAlpine, "3.19", "v3.19"},
+		{EcosystemRockyLinux, "Rocky Linux:9", "9"},
+		{EcosystemRockyLinux, "rocky-9.3", "9"},
+		{EcosystemAlmaLinux, "almalinux-8.9", "8"},
+		{EcosystemPhotonOS, "Photon OS:5.0", "5.0"},
+		{EcosystemDebian, "debian-sid", "sid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.ecosystem)+" "+tt.distro, func(t *testing.T) {
+			if got := DistroRelease(tt.ecosystem, tt.distro); got != tt.want {
+				t.Errorf("DistroRelease(%s, %q) = %q, want %q", tt.ecosystem, tt.distro, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAffectsDistro(t *testing.T) {
+	tests := []struct {
+		affected Ecosystem
+		pkg      PackageDetails
+		want     bool
+	}{
+		{"Debian:11", PackageDetails{Ecosystem: EcosystemDebian, Distro: "debian-11.6"}, true},
+		{"Debian:11", PackageDetails{Ecosystem: EcosystemDebian, Distro: "bookworm"}, false},
+		{"Debian", PackageDetails{Ecosystem: EcosystemDebian, Distro: "bookworm"}, true},
+		{"Debian:12", PackageDetails{Ecosystem: EcosystemDebian}, true},
+		{"Ubuntu:Pro:22.04:LTS", PackageDetails{Ecosystem: EcosystemUbuntu, Distro: "jammy"}, true},
+		{"Ubuntu:20.04:LTS", PackageDetails{Ecosystem: EcosystemUbuntu, Distro: "ubuntu-22.04"}, false},
+		{"Alpine:v3.18", PackageDetails{Ecosystem: EcosystemAlpine, Distro: "alpine-3.18.4"}, true},
+		{"Alpine:v3.19", PackageDetails{Ecosystem: EcosystemAlpine, Distro: "alpine-3.18.4"}, false},
+		{"Ubuntu:Pro:FIPS-updates:22.04:LTS", PackageDetails{Ecosystem: EcosystemUbuntu, Distro: "ubuntu-22.04"}, true},
+		{"Rocky Linux:9", PackageDetails{Ecosystem: EcosystemRockyLinux, Distro: "rocky-9.3"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.affected)+" "+tt.pkg.Distro, func(t *testing.T) {
+			affected := Affected{Package: Package{Ecosystem: tt.affected}}
+
+			if got := affectsDistro(affected, tt.pkg); got != tt.want {
+				t.Errorf("affectsDistro(%s, %q) = %v, want %v", tt.affected, tt.pkg.Distro, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsDistroEcosystem(t *testing.T) {
+	tests := []struct {
+		ecosystem Ecosystem
+		want      bool
+	}{
+		{EcosystemDebian, true},
+		{EcosystemUbuntu, true},
+		{EcosystemAlpine, true},
+		{EcosystemRockyLinux, true},
+		{"Debian:12", true},
+		{"Alpine:v3.18", true},
+		{"rpm:redhat", true},
+		{EcosystemNPM, false},
+		{EcosystemPyPI, false},
+		{EcosystemGo, false},
+		{EcosystemLinux, false},
+		{"cocoapods:", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsDistroEcosystem(tt.ecosystem); got != tt.want {
+			t.Errorf("IsDistroEcosystem(%s) = %v, want %v", tt.ecosystem, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"time"
+)
+
+// VulnerabilityFilter defines which of the stored vulnerabilities are reported, where by default
+// withdrawn vulnerabilities are excluded and every vulnerability is considered to be known
+type VulnerabilityFilter struct {
+	// IncludeWithdrawn reports vulnerabilities even if they have been withdrawn
+	IncludeWithdrawn bool `json:"include_withdrawn,omitempty"`
+	// AsOf only reports the vulnerabilities that had been published by that time, and ignores
+	// any withdrawal after it, so that what was known at that time can be reproduced
+	AsOf time.Time `json:"asof,omitempty"`
+}
+
+// IsWithdrawn checks if the vulnerability had been withdrawn by the given time,
+// or at all if the time is zero
+func (v Vulnerability) IsWithdrawn(asof time.Time) bool {
+	if v.Withdrawn.IsZero() {
+		return false
+	}
+
+	return asof.IsZero() || !v.Withdrawn.After(asof)
+}
+
+// IsPublished checks if the vulnerability had been published by the given time, using
+// the time it was modified if it does not have a published time, or true if the time is zero
+func (v Vulnerability) IsPublished(asof time.Time) bool {
+	published := v.Published
+	if published.IsZero() {
+		published = v.Modified
+	}
+
+	return asof.IsZero() || published.IsZero() || !published.After(asof)
+}
+
+// Includes checks if the vulnerability should be reported
+func (f VulnerabilityFilter) Includes(v Vulnerability) bool {
+	if !v.IsPublished(f.AsOf) {
+		return false
+	}
+
+	return f.IncludeWithdrawn || !v.IsWithdrawn(f.AsOf)
+}
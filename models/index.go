@@ -0,0 +1,288 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// parsedEventSize is the approximate memory used by each parsed event, in addition to the vulnerability itself
+const parsedEventSize = 96
+
+// nameKey returns the key of an affected package name, which cannot be mistaken for a purl
+func nameKey(name string) string {
+	return "name:" + name
+}
+
+// IndexedVulnerability defines a vulnerability along with the events of its ranges,
+// which are parsed and sorted for the ecosystem of each affected entry ahead of time
+type IndexedVulnerability struct {
+	Vulnerability
+	ecosystems []Ecosystem       // ecosystem the events of each affected entry were parsed for
+	events     [][][]parsedEvent // sorted events of each range of each affected entry, or nil if they failed to parse
+	size       int64             // approximate memory used by the vulnerability and its events
+}
+
+// NewIndexedVulnerability parses the events of the vulnerability, leaving those that fail to
+// parse to be reported as indeterminate when the vulnerability is evaluated
+func NewIndexedVulnerability(v Vulnerability) *IndexedVulnerability {
+	iv := &IndexedVulnerability{
+		Vulnerability: v,
+		ecosystems:    make([]Ecosystem, len(v.Affected)),
+		events:        make([][][]parsedEvent, len(v.Affected)),
+	}
+
+	if data, err := json.Marshal(v); err == nil {
+		iv.size = int64(len(data))
+	}
+
+	for i, affected := range v.Affected {
+		ecosystem, _, _ := strings.Cut(string(affected.Package.Ecosystem), ":")
+
+		iv.ecosystems[i] = Ecosystem(ecosystem)
+		iv.events[i] = make([][]parsedEvent, len(affected.Ranges))
+
+		for j, r := range affected.Ranges {
+			if r.Type != RangeEcosystem && r.Type != RangeSemVer {
+				continue
+			}
+
+			if events, err := sortEvents(r.Events, Ecosystem(ecosystem)); err == nil {
+				iv.events[i][j] = events
+				iv.size += int64(len(events)) * parsedEventSize
+			}
+		}
+	}
+
+	return iv
+}
+
+// IsAffected checks the package for the vulnerability the same way as IsAffected, using the
+// events that were parsed ahead of time when the package is compared in the same ecosystem
+func (iv *IndexedVulnerability) IsAffected(pkg PackageDetails) (bool, error) {
+	return isAffected(iv.Vulnerability, pkg, func(i int) func(int) []parsedEvent {
+		if i >= len(iv.events) || iv.ecosystems[i] != pkg.CompareAs {
+			return noParsedEvents
+		}
+
+		return func(j int) []parsedEvent {
+			return iv.events[i][j]
+		}
+	})
+}
+
+// IndexStats defines the size and staleness of a VulnerabilityIndex
+type IndexStats struct {
+	Vulnerabilities int       `json:"vulnerabilities"`
+	Packages        int       `json:"packages"` // number of purls and names that vulnerabilities are keyed by
+	Bytes           int64     `json:"bytes"`
+	Revision        string    `json:"revision"`
+	Refreshed       time.Time `json:"refreshed"`
+	Ready           bool      `json:"ready"`
+}
+
+// VulnerabilityIndex defines the vulnerabilities held in memory, so that the vulnerabilities of a
+// package can be found without querying the database.
+//
+// The vulnerabilities are keyed the same way as in the vulnGraph, which is by the purls of their
+// affected packages (see Vulnerability.AffectedPURLs), along with the names of their affected
+// packages for the packages that do not have a valid purl.
+type VulnerabilityIndex struct {
+	mu        sync.RWMutex
+	byKey     map[string][]*IndexedVulnerability
+	byID      map[string]*IndexedVulnerability
+	bytes     int64
+	revision  string
+	refreshed time.Time
+	ready     bool
+}
+
+// NewVulnerabilityIndex returns an empty VulnerabilityIndex, which is not ready until its revision is first set
+func NewVulnerabilityIndex() *VulnerabilityIndex {
+	return &VulnerabilityIndex{
+		byKey: make(map[string][]*IndexedVulnerability),
+		byID:  make(map[string]*IndexedVulnerability),
+	}
+}
+
+// keys returns the purls and names of the affected packages of the vulnerability
+func (iv *IndexedVulnerability) keys() []string {
+	keys := iv.AffectedPURLs()
+	seen := make(map[string]bool)
+
+	for _, affected := range iv.Affected {
+		if affected.Package.Name == "" || seen[affected.Package.Name] {
+			continue
+		}
+
+		seen[affected.Package.Name] = true
+		keys = append(keys, nameKey(affected.Package.Name))
+	}
+
+	return keys
+}
+
+// Add adds the vulnerability to the index, replacing the vulnerability with the same id if there is one
+func (idx *VulnerabilityIndex) Add(v Vulnerability) {
+	iv := NewIndexedVulnerability(v)
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(v.ID)
+
+	for _, key := range iv.keys() {
+		idx.byKey[key] = append(idx.byKey[key], iv)
+	}
+
+	idx.byID[v.ID] = iv
+	idx.bytes += iv.size
+}
+
+// Remove removes the vulnerability with the id from the index
+func (idx *VulnerabilityIndex) Remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(id)
+}
+
+func (idx *VulnerabilityIndex) remove(id string) {
+	old, ok := idx.byID[id]
+	if !ok {
+		return
+	}
+
+	for _, key := range old.keys() {
+		entries := idx.byKey[key]
+
+		for i, iv := range entries {
+			if iv == old {
+				entries = append(entries[:i], entries[i+1:]...)
+
+				break
+			}
+		}
+
+		if len(entries) == 0 {
+			delete(idx.byKey, key)
+		} else {
+			idx.byKey[key] = entries
+		}
+	}
+
+	delete(idx.byID, id)
+	idx.bytes -= old.size
+}
+
+// Retain removes the vulnerabilities whose ids are not in the set, which are those that have been
+// deleted since the index was loaded, returning the number of vulnerabilities that were removed
+func (idx *VulnerabilityIndex) Retain(ids map[string]bool) int {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	removed := 0
+
+	for id := range idx.byID {
+		if !ids[id] {
+			idx.remove(id)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// Lookup returns the vulnerabilities linked to any of the purls, which are expected without versions,
+// or with an affected package of any of the names, the same way as they are found in the vulnGraph
+func (idx *VulnerabilityIndex) Lookup(purls []string, names []string) []*IndexedVulnerability {
+	keys := append([]string{}, purls...)
+
+	for _, name := range names {
+		keys = append(keys, nameKey(name))
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	// the entries are copied, as they may be changed by a refresh while the caller is using them
+	entries := []*IndexedVulnerability{}
+	seen := make(map[*IndexedVulnerability]bool)
+
+	for _, key := range keys {
+		for _, iv := range idx.byKey[key] {
+			if !seen[iv] {
+				seen[iv] = true
+				entries = append(entries, iv)
+			}
+		}
+	}
+
+	return entries
+}
+
+// Changed returns the ids of the vulnerabilities that are not in the index, or whose modified time differs
+// from that of the vulnerability in the index, out of the modified times of the vulnerabilities by id.
+//
+// The modified time of each vulnerability is compared rather than only loading those modified since the
+// latest one in the index, as an import can add vulnerabilities that were modified long before, such as
+// those of a newly imported ecosystem.
+func (idx *VulnerabilityIndex) Changed(modified map[string]time.Time) []string {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	changed := []string{}
+
+	for id, m := range modified {
+		if iv, ok := idx.byID[id]; !ok || !iv.Modified.Equal(m) {
+			changed = append(changed, id)
+		}
+	}
+
+	return changed
+}
+
+// SetRevision records the revision of the vulnerabilities that the index was refreshed to, which makes it ready
+func (idx *VulnerabilityIndex) SetRevision(revision string, refreshed time.Time) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.revision = revision
+	idx.refreshed = refreshed
+	idx.ready = true
+}
+
+// Revision returns the revision of the vulnerabilities that the index was last refreshed to
+func (idx *VulnerabilityIndex) Revision() string {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return idx.revision
+}
+
+// Ready checks if the index has been loaded, so that it can be used instead of the database
+func (idx *VulnerabilityIndex) Ready() bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return idx.ready
+}
+
+// Stats returns the size and staleness of the index, where the bytes are an approximation
+// of the memory used by the vulnerabilities and their parsed events
+func (idx *VulnerabilityIndex) Stats() IndexStats {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return IndexStats{
+		Vulnerabilities: len(idx.byID),
+		Packages:        len(idx.byKey),
+		Bytes:           idx.bytes,
+		Revision:        idx.revision,
+		Refreshed:       idx.refreshed,
+		Ready:           idx.ready,
+	}
+}
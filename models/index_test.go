@@ -0,0 +1,214 @@
+package models
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// lookupIDs returns the sorted ids of the vulnerabilities the index finds for the purls and names
+func lookupIDs(idx *VulnerabilityIndex, purls []string, names []string) []string {
+	ids := []string{}
+
+	for _, iv := range idx.Lookup(purls, names) {
+		ids = append(ids, iv.ID)
+	}
+
+	slices.Sort(ids)
+
+	return ids
+}
+
+// graphIDs returns the sorted ids of the vulnerabilities that the vulnGraph query of GetCVEs finds for the
+// purls and names, which follows the purl2vulns edges made from Vulnerability.AffectedPURLs and matches
+// the names against the affected package names as they are
+func graphIDs(vulns []Vulnerability, purls []string, names []string) []string {
+	ids := []string{}
+
+	for _, v := range vulns {
+		linked := false
+
+		for _, purl := range v.AffectedPURLs() {
+			linked = linked || slices.Contains(purls, purl)
+		}
+
+		for _, affected := range v.Affected {
+			linked = linked || slices.Contains(names, affected.Package.Name)
+		}
+
+		if linked {
+			ids = append(ids, v.ID)
+		}
+	}
+
+	slices.Sort(ids)
+
+	return ids
+}
+
+// indexFixture returns advisories of packages that are keyed differently by name and purl
+func indexFixture() []Vulnerability {
+	withPurl := ecosystemVulnerability("GHSA-purl", "npm", "@babel/traverse", "0", "7.23.2")
+	withPurl.Affected[0].Package.Purl = "pkg:npm/%40babel/traverse"
+
+	return []Vulnerability{
+		ecosystemVulnerability("GHSA-lodash", "npm", "lodash", "0", "4.17.21"),
+		ecosystemVulnerability("PYSEC-django", "PyPI", "Django", "4.0", "4.2.1"),
+		ecosystemVulnerability("DSA-openssl", "Debian:12", "openssl", "0", "3.0.11-1~deb12u1"),
+		ecosystemVulnerability("GO-net", "Go", "golang.org/x/net", "0", "0.17.0"),
+		ecosystemVulnerability("OSV-unknown", "Unknown", "widget", "0", "1.0.0"),
+		withPurl,
+	}
+}
+
+func TestVulnerabilityIndexLookupMatchesGraph(t *testing.T) {
+	vulns := indexFixture()
+	idx := NewVulnerabilityIndex()
+
+	for _, v := range vulns {
+		idx.Add(v)
+	}
+
+	tests := []struct {
+		name  string
+		purls []string
+		names []string
+		want  []string
+	}{
+		{"npm purl", []string{"pkg:npm/lodash"}, nil, []string{"GHSA-lodash"}},
+		{"scoped npm purl", []string{"pkg:npm/%40babel/traverse"}, nil, []string{"GHSA-purl"}},
+		{"pypi purl of the normalized name", []string{"pkg:pypi/django"}, nil, []string{"PYSEC-django"}},
+		{"distro purl without the release", []string{"pkg:deb/debian/openssl"}, nil, []string{"DSA-openssl"}},
+		{"go purl", []string{"pkg:golang/golang.org/x/net"}, nil, []string{"GO-net"}},
+		{"several purls", []string{"pkg:npm/lodash", "pkg:golang/golang.org/x/net"}, nil, []string{"GHSA-lodash", "GO-net"}},
+		{"name of a package without a purl", nil, []string{"widget"}, []string{"OSV-unknown"}},
+		{"names are matched as they are", nil, []string{"django"}, []string{}},
+		{"purl of another ecosystem", []string{"pkg:pypi/lodash"}, nil, []string{}},
+		{"purl with a version", []string{"pkg:npm/lodash@4.17.20"}, nil, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := graphIDs(vulns, tt.purls, tt.names)
+
+			if !slices.Equal(want, tt.want) {
+				t.Fatalf("the vulnGraph finds %v, the test expects %v", want, tt.want)
+			}
+
+			if got := lookupIDs(idx, tt.purls, tt.names); !slices.Equal(got, want) {
+				t.Errorf("Lookup(%v, %v) = %v, the vulnGraph finds %v", tt.purls, tt.names, got, want)
+			}
+		})
+	}
+}
+
+func TestVulnerabilityIndexRetain(t *testing.T) {
+	idx := NewVulnerabilityIndex()
+
+	for _, v := range indexFixture() {
+		idx.Add(v)
+	}
+
+	idx.SetRevision("1", time.Now())
+
+	lodash := []string{"pkg:npm/lodash"}
+	pkg := PackageDetails{Name: "lodash", Version: "4.17.20", Ecosystem: "npm", CompareAs: "npm"}
+
+	matched := idx.Lookup(lodash, nil)
+	if len(matched) != 1 {
+		t.Fatalf("Lookup(%v) = %d vulnerabilities, want 1", lodash, len(matched))
+	}
+
+	if affected, err := matched[0].IsAffected(pkg); err != nil || !affected {
+		t.Fatalf("IsAffected = %v, %v, want true", affected, err)
+	}
+
+	before := idx.Stats()
+
+	// GHSA-lodash has been deleted from the database
+	keep := make(map[string]bool)
+
+	for _, v := range indexFixture() {
+		if v.ID != "GHSA-lodash" {
+			keep[v.ID] = true
+		}
+	}
+
+	if removed := idx.Retain(keep); removed != 1 {
+		t.Errorf("Retain removed %d vulnerabilities, want 1", removed)
+	}
+
+	if got := lookupIDs(idx, lodash, []string{"lodash"}); len(got) != 0 {
+		t.Errorf("Lookup(%v) = %v after it was removed, want none", lodash, got)
+	}
+
+	after := idx.Stats()
+
+	if after.Vulnerabilities != before.Vulnerabilities-1 || after.Bytes >= before.Bytes || after.Packages >= before.Packages {
+		t.Errorf("Stats = %+v after removing a vulnerability, was %+v", after, before)
+	}
+
+	if got := lookupIDs(idx, []string{"pkg:golang/golang.org/x/net"}, nil); !slices.Equal(got, []string{"GO-net"}) {
+		t.Errorf("Lookup of a retained vulnerability = %v, want [GO-net]", got)
+	}
+
+	if removed := idx.Retain(keep); removed != 0 {
+		t.Errorf("Retain removed %d vulnerabilities again, want 0", removed)
+	}
+}
+
+func TestVulnerabilityIndexAddReplaces(t *testing.T) {
+	idx := NewVulnerabilityIndex()
+
+	idx.Add(ecosystemVulnerability("GHSA-moved", "npm", "lodash", "0", "4.17.21"))
+	idx.Add(ecosystemVulnerability("GHSA-moved", "npm", "underscore", "0", "1.13.6"))
+
+	if got := lookupIDs(idx, []string{"pkg:npm/lodash"}, nil); len(got) != 0 {
+		t.Errorf("Lookup of the replaced package = %v, want none", got)
+	}
+
+	if got := lookupIDs(idx, []string{"pkg:npm/underscore"}, nil); !slices.Equal(got, []string{"GHSA-moved"}) {
+		t.Errorf("Lookup of the new package = %v, want [GHSA-moved]", got)
+	}
+
+	if stats := idx.Stats(); stats.Vulnerabilities != 1 {
+		t.Errorf("Stats().Vulnerabilities = %d, want 1", stats.Vulnerabilities)
+	}
+}
+
+func TestVulnerabilityIndexChanged(t *testing.T) {
+	idx := NewVulnerabilityIndex()
+
+	newer := ecosystemVulnerability("GHSA-newer", "npm", "lodash", "0", "4.17.21")
+	newer.Modified = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	idx.Add(newer)
+	idx.SetRevision("1", time.Now())
+
+	// an import of another ecosystem adds an advisory that was modified long before the newest one in the index
+	older := ecosystemVulnerability("PYSEC-older", "PyPI", "django", "0", "3.2.19")
+	older.Modified = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	modified := map[string]time.Time{newer.ID: newer.Modified, older.ID: older.Modified}
+
+	if changed := idx.Changed(modified); !slices.Equal(changed, []string{older.ID}) {
+		t.Fatalf("Changed = %v, want [%s]", changed, older.ID)
+	}
+
+	idx.Add(older)
+
+	if got := lookupIDs(idx, []string{"pkg:pypi/django"}, nil); !slices.Equal(got, []string{older.ID}) {
+		t.Errorf("Lookup of the older advisory = %v, want [%s]", got, older.ID)
+	}
+
+	if changed := idx.Changed(modified); len(changed) != 0 {
+		t.Errorf("Changed = %v once the advisories are loaded, want none", changed)
+	}
+
+	// an older advisory that is upserted again is loaded even though it is still older than the newest one
+	modified[older.ID] = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if changed := idx.Changed(modified); !slices.Equal(changed, []string{older.ID}) {
+		t.Errorf("Changed = %v after %s was modified, want [%s]", changed, older.ID, older.ID)
+	}
+}
@@ -0,0 +1,104 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"strings"
+)
+
+// caseInsensitiveGoHosts defines the code hosts whose paths are not case sensitive,
+// so that "github.com/Sirupsen/logrus" and "github.com/sirupsen/logrus" are the same module
+//
+//nolint:gochecknoglobals // this is read-only and the nicest implementation
+var caseInsensitiveGoHosts = []string{"github.com/", "gitlab.com/", "bitbucket.org/"}
+
+// NormalizeName returns the canonical form of a package name in the ecosystem, so that
+// names which the ecosystem treats as the same package compare equal, i.e. "Zope.Interface"
+// and "zope-interface" for PyPI (PEP 503), or "Newtonsoft.Json" and "newtonsoft.json" for NuGet
+func NormalizeName(ecosystem Ecosystem, name string) string {
+	name = strings.TrimSpace(name)
+
+	// ignore any release suffix such as "Debian:11"
+	base, _, _ := strings.Cut(string(ecosystem), ":")
+
+	//nolint:exhaustive // Using strings to specify ecosystem instead of lockfile types
+	switch Ecosystem(base) {
+	case EcosystemPyPI:
+		// See https://peps.python.org/pep-0503/#normalized-names
+		return MustCompile(`[-_.]+`).ReplaceAllString(strings.ToLower(name), "-")
+	case EcosystemNuGet, EcosystemPackagist:
+		return strings.ToLower(name)
+	case EcosystemGo:
+		return normalizeGoModulePath(name)
+	}
+
+	return name
+}
+
+// normalizeGoModulePath removes the "!" case encoding used by the module cache and
+// proxy (i.e. "github.com/!azure/azure-sdk-for-go") along with any trailing slash,
+// and lowercases the paths of hosts that are not case sensitive
+//
+// See https://go.dev/ref/mod#goproxy-protocol
+func normalizeGoModulePath(path string) string {
+	if strings.Contains(path, "!") {
+		var sb strings.Builder
+
+		for i := 0; i < len(path); i++ {
+			if path[i] == '!' && i+1 < len(path) {
+				i++
+				sb.WriteString(strings.ToUpper(path[i : i+1]))
+
+				continue
+			}
+
+			sb.WriteByte(path[i])
+		}
+
+		path = sb.String()
+	}
+
+	path = strings.TrimSuffix(path, "/")
+
+	for _, host := range caseInsensitiveGoHosts {
+		if strings.HasPrefix(strings.ToLower(path), host) {
+			return strings.ToLower(path)
+		}
+	}
+
+	return path
+}
+
+// goMajorVersionSuffix returns the "/vN" suffix that a module at the version must have in
+// its path, which is missing from the names some SBOM tools give modules at v2 or later
+//
+// See https://go.dev/ref/mod#major-version-suffixes
+func goMajorVersionSuffix(path string, version string) string {
+	// gopkg.in has its own ".vN" suffix and +incompatible versions have no suffix
+	if strings.HasPrefix(path, "gopkg.in/") || strings.HasSuffix(version, "+incompatible") {
+		return ""
+	}
+
+	major := MustCompile(`^v?(\d+)\.`).FindStringSubmatch(version)
+	if len(major) == 0 || major[1] == "0" || major[1] == "1" {
+		return ""
+	}
+
+	if MustCompile(`/v\d+$`).MatchString(path) {
+		return ""
+	}
+
+	return "/v" + major[1]
+}
+
+// NormalizePackage returns the package with its name normalized for the ecosystem,
+// including the major version suffix of Go modules at v2 or later that are missing it
+func NormalizePackage(pkg PackageDetails) PackageDetails {
+	pkg.Name = NormalizeName(pkg.Ecosystem, pkg.Name)
+
+	if pkg.Ecosystem == EcosystemGo && pkg.Name != "stdlib" && pkg.Name != "toolchain" {
+		pkg.Name += goMajorVersionSuffix(pkg.Name, pkg.Version)
+	}
+
+	return pkg
+}
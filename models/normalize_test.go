@@ -0,0 +1,74 @@
+package models
+
+import "testing"
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		ecosystem Ecosystem
+		name      string
+		want      string
+	}{
+		// PyPI names are compared as described by PEP 503
+		{EcosystemPyPI, "Django", "django"},
+		{EcosystemPyPI, "Zope.Interface", "zope-interface"},
+		{EcosystemPyPI, "zope_interface", "zope-interface"},
+		{EcosystemPyPI, "Foo.-_Bar", "foo-bar"},
+		{EcosystemPyPI, " requests ", "requests"},
+
+		// NuGet and Packagist names are not case sensitive
+		{EcosystemNuGet, "Newtonsoft.Json", "newtonsoft.json"},
+		{EcosystemPackagist, "Symfony/Http-Kernel", "symfony/http-kernel"},
+
+		// Go module paths are decoded and lowercased for the hosts that are not case sensitive
+		{EcosystemGo, "github.com/!azure/azure-sdk-for-go", "github.com/azure/azure-sdk-for-go"},
+		{EcosystemGo, "github.com/Sirupsen/logrus", "github.com/sirupsen/logrus"},
+		{EcosystemGo, "GitLab.com/Group/Project", "gitlab.com/group/project"},
+		{EcosystemGo, "example.com/!burnt!sushi/toml", "example.com/BurntSushi/toml"},
+		{EcosystemGo, "example.com/Foo/bar/", "example.com/Foo/bar"},
+		{EcosystemGo, "golang.org/x/net", "golang.org/x/net"},
+		{EcosystemGo, "example.com/trailing!", "example.com/trailing!"},
+
+		// the names of other ecosystems are case sensitive or already canonical
+		{EcosystemNPM, "@Angular/Core", "@Angular/Core"},
+		{EcosystemMaven, "org.apache.logging.log4j:log4j-core", "org.apache.logging.log4j:log4j-core"},
+		{EcosystemRubyGems, "Rails", "Rails"},
+		{"Debian:12", "libssl3", "libssl3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.ecosystem)+" "+tt.name, func(t *testing.T) {
+			if got := NormalizeName(tt.ecosystem, tt.name); got != tt.want {
+				t.Errorf("NormalizeName(%s, %q) = %q, want %q", tt.ecosystem, tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizePackage(t *testing.T) {
+	tests := []struct {
+		pkg  PackageDetails
+		want string
+	}{
+		{PackageDetails{Name: "github.com/go-chi/chi", Version: "v5.0.10", Ecosystem: EcosystemGo}, "github.com/go-chi/chi/v5"},
+		{PackageDetails{Name: "github.com/go-chi/chi", Version: "5.0.10", Ecosystem: EcosystemGo}, "github.com/go-chi/chi/v5"},
+		{PackageDetails{Name: "github.com/go-chi/chi/v5", Version: "v5.0.10", Ecosystem: EcosystemGo}, "github.com/go-chi/chi/v5"},
+		{PackageDetails{Name: "github.com/!sirupsen/logrus", Version: "v1.9.3", Ecosystem: EcosystemGo}, "github.com/sirupsen/logrus"},
+		{PackageDetails{Name: "github.com/docker/docker", Version: "v24.0.7+incompatible", Ecosystem: EcosystemGo}, "github.com/docker/docker"},
+		{PackageDetails{Name: "gopkg.in/yaml.v3", Version: "v3.0.1", Ecosystem: EcosystemGo}, "gopkg.in/yaml.v3"},
+		{PackageDetails{Name: "golang.org/x/net", Version: "v0.17.0", Ecosystem: EcosystemGo}, "golang.org/x/net"},
+		{PackageDetails{Name: "stdlib", Version: "1.21.3", Ecosystem: EcosystemGo}, "stdlib"},
+		{PackageDetails{Name: "github.com/go-chi/chi", Ecosystem: EcosystemGo}, "github.com/go-chi/chi"},
+		{PackageDetails{Name: "Django", Version: "4.2.1", Ecosystem: EcosystemPyPI}, "django"},
+		{PackageDetails{Name: "lodash", Version: "4.17.20", Ecosystem: EcosystemNPM}, "lodash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pkg.Name+"@"+tt.pkg.Version, func(t *testing.T) {
+			got := NormalizePackage(tt.pkg)
+
+			if got.Name != tt.want || got.Version != tt.pkg.Version || got.Ecosystem != tt.pkg.Ecosystem {
+				t.Errorf("NormalizePackage(%+v) = %+v, want the name %q", tt.pkg, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,419 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NVD node and configuration operators
+const (
+	NVDOperatorAnd = "AND"
+	NVDOperatorOr  = "OR"
+)
+
+// NVDFeed defines a NVD CVE JSON 2.0 feed, i.e. nvdcve-2.0-2024.json
+//
+// See https://csrc.nist.gov/schema/nvd/api/2.0/cve_api_json_2.0.schema
+type NVDFeed struct {
+	Vulnerabilities []struct {
+		CVE NVDCVE `json:"cve"`
+	} `json:"vulnerabilities"`
+}
+
+// NVDCVE defines a single CVE of a NVD feed
+type NVDCVE struct {
+	ID           string `json:"id"`
+	Published    string `json:"published"`
+	LastModified string `json:"lastModified"`
+	VulnStatus   string `json:"vulnStatus"`
+	Descriptions []struct {
+		Lang  string `json:"lang"`
+		Value string `json:"value"`
+	} `json:"descriptions"`
+	Metrics        map[string][]NVDMetric `json:"metrics"`
+	Configurations []NVDConfiguration     `json:"configurations"`
+}
+
+// NVDMetric defines a CVSS score of a CVE
+type NVDMetric struct {
+	Source   string `json:"source"`
+	Type     string `json:"type"`
+	CVSSData struct {
+		VectorString string `json:"vectorString"`
+	} `json:"cvssData"`
+}
+
+// NVDConfiguration defines the nodes that must match for a CVE to apply
+type NVDConfiguration struct {
+	Operator string    `json:"operator,omitempty"`
+	Nodes    []NVDNode `json:"nodes"`
+}
+
+// NVDNode defines a list of CPE match criteria that are combined by the operator
+type NVDNode struct {
+	Operator string        `json:"operator"`
+	Negate   bool          `json:"negate,omitempty"`
+	CPEMatch []NVDCPEMatch `json:"cpeMatch"`
+}
+
+// NVDCPEMatch defines a CPE match criteria, which can be a range of versions
+type NVDCPEMatch struct {
+	Vulnerable            bool   `json:"vulnerable"`
+	Criteria              string `json:"criteria"`
+	VersionStartIncluding string `json:"versionStartIncluding,omitempty"`
+	VersionStartExcluding string `json:"versionStartExcluding,omitempty"`
+	VersionEndIncluding   string `json:"versionEndIncluding,omitempty"`
+	VersionEndExcluding   string `json:"versionEndExcluding,omitempty"`
+}
+
+// CPEMatch defines a CVE that a component was matched to by its CPE
+type CPEMatch struct {
+	Vulnerability Vulnerability
+	Criteria      string
+	FixedIn       string
+}
+
+// nvdMetricTypes maps the metrics of a CVE to the severity type of their vectors
+//
+//nolint:gochecknoglobals // this is read-only and the nicest implementation
+var nvdMetricTypes = map[string]SeverityType{
+	"cvssMetricV2":  SeverityCVSSV2,
+	"cvssMetricV30": SeverityCVSSV3,
+	"cvssMetricV31": SeverityCVSSV3,
+	"cvssMetricV40": SeverityCVSSV4,
+}
+
+// parseNVDTime parses the times of the NVD, which are UTC without a time zone
+func parseNVDTime(str string) time.Time {
+	for _, layout := range []string{"2006-01-02T15:04:05.999", time.RFC3339Nano} {
+		if t, err := time.Parse(layout, str); err == nil {
+			return t.UTC()
+		}
+	}
+
+	return time.Time{}
+}
+
+// ToVulnerability converts the CVE into a Vulnerability, so that it can be filtered and scored
+// like those from OSV, where a rejected CVE is withdrawn as of when it was last modified
+func (cve NVDCVE) ToVulnerability() Vulnerability {
+	v := Vulnerability{
+		ID:        cve.ID,
+		Published: parseNVDTime(cve.Published),
+		Modified:  parseNVDTime(cve.LastModified),
+	}
+
+	if cve.VulnStatus == "Rejected" {
+		v.Withdrawn = v.Modified
+	}
+
+	for _, d := range cve.Descriptions {
+		if d.Lang == "en" {
+			v.Details = d.Value
+
+			break
+		}
+	}
+
+	for key, metrics := range cve.Metrics {
+		t, ok := nvdMetricTypes[key]
+		if !ok {
+			continue
+		}
+
+		for _, m := range metrics {
+			if m.CVSSData.VectorString != "" {
+				v.Severity = append(v.Severity, Severity{Type: t, Score: m.CVSSData.VectorString})
+			}
+		}
+	}
+
+	return v
+}
+
+// matchesVersion checks if the version is within the version of the criteria, or its bounds when it has them
+func (m NVDCPEMatch) matchesVersion(criteria CPE, version string) bool {
+	hasBounds := m.VersionStartIncluding != "" || m.VersionStartExcluding != "" ||
+		m.VersionEndIncluding != "" || m.VersionEndExcluding != ""
+
+	if !hasBounds || criteria.Version != CPEAny {
+		return matchCPEValue(criteria.Version, version)
+	}
+
+	if version == CPEAny || version == CPENotApplicable {
+		return false
+	}
+
+	return (m.VersionStartIncluding == "" || compareCPEVersions(version, m.VersionStartIncluding) >= 0) &&
+		(m.VersionStartExcluding == "" || compareCPEVersions(version, m.VersionStartExcluding) > 0) &&
+		(m.VersionEndIncluding == "" || compareCPEVersions(version, m.VersionEndIncluding) <= 0) &&
+		(m.VersionEndExcluding == "" || compareCPEVersions(version, m.VersionEndExcluding) < 0)
+}
+
+// Matches checks if the CPE, at the version, matches the criteria
+func (m NVDCPEMatch) Matches(cpe CPE, version string) bool {
+	criteria, err := ParseCPE(m.Criteria)
+	if err != nil {
+		return false
+	}
+
+	return cpe.MatchesCriteria(criteria) && m.matchesVersion(criteria, version)
+}
+
+// isPlatform checks if the node only describes where the vulnerable software runs
+func (n NVDNode) isPlatform() bool {
+	for _, m := range n.CPEMatch {
+		if m.Vulnerable {
+			return false
+		}
+	}
+
+	return true
+}
+
+// match evaluates the vulnerable criteria of the node against the CPE, returning the criteria that matched
+func (n NVDNode) match(cpe CPE, version string) (NVDCPEMatch, bool) {
+	var matched NVDCPEMatch
+
+	found := false
+
+	for _, m := range n.CPEMatch {
+		if !m.Vulnerable {
+			continue
+		}
+
+		if m.Matches(cpe, version) {
+			if !found {
+				matched, found = m, true
+			}
+
+			if n.Operator != NVDOperatorAnd {
+				break
+			}
+		} else if n.Operator == NVDOperatorAnd {
+			return NVDCPEMatch{}, n.Negate
+		}
+	}
+
+	return matched, found != n.Negate
+}
+
+// Match evaluates the configuration against the CPE of a component at the version, returning
+// the vulnerable criteria that it matched.
+//
+// Nodes that only describe the platform the vulnerable software runs on (i.e. "running on Windows")
+// are assumed to be satisfied when they must all match, as the SBOM of a component does not describe
+// where it is deployed.
+func (c NVDConfiguration) Match(cpe CPE, version string) (NVDCPEMatch, bool) {
+	var matched NVDCPEMatch
+
+	found := false
+
+	for _, n := range c.Nodes {
+		if n.isPlatform() {
+			continue
+		}
+
+		m, ok := n.match(cpe, version)
+
+		if ok && m.Criteria != "" && !found {
+			matched, found = m, true
+		}
+
+		if c.Operator == NVDOperatorAnd && !ok {
+			return NVDCPEMatch{}, false
+		}
+
+		if c.Operator != NVDOperatorAnd && found {
+			break
+		}
+	}
+
+	return matched, found
+}
+
+// nvdEntry defines a CVE along with its configurations
+type nvdEntry struct {
+	vulnerability  Vulnerability
+	configurations []NVDConfiguration
+}
+
+// CPEIndex defines the CVEs imported from NVD feeds, indexed by the vendor and product of their vulnerable criteria
+type CPEIndex struct {
+	mu      sync.RWMutex
+	entries map[string]*nvdEntry
+	index   map[string][]*nvdEntry
+}
+
+// NewCPEIndex returns an empty CPEIndex
+func NewCPEIndex() *CPEIndex {
+	return &CPEIndex{entries: make(map[string]*nvdEntry), index: make(map[string][]*nvdEntry)}
+}
+
+// cpeIndexKey returns the key of the vendor and product, where any wildcard in either is indexed as ANY
+func cpeIndexKey(cpe CPE) string {
+	vendor, product := cpe.Vendor, cpe.Product
+
+	if strings.ContainsAny(vendor, "*?") {
+		vendor = CPEAny
+	}
+
+	if strings.ContainsAny(product, "*?") {
+		product = CPEAny
+	}
+
+	return vendor + ":" + product
+}
+
+// Add adds the CVE to the index, replacing it if it was already imported
+func (idx *CPEIndex) Add(cve NVDCVE) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if old, ok := idx.entries[cve.ID]; ok {
+		idx.remove(old)
+	}
+
+	entry := &nvdEntry{vulnerability: cve.ToVulnerability(), configurations: cve.Configurations}
+	keys := make(map[string]bool)
+
+	for _, c := range cve.Configurations {
+		for _, n := range c.Nodes {
+			for _, m := range n.CPEMatch {
+				if criteria, err := ParseCPE(m.Criteria); err == nil && m.Vulnerable {
+					keys[cpeIndexKey(criteria)] = true
+				}
+			}
+		}
+	}
+
+	for key := range keys {
+		idx.index[key] = append(idx.index[key], entry)
+	}
+
+	idx.entries[cve.ID] = entry
+}
+
+// remove removes the entry from the index
+func (idx *CPEIndex) remove(entry *nvdEntry) {
+	for key, entries := range idx.index {
+		for i, e := range entries {
+			if e == entry {
+				idx.index[key] = append(entries[:i], entries[i+1:]...)
+
+				break
+			}
+		}
+	}
+
+	delete(idx.entries, entry.vulnerability.ID)
+}
+
+// Len returns the number of CVEs in the index
+func (idx *CPEIndex) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return len(idx.entries)
+}
+
+// Load adds the CVEs of a NVD CVE JSON 2.0 feed, which can be gzip compressed, returning the number of CVEs that were added
+func (idx *CPEIndex) Load(r io.Reader) (int, error) {
+	reader, err := gzipOrPlain(r)
+	if err != nil {
+		return 0, err
+	}
+
+	var feed NVDFeed
+
+	if err := json.NewDecoder(reader).Decode(&feed); err != nil {
+		return 0, err
+	}
+
+	for _, v := range feed.Vulnerabilities {
+		idx.Add(v.CVE)
+	}
+
+	return len(feed.Vulnerabilities), nil
+}
+
+// gzipOrPlain returns a reader that decompresses the content if it starts with the gzip magic number
+func gzipOrPlain(r io.Reader) (io.Reader, error) {
+	header := make([]byte, 2)
+
+	n, err := io.ReadFull(r, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+
+	reader := io.MultiReader(strings.NewReader(string(header[:n])), r)
+
+	if n == 2 && header[0] == 0x1f && header[1] == 0x8b {
+		return gzip.NewReader(reader)
+	}
+
+	return reader, nil
+}
+
+// Match returns the CVEs whose configurations match the CPE of a component, where the version of
+// the component is used if the CPE does not have one
+func (idx *CPEIndex) Match(cpe CPE, version string) []CPEMatch {
+	if cpe.Version != CPEAny && cpe.Version != "" {
+		version = cpe.Version
+	}
+
+	if version == "" {
+		version = CPEAny
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var matches []CPEMatch
+
+	seen := make(map[string]bool)
+
+	for _, key := range []string{cpeIndexKey(cpe), cpe.Vendor + ":" + CPEAny, CPEAny + ":" + cpe.Product, CPEAny + ":" + CPEAny} {
+		for _, entry := range idx.index[key] {
+			if seen[entry.vulnerability.ID] {
+				continue
+			}
+
+			for _, c := range entry.configurations {
+				if m, ok := c.Match(cpe, version); ok {
+					seen[entry.vulnerability.ID] = true
+					matches = append(matches, CPEMatch{entry.vulnerability, m.Criteria, m.VersionEndExcluding})
+
+					break
+				}
+			}
+		}
+	}
+
+	return matches
+}
+
+// RecommendedCPEVersion returns the lowest version that fixes every one of the matches, which is
+// the highest of the versions they were fixed in, or an empty string if any of them have no fix
+func RecommendedCPEVersion(matches []CPEMatch) string {
+	recommended := ""
+
+	for _, m := range matches {
+		if m.FixedIn == "" {
+			return ""
+		}
+
+		if recommended == "" || compareCPEVersions(m.FixedIn, recommended) > 0 {
+			recommended = m.FixedIn
+		}
+	}
+
+	return recommended
+}
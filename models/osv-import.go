@@ -0,0 +1,106 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OSVEntry defines a vulnerability read from an OSV archive or directory, keeping the original
+// document so that fields which Vulnerability does not define are stored as well
+type OSVEntry struct {
+	Key  string
+	Doc  map[string]interface{}
+	Vuln Vulnerability
+}
+
+// OSVKey returns the document key for the vulnerability id, replacing the characters that ArangoDB does not allow in keys
+func OSVKey(id string) string {
+	return MustCompile(`[^a-zA-Z0-9_\-:.@()+,=;$!*'%]`).ReplaceAllString(id, "_")
+}
+
+// ParseOSVEntry reads and validates an OSV entry, setting the _key of its document from its id
+func ParseOSVEntry(data []byte) (OSVEntry, error) {
+	var entry OSVEntry
+
+	err := json.Unmarshal(data, &entry.Vuln)
+	if err == nil {
+		err = json.Unmarshal(data, &entry.Doc)
+	}
+
+	if err == nil {
+		err = entry.Vuln.Validate()
+	}
+
+	if err != nil {
+		return OSVEntry{}, err
+	}
+
+	entry.Key = OSVKey(entry.Vuln.ID)
+	entry.Doc["_key"] = entry.Key
+
+	return entry, nil
+}
+
+// OSVBatcher defines the queue of OSV entries waiting to be written, which skips the entries that have not been modified
+// since they were last imported and writes the others in batches of Size, counting the entries that were added, updated
+// and unchanged along the way
+type OSVBatcher struct {
+	Modified  map[string]time.Time // modified time of the vulnerabilities that are already stored, by key
+	Full      bool                 // replace every vulnerability, even if it has not been modified
+	Size      int                  // number of entries written at a time
+	Write     func(entries []OSVEntry) error
+	Revision  string // time of the latest modification in the entries, whether or not they were written
+	Added     int
+	Updated   int
+	Unchanged int
+	batch     []OSVEntry
+}
+
+// NewOSVBatcher returns an OSVBatcher for the vulnerabilities that are already stored, which writes the entries with write
+func NewOSVBatcher(modified map[string]time.Time, full bool, size int, write func(entries []OSVEntry) error) *OSVBatcher {
+	return &OSVBatcher{Modified: modified, Full: full, Size: size, Write: write}
+}
+
+// Add queues the entry to be written, unless it has not been modified since it was last imported,
+// writing the queued entries once there are Size of them
+func (b *OSVBatcher) Add(entry OSVEntry) error {
+	if modified := entry.Vuln.Modified.UTC().Format(time.RFC3339); modified > b.Revision {
+		b.Revision = modified
+	}
+
+	modified, ok := b.Modified[entry.Key]
+
+	if ok && !b.Full && modified.Equal(entry.Vuln.Modified) {
+		b.Unchanged++
+		return nil
+	}
+
+	if ok {
+		b.Updated++
+	} else {
+		b.Added++
+	}
+
+	b.Modified[entry.Key] = entry.Vuln.Modified
+	b.batch = append(b.batch, entry)
+
+	if len(b.batch) >= b.Size {
+		return b.Flush()
+	}
+
+	return nil
+}
+
+// Flush writes the queued entries, if there are any
+func (b *OSVBatcher) Flush() error {
+	if len(b.batch) == 0 {
+		return nil
+	}
+
+	batch := b.batch
+	b.batch = nil
+
+	return b.Write(batch)
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+// osvEntryFixture returns the parsed OSV entry of an npm advisory modified at the time
+func osvEntryFixture(t *testing.T, id string, modified string) OSVEntry {
+	t.Helper()
+
+	entry, err := ParseOSVEntry([]byte(`{"id": "` + id + `", "modified": "` + modified + `",
+		"affected": [{"package": {"ecosystem": "npm", "name": "lodash"}, "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}]}]}]}`))
+	if err != nil {
+		t.Fatalf("ParseOSVEntry(%s) returned an error: %v", id, err)
+	}
+
+	return entry
+}
+
+func TestParseOSVEntry(t *testing.T) {
+	entry, err := ParseOSVEntry([]byte(`{"id": "RUSTSEC-2024-0001/a b", "modified": "2024-01-02T03:04:05Z", "database_specific": {"x": 1}}`))
+	if err != nil {
+		t.Fatalf("ParseOSVEntry returned an error: %v", err)
+	}
+
+	if entry.Key != "RUSTSEC-2024-0001_a_b" || entry.Doc["_key"] != entry.Key {
+		t.Errorf("Key = %q and _key = %v, want RUSTSEC-2024-0001_a_b", entry.Key, entry.Doc["_key"])
+	}
+
+	if _, ok := entry.Doc["database_specific"]; !ok {
+		t.Errorf("Doc = %v, want the fields that Vulnerability does not define", entry.Doc)
+	}
+
+	for _, data := range []string{`{"id": "GHSA-1"`, `{"modified": "2024-01-02T03:04:05Z"}`} {
+		if _, err := ParseOSVEntry([]byte(data)); err == nil {
+			t.Errorf("ParseOSVEntry(%s) returned no error", data)
+		}
+	}
+}
+
+func TestOSVBatcher(t *testing.T) {
+	written := [][]string{}
+
+	write := func(entries []OSVEntry) error {
+		keys := []string{}
+
+		for _, entry := range entries {
+			keys = append(keys, entry.Key)
+		}
+
+		written = append(written, keys)
+
+		return nil
+	}
+
+	b := NewOSVBatcher(make(map[string]time.Time), false, 2, write)
+
+	for _, id := range []string{"GHSA-1", "GHSA-2", "GHSA-3", "GHSA-4", "GHSA-5"} {
+		if err := b.Add(osvEntryFixture(t, id, "2024-01-02T03:04:05Z")); err != nil {
+			t.Fatalf("Add(%s) returned an error: %v", id, err)
+		}
+	}
+
+	if want := [][]string{{"GHSA-1", "GHSA-2"}, {"GHSA-3", "GHSA-4"}}; !slices.EqualFunc(written, want, slices.Equal) {
+		t.Errorf("written before the flush = %v, want %v", written, want)
+	}
+
+	if err := b.Flush(); err != nil {
+		t.Fatalf("Flush returned an error: %v", err)
+	}
+
+	if err := b.Flush(); err != nil {
+		t.Fatalf("Flush returned an error: %v", err)
+	}
+
+	if want := [][]string{{"GHSA-1", "GHSA-2"}, {"GHSA-3", "GHSA-4"}, {"GHSA-5"}}; !slices.EqualFunc(written, want, slices.Equal) {
+		t.Errorf("written = %v, want %v", written, want)
+	}
+
+	if b.Added != 5 || b.Updated != 0 || b.Unchanged != 0 || b.Revision != "2024-01-02T03:04:05Z" {
+		t.Errorf("Added = %d, Updated = %d, Unchanged = %d and Revision = %s", b.Added, b.Updated, b.Unchanged, b.Revision)
+	}
+
+	failed := errors.New("failed")
+	b = NewOSVBatcher(make(map[string]time.Time), false, 1, func([]OSVEntry) error { return failed })
+
+	if err := b.Add(osvEntryFixture(t, "GHSA-1", "2024-01-02T03:04:05Z")); !errors.Is(err, failed) {
+		t.Errorf("Add = %v when the batch fails to be written, want %v", err, failed)
+	}
+}
+
+func TestOSVBatcherIncremental(t *testing.T) {
+	stored := map[string]time.Time{
+		"GHSA-unchanged": time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		"GHSA-updated":   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name    string
+		full    bool
+		written []string
+		added   int
+		updated int
+		same    int
+	}{
+		{"incremental", false, []string{"GHSA-updated", "GHSA-added"}, 1, 1, 1},
+		{"full", true, []string{"GHSA-unchanged", "GHSA-updated", "GHSA-added"}, 1, 2, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			written := []string{}
+
+			b := NewOSVBatcher(maps.Clone(stored), tt.full, 10, func(entries []OSVEntry) error {
+				for _, entry := range entries {
+					written = append(written, entry.Key)
+				}
+
+				return nil
+			})
+
+			entries := []OSVEntry{
+				osvEntryFixture(t, "GHSA-unchanged", "2024-01-02T03:04:05Z"),
+				osvEntryFixture(t, "GHSA-updated", "2024-06-01T00:00:00Z"),
+				osvEntryFixture(t, "GHSA-added", "2020-01-01T00:00:00Z"), // modified before the stored advisories
+			}
+
+			for _, entry := range entries {
+				if err := b.Add(entry); err != nil {
+					t.Fatalf("Add(%s) returned an error: %v", entry.Key, err)
+				}
+			}
+
+			if err := b.Flush(); err != nil {
+				t.Fatalf("Flush returned an error: %v", err)
+			}
+
+			if !slices.Equal(written, tt.written) {
+				t.Errorf("written = %v, want %v", written, tt.written)
+			}
+
+			if b.Added != tt.added || b.Updated != tt.updated || b.Unchanged != tt.same {
+				t.Errorf("Added = %d, Updated = %d and Unchanged = %d, want %d, %d and %d", b.Added, b.Updated, b.Unchanged, tt.added, tt.updated, tt.same)
+			}
+
+			if b.Revision != "2024-06-01T00:00:00Z" {
+				t.Errorf("Revision = %s, want the latest modified time", b.Revision)
+			}
+
+			// the same entry appearing again, i.e. in another archive, is unchanged once it has been queued
+			if err := b.Add(entries[2]); err != nil || (!tt.full && b.Unchanged != tt.same+1) {
+				t.Errorf("Add of a queued entry = %v with Unchanged = %d, want it to be unchanged", err, b.Unchanged)
+			}
+		})
+	}
+}
@@ -5,11 +5,35 @@ package models
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 // ErrUnsupportedEcosystem defines the unsupported ecosystem error
 var ErrUnsupportedEcosystem = errors.New("unsupported ecosystem")
 
+// ParseError describes a version string that could not be parsed, including
+// the ecosystem it was parsed for and the token that could not be understood
+type ParseError struct {
+	Ecosystem Ecosystem `json:"ecosystem"`
+	Input     string    `json:"input"`
+	Token     string    `json:"token,omitempty"`
+	Reason    string    `json:"reason"`
+}
+
+// Error implements the error interface
+func (e *ParseError) Error() string {
+	prefix := "invalid version"
+	if e.Ecosystem != "" {
+		prefix = fmt.Sprintf("invalid %s version", e.Ecosystem)
+	}
+
+	if e.Token != "" && e.Token != e.Input {
+		return fmt.Sprintf("%s %q: %s at %q", prefix, e.Input, e.Reason, e.Token)
+	}
+
+	return fmt.Sprintf("%s %q: %s", prefix, e.Input, e.Reason)
+}
+
 // MustParse parses the version string based on the ecosystem and panics if it fails to parse
 func MustParse(str string, ecosystem Ecosystem) Version {
 	v, err := Parse(str, ecosystem)
@@ -21,36 +45,67 @@ func MustParse(str string, ecosystem Ecosystem) Version {
 	return v
 }
 
-// Parse chooses the correct parser based on the ecosystem
+// Parse chooses the correct parser based on the ecosystem, returning
+// a *ParseError if the version string is not valid for that ecosystem
 func Parse(str string, ecosystem Ecosystem) (Version, error) {
+	if strings.TrimSpace(str) == "" {
+		return nil, &ParseError{Ecosystem: ecosystem, Input: str, Reason: "empty version"}
+	}
+
+	v, err := parse(str, ecosystem)
+
+	var perr *ParseError
+	if errors.As(err, &perr) {
+		perr.Ecosystem = ecosystem
+		perr.Input = str
+
+		return nil, perr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func parse(str string, ecosystem Ecosystem) (Version, error) {
 	//nolint:exhaustive // Using strings to specify ecosystem instead of lockfile types
 	switch ecosystem {
 	case "npm":
-		return parseSemverVersion(str), nil
+		return parseSemverVersion(str)
 	case "crates.io":
-		return parseSemverVersion(str), nil
+		return parseSemverVersion(str)
 	case "Debian":
-		return parseDebianVersion(str), nil
+		return parseDebianVersion(str)
+	case "Ubuntu":
+		return parseDebianVersion(str)
 	case "RubyGems":
-		return parseRubyGemsVersion(str), nil
+		return parseRubyGemsVersion(str)
 	case "NuGet":
-		return parseNuGetVersion(str), nil
+		return parseNuGetVersion(str)
 	case "Packagist":
-		return parsePackagistVersion(str), nil
+		return parsePackagistVersion(str)
 	case "Go":
-		return parseSemverVersion(str), nil
+		return parseGoVersion(str)
 	case "Hex":
-		return parseSemverVersion(str), nil
+		return parseSemverVersion(str)
 	case "Maven":
-		return parseMavenVersion(str), nil
+		return parseMavenVersion(str)
 	case "PyPI":
-		return parsePyPIVersion(str), nil
+		return parsePyPIVersion(str)
 	case "Pub":
-		return parseSemverVersion(str), nil
+		return parseSemverVersion(str)
 	case "ConanCenter":
-		return parseSemverVersion(str), nil
+		return parseSemverVersion(str)
 	case "Alpine":
-		return parseSemverVersion(str), nil
+		return parseAlpineVersion(str)
+	case "Rocky Linux":
+		return parseRPMVersion(str)
+	case "AlmaLinux":
+		return parseRPMVersion(str)
+	case "Photon OS":
+		return parseRPMVersion(str)
 	}
 
 	return nil, fmt.Errorf("%w %s", ErrUnsupportedEcosystem, ecosystem)
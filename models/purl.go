@@ -0,0 +1,86 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+)
+
+// purlLowercaseNames defines the purl types, in addition to those packageurl-go already
+// handles, whose names are not case sensitive and are lowercased in the canonical form
+//
+//nolint:gochecknoglobals // this is read-only and the nicest implementation
+var purlLowercaseNames = map[string]bool{
+	"hex": true,
+	"pub": true,
+}
+
+// parseCanonicalPURL parses the purl and normalizes it, which decodes its components, lowercases
+// the type along with the namespace and name of types that are not case sensitive, removes empty
+// qualifiers and sorts the rest by key, as described by the purl spec
+//
+// See https://github.com/package-url/purl-spec/blob/master/PURL-SPECIFICATION.rst
+func parseCanonicalPURL(purl string) (packageurl.PackageURL, error) {
+	parsedPURL, err := packageurl.FromString(strings.TrimSpace(purl))
+	if err != nil {
+		return packageurl.PackageURL{}, err
+	}
+
+	if purlLowercaseNames[parsedPURL.Type] {
+		parsedPURL.Namespace = strings.ToLower(parsedPURL.Namespace)
+		parsedPURL.Name = strings.ToLower(parsedPURL.Name)
+	}
+
+	// golang purls made from the module cache have its "!" encoding of uppercase letters (i.e. "!azure"), which
+	// is decoded and lowercased like the rest of the purl, as packageurl-go lowercases golang purls
+	if parsedPURL.Type == packageurl.TypeGolang {
+		parsedPURL.Namespace = strings.ToLower(normalizeGoModulePath(parsedPURL.Namespace))
+		parsedPURL.Name = strings.ToLower(normalizeGoModulePath(parsedPURL.Name))
+	}
+
+	return parsedPURL, nil
+}
+
+// CanonicalPURL returns the canonical form of the purl, so that equivalent purls such as
+// "pkg:npm/@angular/core@1.0.0" and "pkg:NPM/%40angular/Core@1.0.0" are the same string
+func CanonicalPURL(purl string) (string, error) {
+	parsedPURL, err := parseCanonicalPURL(purl)
+	if err != nil {
+		return "", err
+	}
+
+	return parsedPURL.ToString(), nil
+}
+
+// PURLBase returns the canonical form of the purl without its version, qualifiers and subpath,
+// which is the key of the package regardless of version, i.e. "pkg:npm/%40angular/core"
+func PURLBase(purl string) (string, error) {
+	parsedPURL, err := parseCanonicalPURL(purl)
+	if err != nil {
+		return "", err
+	}
+
+	return packageurl.NewPackageURL(parsedPURL.Type, parsedPURL.Namespace, parsedPURL.Name, "", nil, "").ToString(), nil
+}
+
+// UniquePURLs returns the canonical form of the purls with any duplicates removed, keeping the
+// order they were first seen in, and purls that fail to parse as they are
+func UniquePURLs(purls []string) []string {
+	seen := make(map[string]bool, len(purls))
+	unique := make([]string, 0, len(purls))
+
+	for _, purl := range purls {
+		if canonical, err := CanonicalPURL(purl); err == nil {
+			purl = canonical
+		}
+
+		if !seen[purl] {
+			seen[purl] = true
+			unique = append(unique, purl)
+		}
+	}
+
+	return unique
+}
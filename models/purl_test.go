@@ -0,0 +1,132 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCanonicalPURL(t *testing.T) {
+	tests := []struct {
+		name string
+		purl string
+		want string
+		base string
+	}{
+		{
+			name: "npm scope is encoded and lowercased",
+			purl: "pkg:NPM/@Angular/Core@1.0.0",
+			want: "pkg:npm/%40angular/core@1.0.0",
+			base: "pkg:npm/%40angular/core",
+		},
+		{
+			name: "golang namespace is lowercased",
+			purl: "pkg:golang/GitHub.com/Sirupsen/Logrus@v1.9.0",
+			want: "pkg:golang/github.com/sirupsen/logrus@v1.9.0",
+			base: "pkg:golang/github.com/sirupsen/logrus",
+		},
+		{
+			name: "golang module cache encoding is decoded",
+			purl: "pkg:golang/github.com/!azure/azure-sdk-for-go@v68.0.0+incompatible",
+			want: "pkg:golang/github.com/azure/azure-sdk-for-go@v68.0.0%2Bincompatible",
+			base: "pkg:golang/github.com/azure/azure-sdk-for-go",
+		},
+		{
+			name: "golang module cache encoding of another host",
+			purl: "pkg:golang/example.com/!burnt!sushi/toml@v1.3.2",
+			want: "pkg:golang/example.com/burntsushi/toml@v1.3.2",
+			base: "pkg:golang/example.com/burntsushi/toml",
+		},
+		{
+			name: "pypi underscores are dashes",
+			purl: "pkg:pypi/Zope_Interface@5.0",
+			want: "pkg:pypi/zope-interface@5.0",
+			base: "pkg:pypi/zope-interface",
+		},
+		{
+			name: "qualifiers are sorted and empty ones removed",
+			purl: "pkg:deb/Debian/OpenSSL@3.0.11-1?distro=debian-12&arch=amd64&epoch=",
+			want: "pkg:deb/debian/openssl@3.0.11-1?arch=amd64&distro=debian-12",
+			base: "pkg:deb/debian/openssl",
+		},
+		{
+			name: "qualifiers of the same purl in another order",
+			purl: "pkg:deb/debian/openssl@3.0.11-1?arch=amd64&distro=debian-12",
+			want: "pkg:deb/debian/openssl@3.0.11-1?arch=amd64&distro=debian-12",
+			base: "pkg:deb/debian/openssl",
+		},
+		{
+			name: "hex names are lowercased",
+			purl: "pkg:hex/Phoenix@1.7.10",
+			want: "pkg:hex/phoenix@1.7.10",
+			base: "pkg:hex/phoenix",
+		},
+		{
+			name: "pub names are lowercased",
+			purl: "pkg:pub/HTTP@1.1.0",
+			want: "pkg:pub/http@1.1.0",
+			base: "pkg:pub/http",
+		},
+		{
+			name: "maven names are case sensitive",
+			purl: "pkg:maven/org.Apache/Log4j@2.0?type=jar",
+			want: "pkg:maven/org.Apache/Log4j@2.0?type=jar",
+			base: "pkg:maven/org.Apache/Log4j",
+		},
+		{
+			name: "nuget names are kept as they are",
+			purl: "pkg:nuget/Newtonsoft.Json@13.0.3",
+			want: "pkg:nuget/Newtonsoft.Json@13.0.3",
+			base: "pkg:nuget/Newtonsoft.Json",
+		},
+		{
+			name: "subpath",
+			purl: " pkg:npm/lodash@4.17.20#dist/lodash.js ",
+			want: "pkg:npm/lodash@4.17.20#dist/lodash.js",
+			base: "pkg:npm/lodash",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := CanonicalPURL(tt.purl); err != nil || got != tt.want {
+				t.Errorf("CanonicalPURL(%q) = %q, %v, want %q", tt.purl, got, err, tt.want)
+			}
+
+			if got, err := PURLBase(tt.purl); err != nil || got != tt.base {
+				t.Errorf("PURLBase(%q) = %q, %v, want %q", tt.purl, got, err, tt.base)
+			}
+		})
+	}
+
+	if _, err := CanonicalPURL("lodash@4.17.20"); err == nil {
+		t.Errorf("CanonicalPURL of a name without a scheme returned no error")
+	}
+}
+
+func TestUniquePURLs(t *testing.T) {
+	got := UniquePURLs([]string{
+		"pkg:npm/%40angular/core",
+		"pkg:NPM/@angular/core",
+		"pkg:golang/github.com/!azure/go-autorest",
+		"pkg:golang/github.com/Azure/go-autorest",
+		"not a purl",
+		"not a purl",
+		"pkg:pypi/django",
+	})
+
+	want := []string{"pkg:npm/%40angular/core", "pkg:golang/github.com/azure/go-autorest", "not a purl", "pkg:pypi/django"}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("UniquePURLs = %v, want %v", got, want)
+	}
+}
+
+func TestSourcePURL(t *testing.T) {
+	if got, err := SourcePURL("pkg:deb/Debian/libssl3@3.0.11-1?distro=debian-12", "openssl"); err != nil || got != "pkg:deb/debian/openssl" {
+		t.Errorf("SourcePURL = %q, %v, want pkg:deb/debian/openssl", got, err)
+	}
+
+	if _, err := SourcePURL("libssl3", "openssl"); err == nil {
+		t.Errorf("SourcePURL of a name without a scheme returned no error")
+	}
+}
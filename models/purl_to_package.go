@@ -3,26 +3,86 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
 	"github.com/package-url/packageurl-go"
 )
 
+// ErrPURLNamespaceRequired defines the error for a package whose purl type requires a namespace, but whose name has none
+var ErrPURLNamespaceRequired = errors.New("purl type requires a namespace")
+
+// purlNamespaceRequired defines the purl types that cannot be parsed without a namespace,
+// i.e. the host and path of a Swift package, as in "pkg:swift/github.com/apple/swift-nio"
+//
+//nolint:gochecknoglobals // this is read-only and the nicest implementation
+var purlNamespaceRequired = map[string]bool{
+	"swift": true,
+}
+
+// getPURLMapping returns the mapping of the purl type and namespace to an ecosystem,
+// preferring a mapping of the exact namespace over one that matches any namespace
+func getPURLMapping(pkgURL packageurl.PackageURL) (purlMapping, bool) {
+	var wildcard *purlMapping
+
+	for i, m := range purlMappings {
+		if m.Type != pkgURL.Type {
+			continue
+		}
+
+		if m.Namespace == pkgURL.Namespace {
+			return m, true
+		}
+
+		if m.Namespace == "*" && wildcard == nil {
+			wildcard = &purlMappings[i]
+		}
+	}
+
+	if wildcard != nil {
+		return *wildcard, true
+	}
+
+	return purlMapping{}, false
+}
+
+// getEcosystemMapping returns the first mapping of the ecosystem, which is the one used to create its purls
+func getEcosystemMapping(ecosystem Ecosystem) (purlMapping, bool) {
+	for _, m := range purlMappings {
+		if m.Ecosystem == ecosystem {
+			return m, true
+		}
+	}
+
+	return purlMapping{}, false
+}
+
 func getPURLEcosystem(pkgURL packageurl.PackageURL) Ecosystem {
-	ecoMap, ok := purlEcosystems[pkgURL.Type]
+	m, ok := getPURLMapping(pkgURL)
 	if !ok {
 		return Ecosystem(pkgURL.Type + ":" + pkgURL.Namespace)
 	}
 
-	wildcardRes, hasWildcard := ecoMap["*"]
-	if hasWildcard {
-		return wildcardRes
-	}
+	return m.Ecosystem
+}
 
-	ecosystem, ok := ecoMap[pkgURL.Namespace]
+// getPURLCommit returns the commit from the vcs_url qualifier, which
+// is in the form of <type>+<url>@<commit>, if the purl has one
+func getPURLCommit(pkgURL packageurl.PackageURL) string {
+	vcsURL, ok := pkgURL.Qualifiers.Map()["vcs_url"]
 	if !ok {
-		return Ecosystem(pkgURL.Type + ":" + pkgURL.Namespace)
+		return ""
+	}
+
+	i := strings.LastIndex(vcsURL, "@")
+
+	// an @ before the path is userinfo rather than a commit
+	if i == -1 || strings.Contains(vcsURL[i:], "/") {
+		return ""
 	}
 
-	return ecosystem
+	return vcsURL[i+1:]
 }
 
 // PURLToPackage converts a Package URL string to models.PackageInfo
@@ -32,16 +92,17 @@ func PURLToPackage(purl string) (PackageInfo, error) {
 		return PackageInfo{}, err
 	}
 	ecosystem := getPURLEcosystem(parsedPURL)
+	mapping, _ := getPURLMapping(parsedPURL)
 
 	// PackageInfo expects the full namespace in the name for ecosystems that specify it.
 	name := parsedPURL.Name
 	if parsedPURL.Namespace != "" {
-		switch ecosystem { //nolint:exhaustive
-		case EcosystemMaven:
+		switch mapping.Name {
+		case purlNameColon:
 			// Maven uses : to separate namespace and package
 			name = parsedPURL.Namespace + ":" + parsedPURL.Name
-		case EcosystemDebian, EcosystemAlpine:
-			// Debian and Alpine repeats their namespace in PURL, so don't add it to the name
+		case purlNameOnly:
+			// Linux distributions repeat their namespace in PURL, so don't add it to the name
 			name = parsedPURL.Name
 		default:
 			name = parsedPURL.Namespace + "/" + parsedPURL.Name
@@ -52,5 +113,68 @@ func PURLToPackage(purl string) (PackageInfo, error) {
 		Name:      name,
 		Ecosystem: string(ecosystem),
 		Version:   parsedPURL.Version,
+		Commit:    getPURLCommit(parsedPURL),
+		Distro:    parsedPURL.Qualifiers.Map()["distro"],
+		Source:    getPURLSource(parsedPURL),
 	}, nil
 }
+
+// PackageToPURL converts a models.PackageInfo to a Package URL string, which is the
+// reverse of PURLToPackage, returning ErrUnsupportedEcosystem if the ecosystem has no purl type
+// and ErrPURLNamespaceRequired if the name has no namespace when the purl type requires one
+func PackageToPURL(pkg PackageInfo) (string, error) {
+	// the release of a distro ecosystem, i.e. "Debian:12", is not part of the purl type
+	ecosystem, _, _ := strings.Cut(pkg.Ecosystem, ":")
+
+	m, ok := getEcosystemMapping(Ecosystem(ecosystem))
+	if !ok {
+		return "", fmt.Errorf("%w %s", ErrUnsupportedEcosystem, pkg.Ecosystem)
+	}
+
+	namespace, name := "", pkg.Name
+
+	switch m.Name {
+	case purlNameColon:
+		if i := strings.Index(pkg.Name, ":"); i != -1 {
+			namespace, name = pkg.Name[:i], pkg.Name[i+1:]
+		}
+	case purlNameOnly:
+		namespace = m.Namespace
+	case purlNameSlash:
+		if i := strings.LastIndex(pkg.Name, "/"); i != -1 {
+			namespace, name = pkg.Name[:i], pkg.Name[i+1:]
+		}
+	}
+
+	if namespace == "" && purlNamespaceRequired[m.Type] {
+		return "", fmt.Errorf("%w %s: %s", ErrPURLNamespaceRequired, m.Type, pkg.Name)
+	}
+
+	var qualifiers packageurl.Qualifiers
+
+	if pkg.Distro != "" {
+		qualifiers = append(qualifiers, packageurl.Qualifier{Key: "distro", Value: pkg.Distro})
+	}
+
+	if pkg.Source.Name != "" {
+		upstream := pkg.Source.Name
+		if pkg.Source.Version != "" {
+			upstream += "@" + pkg.Source.Version
+		}
+
+		qualifiers = append(qualifiers, packageurl.Qualifier{Key: "upstream", Value: upstream})
+	}
+
+	return packageurl.NewPackageURL(m.Type, namespace, name, pkg.Version, qualifiers, "").ToString(), nil
+}
+
+// SourcePURL returns the purl of the source package, without a version, qualifiers or subpath,
+// that the binary package of the given purl was built from, i.e. "pkg:deb/debian/openssl" for libssl3
+func SourcePURL(purl string, source string) (string, error) {
+	parsedPURL, err := parseCanonicalPURL(purl)
+	if err != nil {
+		return "", err
+	}
+
+	return PURLBase(packageurl.NewPackageURL(parsedPURL.Type, parsedPURL.Namespace, source, "", nil, "").ToString())
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/package-url/packageurl-go"
+)
+
+func TestPURLMappingsRoundTrip(t *testing.T) {
+	// the namespace and name of a package of each purl type, where the namespace of a
+	// mapping that is not "*" (i.e. "debian") is used instead of the one given here
+	examples := map[string][2]string{
+		"golang":        {"github.com/gin-gonic", "gin"},
+		"npm":           {"@angular", "core"},
+		"generic":       {"", "zlib"},
+		"pypi":          {"", "django"},
+		"gem":           {"", "rails"},
+		"cargo":         {"", "serde"},
+		"composer":      {"symfony", "http-kernel"},
+		"maven":         {"org.apache.logging.log4j", "log4j-core"},
+		"nuget":         {"", "Newtonsoft.Json"},
+		"deb":           {"", "openssl"},
+		"apk":           {"", "openssl"},
+		"hex":           {"", "phoenix"},
+		"github":        {"actions", "checkout"},
+		"githubactions": {"actions", "checkout"},
+		"pub":           {"", "http"},
+		"conan":         {"", "openssl"},
+		"rpm":           {"", "openssl"},
+		"bitnami":       {"", "wordpress"},
+		"cran":          {"", "ggplot2"},
+		"bioconductor":  {"", "GenomicRanges"},
+		"swift":         {"github.com/apple", "swift-nio"},
+		"hackage":       {"", "aeson"},
+	}
+
+	for _, m := range purlMappings {
+		t.Run(m.Type+"/"+m.Namespace, func(t *testing.T) {
+			example, ok := examples[m.Type]
+			if !ok {
+				t.Fatalf("no example package of the purl type %s", m.Type)
+			}
+
+			namespace, name := example[0], example[1]
+			if m.Namespace != "*" {
+				namespace = m.Namespace
+			}
+
+			purl := packageurl.NewPackageURL(m.Type, namespace, name, "1.0.0", nil, "").ToString()
+
+			pkg, err := PURLToPackage(purl)
+			if err != nil {
+				t.Fatalf("PURLToPackage(%s) returned an error: %v", purl, err)
+			}
+
+			if pkg.Ecosystem != string(m.Ecosystem) || pkg.Version != "1.0.0" {
+				t.Errorf("PURLToPackage(%s) = %+v, want the %s ecosystem", purl, pkg, m.Ecosystem)
+			}
+
+			back, err := PackageToPURL(pkg)
+			if err != nil {
+				t.Fatalf("PackageToPURL(%+v) returned an error: %v", pkg, err)
+			}
+
+			// purls are created with the first mapping of the ecosystem, i.e. "github" rather than "githubactions"
+			if first, _ := getEcosystemMapping(m.Ecosystem); first == m && back != purl {
+				t.Errorf("PackageToPURL(%+v) = %s, want %s", pkg, back, purl)
+			}
+
+			again, err := PURLToPackage(back)
+			if err != nil {
+				t.Fatalf("PURLToPackage(%s) returned an error: %v", back, err)
+			}
+
+			if again != pkg {
+				t.Errorf("PURLToPackage(%s) = %+v, want %+v", back, again, pkg)
+			}
+		})
+	}
+}
+
+func TestPackageToPURL(t *testing.T) {
+	tests := []struct {
+		name string
+		pkg  PackageInfo
+		want string
+		err  error
+	}{
+		{
+			name: "distro release and source package",
+			pkg:  PackageInfo{Name: "libssl3", Version: "3.0.11-1", Ecosystem: "Debian:12", Distro: "debian-12", Source: SourcePackage{"openssl", "3.0.11-1"}},
+			want: "pkg:deb/debian/libssl3@3.0.11-1?distro=debian-12&upstream=openssl%403.0.11-1",
+		},
+		{
+			name: "maven without a group",
+			pkg:  PackageInfo{Name: "log4j", Ecosystem: "Maven"},
+			want: "pkg:maven/log4j",
+		},
+		{
+			name: "swift package with its host",
+			pkg:  PackageInfo{Name: "github.com/apple/swift-nio", Version: "2.0.0", Ecosystem: "SwiftURL"},
+			want: "pkg:swift/github.com/apple/swift-nio@2.0.0",
+		},
+		{
+			name: "swift package without its host",
+			pkg:  PackageInfo{Name: "swift-nio", Version: "2.0.0", Ecosystem: "SwiftURL"},
+			err:  ErrPURLNamespaceRequired,
+		},
+		{
+			name: "ecosystem without a purl type",
+			pkg:  PackageInfo{Name: "widget", Ecosystem: "Unknown"},
+			err:  ErrUnsupportedEcosystem,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PackageToPURL(tt.pkg)
+			if !errors.Is(err, tt.err) || got != tt.want {
+				t.Errorf("PackageToPURL(%+v) = %q, %v, want %q, %v", tt.pkg, got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
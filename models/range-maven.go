@@ -0,0 +1,135 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"strings"
+)
+
+// parseIntervalNotation parses a comma separated list of mathematical interval
+// notation ranges, such as "(,1.0],[1.2,)", as used by both Maven and NuGet
+func parseIntervalNotation(str string) ([]VersionInterval, error) {
+	var intervals []VersionInterval
+
+	rest := strings.TrimSpace(str)
+
+	for rest != "" {
+		if rest[0] != '[' && rest[0] != '(' {
+			return nil, &ParseError{Token: rest, Reason: "expected '[' or '('"}
+		}
+
+		end := strings.IndexAny(rest, "])")
+		if end == -1 {
+			return nil, &ParseError{Token: rest, Reason: "expected ']' or ')'"}
+		}
+
+		vi, err := parseInterval(rest[:end+1])
+		if err != nil {
+			return nil, err
+		}
+
+		intervals = append(intervals, vi)
+
+		rest = strings.TrimSpace(rest[end+1:])
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, ","))
+	}
+
+	return intervals, nil
+}
+
+func parseInterval(interval string) (VersionInterval, error) {
+	open, closing := interval[0], interval[len(interval)-1]
+	bounds := strings.Split(interval[1:len(interval)-1], ",")
+
+	switch len(bounds) {
+	case 1:
+		// only an exact version can be written without a comma, i.e. [1.0]
+		version := strings.TrimSpace(bounds[0])
+
+		if open != '[' || closing != ']' || version == "" {
+			return nil, &ParseError{Token: interval, Reason: "invalid exact version"}
+		}
+
+		return VersionInterval{constraint(OperatorEqual, version)}, nil
+	case 2:
+		vi := VersionInterval{}
+
+		if lower := strings.TrimSpace(bounds[0]); lower != "" {
+			if open == '[' {
+				vi = append(vi, constraint(OperatorGreaterOrEqual, lower))
+			} else {
+				vi = append(vi, constraint(OperatorGreaterThan, lower))
+			}
+		}
+
+		if upper := strings.TrimSpace(bounds[1]); upper != "" {
+			if closing == ']' {
+				vi = append(vi, constraint(OperatorLessOrEqual, upper))
+			} else {
+				vi = append(vi, constraint(OperatorLessThan, upper))
+			}
+		}
+
+		return vi, nil
+	}
+
+	return nil, &ParseError{Token: interval, Reason: "too many versions in interval"}
+}
+
+// parseMavenRange parses a Maven version range, where a version outside
+// of an interval is a "soft" requirement for that exact version
+//
+// See https://maven.apache.org/enforcer/enforcer-rules/versionRanges.html
+func parseMavenRange(str string) ([]VersionInterval, error) {
+	str = strings.TrimSpace(str)
+
+	if !strings.ContainsAny(str, "[(") {
+		if str == "" || strings.ContainsAny(str, ",])") {
+			return nil, &ParseError{Token: str, Reason: "invalid version"}
+		}
+
+		return []VersionInterval{{constraint(OperatorEqual, str)}}, nil
+	}
+
+	return parseIntervalNotation(str)
+}
+
+// parseNuGetRange parses a NuGet version range, where a version outside of
+// an interval is the minimum version and may float using a trailing "*"
+//
+// See https://learn.microsoft.com/en-us/nuget/concepts/package-versioning#version-ranges
+func parseNuGetRange(str string) ([]VersionInterval, error) {
+	str = strings.TrimSpace(str)
+
+	if strings.ContainsAny(str, "[(") {
+		return parseIntervalNotation(str)
+	}
+
+	if str == "" || strings.ContainsAny(str, ",])") {
+		return nil, &ParseError{Token: str, Reason: "invalid version"}
+	}
+
+	if str == "*" {
+		return []VersionInterval{{}}, nil
+	}
+
+	// a floating version such as 1.2.* allows any version with the same prefix
+	if prefix, floating := strings.CutSuffix(str, ".*"); floating {
+		components := strings.Split(prefix, ".")
+		last := len(components) - 1
+
+		if _, isNumber := convertToBigInt(components[last]); !isNumber {
+			return nil, &ParseError{Token: str, Reason: "invalid floating version"}
+		}
+
+		next := append([]string{}, components...)
+		next[last] = incrementNumber(next[last])
+
+		return []VersionInterval{{
+			constraint(OperatorGreaterOrEqual, prefix),
+			constraint(OperatorLessThan, strings.Join(next, ".")),
+		}}, nil
+	}
+
+	return []VersionInterval{{constraint(OperatorGreaterOrEqual, str)}}, nil
+}
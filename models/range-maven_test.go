@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestParseVersionRangeMaven(t *testing.T) {
+	expectRangeMembership(t, "Maven", []rangeMembership{
+		{"[1.0,2.0)", []string{"1.0", "1.5", "1.9.9"}, []string{"0.9", "2.0", "2.0.1"}},
+		{"[1.0,2.0]", []string{"1.0", "2.0"}, []string{"2.0.1"}},
+		{"(,1.0]", []string{"0.1", "1.0"}, []string{"1.0.1"}},
+		{"(,1.0],[1.2,)", []string{"1.0", "1.2", "9.0"}, []string{"1.1"}},
+		{"[1.5]", []string{"1.5", "1.5.0"}, []string{"1.5.1"}},
+		{"(1.0,)", []string{"1.0.1", "2"}, []string{"1.0", "0.9"}},
+	})
+
+	expectRangeParseErrors(t, "Maven", []string{
+		"[1.0,2.0",
+		"1.0,2.0)",
+		"[1.0,2.0,3.0]",
+	})
+}
+
+func TestParseVersionRangeNuGet(t *testing.T) {
+	expectRangeMembership(t, "NuGet", []rangeMembership{
+		{"1.0", []string{"1.0.0", "2.0.0"}, []string{"0.9.0"}},
+		{"[1.0,2.0)", []string{"1.0.0", "1.9.9"}, []string{"2.0.0", "0.9.0"}},
+		{"(1.0,)", []string{"1.0.1"}, []string{"1.0.0"}},
+		{"[1.0]", []string{"1.0.0"}, []string{"1.0.1"}},
+		{"1.*", []string{"1.0.0", "1.9.0"}, []string{"0.9.0"}},
+	})
+
+	expectRangeParseErrors(t, "NuGet", []string{
+		"[1.0,2.0",
+		"(1.0",
+	})
+}
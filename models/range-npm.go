@@ -0,0 +1,256 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"strings"
+)
+
+// partialVersion is a version where any of the numeric components may be missing
+// or a wildcard (x, X or *), as used by X-ranges in npm constraints
+type partialVersion struct {
+	major string
+	minor string
+	patch string
+	pre   string
+}
+
+func isWildcardComponent(str string) bool {
+	return str == "" || str == "x" || str == "X" || str == "*"
+}
+
+func parsePartialVersion(str string) (partialVersion, bool) {
+	re := MustCompile(`^v?(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+	match := re.FindStringSubmatch(str)
+
+	if len(match) == 0 {
+		return partialVersion{}, false
+	}
+
+	pv := partialVersion{match[1], match[2], match[3], match[4]}
+
+	// anything after a wildcard is also a wildcard
+	if isWildcardComponent(pv.major) {
+		pv.minor = ""
+	}
+	if isWildcardComponent(pv.minor) {
+		pv.patch = ""
+	}
+	if isWildcardComponent(pv.patch) {
+		pv.pre = ""
+	}
+
+	for _, c := range []*string{&pv.major, &pv.minor, &pv.patch} {
+		if isWildcardComponent(*c) {
+			*c = ""
+		}
+	}
+
+	return pv, true
+}
+
+// String returns the version with any missing components filled in with zeros
+func (pv partialVersion) String() string {
+	components := []string{pv.major, pv.minor, pv.patch}
+
+	for i, c := range components {
+		if c == "" {
+			components[i] = "0"
+		}
+	}
+
+	return npmVersion(components[0], components[1], components[2], pv.pre)
+}
+
+// npmVersion returns a version from the components, where "-0" can be added to
+// exclude any prereleases of the version when used as an exclusive upper bound
+func npmVersion(major, minor, patch, pre string) string {
+	v := major + "." + minor + "." + patch
+
+	if pre != "" {
+		v += "-" + pre
+	}
+
+	return v
+}
+
+// parseNpmRange parses a range in the syntax used by node-semver, i.e. "^4.17.0 || >=5.0.0 <5.2.0"
+//
+// See https://github.com/npm/node-semver#ranges
+func parseNpmRange(str string) ([]VersionInterval, error) {
+	var intervals []VersionInterval
+
+	for _, set := range strings.Split(str, "||") {
+		vi, err := parseNpmComparatorSet(strings.TrimSpace(set))
+		if err != nil {
+			return nil, err
+		}
+
+		intervals = append(intervals, vi)
+	}
+
+	return intervals, nil
+}
+
+func parseNpmComparatorSet(set string) (VersionInterval, error) {
+	vi := VersionInterval{}
+
+	if match := MustCompile(`^(\S+)\s+-\s+(\S+)$`).FindStringSubmatch(set); len(match) != 0 {
+		return parseNpmHyphenRange(match[1], match[2])
+	}
+
+	// operators can be separated from their version by whitespace
+	set = MustCompile(`(<=|>=|<|>|=|~>|~|\^)\s+`).ReplaceAllString(set, "$1")
+
+	for _, comparator := range strings.Fields(set) {
+		constraints, err := parseNpmComparator(comparator)
+		if err != nil {
+			return nil, err
+		}
+
+		vi = append(vi, constraints...)
+	}
+
+	return vi, nil
+}
+
+func parseNpmHyphenRange(from, to string) (VersionInterval, error) {
+	lower, ok := parsePartialVersion(from)
+	if !ok {
+		return nil, &ParseError{Token: from, Reason: "invalid version"}
+	}
+
+	upper, ok := parsePartialVersion(to)
+	if !ok {
+		return nil, &ParseError{Token: to, Reason: "invalid version"}
+	}
+
+	vi := VersionInterval{}
+
+	if lower.major != "" {
+		vi = append(vi, constraint(OperatorGreaterOrEqual, lower.String()))
+	}
+
+	switch {
+	case upper.major == "":
+		// an upper bound of "*" does not limit the range
+	case upper.minor == "":
+		vi = append(vi, constraint(OperatorLessThan, npmVersion(incrementNumber(upper.major), "0", "0", "0")))
+	case upper.patch == "":
+		vi = append(vi, constraint(OperatorLessThan, npmVersion(upper.major, incrementNumber(upper.minor), "0", "0")))
+	default:
+		vi = append(vi, constraint(OperatorLessOrEqual, upper.String()))
+	}
+
+	return vi, nil
+}
+
+func parseNpmComparator(comparator string) (VersionInterval, error) {
+	match := MustCompile(`^(<=|>=|<|>|=|~>|~|\^)?(.*)$`).FindStringSubmatch(comparator)
+	operator, version := match[1], match[2]
+
+	pv, ok := parsePartialVersion(version)
+	if !ok {
+		return nil, &ParseError{Token: comparator, Reason: "invalid comparator"}
+	}
+
+	switch operator {
+	case "~", "~>":
+		return npmTildeRange(pv), nil
+	case "^":
+		return npmCaretRange(pv), nil
+	}
+
+	return npmXRange(operator, pv), nil
+}
+
+// npmTildeRange allows patch-level changes if a minor version is specified,
+// or minor-level changes if not
+func npmTildeRange(pv partialVersion) VersionInterval {
+	switch {
+	case pv.major == "":
+		return VersionInterval{}
+	case pv.minor == "":
+		return VersionInterval{
+			constraint(OperatorGreaterOrEqual, npmVersion(pv.major, "0", "0", "")),
+			constraint(OperatorLessThan, npmVersion(incrementNumber(pv.major), "0", "0", "0")),
+		}
+	}
+
+	return VersionInterval{
+		constraint(OperatorGreaterOrEqual, pv.String()),
+		constraint(OperatorLessThan, npmVersion(pv.major, incrementNumber(pv.minor), "0", "0")),
+	}
+}
+
+// npmCaretRange allows changes that do not modify the left-most non-zero component
+func npmCaretRange(pv partialVersion) VersionInterval {
+	var upper string
+
+	switch {
+	case pv.major == "":
+		return VersionInterval{}
+	case pv.minor == "":
+		upper = npmVersion(incrementNumber(pv.major), "0", "0", "0")
+	case pv.patch == "":
+		if pv.major == "0" {
+			upper = npmVersion(pv.major, incrementNumber(pv.minor), "0", "0")
+		} else {
+			upper = npmVersion(incrementNumber(pv.major), "0", "0", "0")
+		}
+	case pv.major != "0":
+		upper = npmVersion(incrementNumber(pv.major), "0", "0", "0")
+	case pv.minor != "0":
+		upper = npmVersion(pv.major, incrementNumber(pv.minor), "0", "0")
+	default:
+		upper = npmVersion(pv.major, pv.minor, incrementNumber(pv.patch), "0")
+	}
+
+	return VersionInterval{
+		constraint(OperatorGreaterOrEqual, pv.String()),
+		constraint(OperatorLessThan, upper),
+	}
+}
+
+// npmXRange handles a primitive comparison where the version may contain wildcards
+func npmXRange(operator string, pv partialVersion) VersionInterval {
+	if operator == "" {
+		operator = OperatorEqual
+	}
+
+	// without wildcards, this is just a regular comparison
+	if pv.patch != "" {
+		return VersionInterval{constraint(operator, pv.String())}
+	}
+
+	if pv.major == "" {
+		// nothing can be less or greater than every version
+		if operator == OperatorLessThan || operator == OperatorGreaterThan {
+			return VersionInterval{constraint(OperatorLessThan, "0.0.0-0")}
+		}
+
+		return VersionInterval{}
+	}
+
+	// the version after the wildcard, i.e. 2.0.0 for 1.x and 1.3.0 for 1.2.x
+	next := npmVersion(incrementNumber(pv.major), "0", "0", "")
+	if pv.minor != "" {
+		next = npmVersion(pv.major, incrementNumber(pv.minor), "0", "")
+	}
+
+	switch operator {
+	case OperatorGreaterThan:
+		return VersionInterval{constraint(OperatorGreaterOrEqual, next)}
+	case OperatorGreaterOrEqual:
+		return VersionInterval{constraint(OperatorGreaterOrEqual, pv.String())}
+	case OperatorLessThan:
+		return VersionInterval{constraint(OperatorLessThan, pv.String()+"-0")}
+	case OperatorLessOrEqual:
+		return VersionInterval{constraint(OperatorLessThan, next+"-0")}
+	}
+
+	return VersionInterval{
+		constraint(OperatorGreaterOrEqual, pv.String()),
+		constraint(OperatorLessThan, next+"-0"),
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestParseVersionRangeNpm(t *testing.T) {
+	expectRangeMembership(t, "npm", []rangeMembership{
+		{"^4.17.0", []string{"4.17.0", "4.17.21", "4.18.0-beta", "4.99.0"}, []string{"4.16.9", "5.0.0", "5.0.0-rc.1"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0", "0.2.2"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4", "0.0.2"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.99"}, []string{"1.3.0", "1.2.2"}},
+		{"~1", []string{"1.0.0", "1.9.9"}, []string{"2.0.0", "0.9.9"}},
+		{">=1.0.0 <1.2.0 || >=2.0.0", []string{"1.0.0", "1.1.9", "2.0.0", "3.1.4"}, []string{"0.9.0", "1.2.0", "1.9.9"}},
+		{"1.2.3 - 2.3.4", []string{"1.2.3", "2.3.4"}, []string{"1.2.2", "2.3.5"}},
+		{"1.2 - 2.3", []string{"1.2.0", "2.3.99"}, []string{"1.1.9", "2.4.0"}},
+		{"1.x", []string{"1.0.0", "1.99.0"}, []string{"2.0.0", "0.1.0"}},
+		{"*", []string{"0.0.1", "99.0.0"}, nil},
+		{"<1.0.0", []string{"0.9.9"}, []string{"1.0.0", "1.0.1"}},
+		{"=1.2.3", []string{"1.2.3"}, []string{"1.2.4"}},
+	})
+
+	expectRangeParseErrors(t, "npm", []string{
+		">=foo",
+		"^1.2.3.4",
+		"1.2.3 - bar",
+	})
+}
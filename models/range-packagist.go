@@ -0,0 +1,155 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"strings"
+)
+
+// packagistNumbers returns the leading numeric components of the version
+func packagistNumbers(version string) []string {
+	release := MustCompile(`^[vV]?(\d+(?:\.\d+)*)`).FindStringSubmatch(version)
+
+	if len(release) == 0 {
+		return nil
+	}
+
+	return strings.Split(release[1], ".")
+}
+
+// packagistUpperBound increments the component at the given index and drops those
+// after it, with a "-dev" suffix so that prereleases of that version are excluded
+func packagistUpperBound(numbers []string, i int) string {
+	next := append([]string{}, numbers[:i+1]...)
+	next[i] = incrementNumber(next[i])
+
+	return strings.Join(next, ".") + "-dev"
+}
+
+// parsePackagistRange parses a Composer version constraint, i.e. "^1.2 || ~2.0.3" or ">=1.0 <1.1"
+//
+// See https://getcomposer.org/doc/articles/versions.md#writing-version-constraints
+func parsePackagistRange(str string) ([]VersionInterval, error) {
+	var intervals []VersionInterval
+
+	for _, set := range MustCompile(`\|\|?`).Split(str, -1) {
+		vi, err := parsePackagistConstraintSet(strings.TrimSpace(set))
+		if err != nil {
+			return nil, err
+		}
+
+		intervals = append(intervals, vi)
+	}
+
+	return intervals, nil
+}
+
+func parsePackagistConstraintSet(set string) (VersionInterval, error) {
+	// stability flags only affect which versions composer will install
+	set = MustCompile(`@[a-zA-Z]+`).ReplaceAllString(set, "")
+
+	if match := MustCompile(`^(\S+)\s+-\s+(\S+)$`).FindStringSubmatch(set); len(match) != 0 {
+		return parsePackagistHyphenRange(match[1], match[2])
+	}
+
+	// operators can be separated from their version by whitespace
+	set = MustCompile(`(<>|!=|>=|<=|==|=|>|<|\^|~)\s+`).ReplaceAllString(set, "$1")
+
+	vi := VersionInterval{}
+
+	for _, c := range MustCompile(`[\s,]+`).Split(set, -1) {
+		if c == "" {
+			continue
+		}
+
+		constraints, err := parsePackagistConstraint(c)
+		if err != nil {
+			return nil, err
+		}
+
+		vi = append(vi, constraints...)
+	}
+
+	return vi, nil
+}
+
+func parsePackagistHyphenRange(from, to string) (VersionInterval, error) {
+	upper := packagistNumbers(to)
+
+	if len(packagistNumbers(from)) == 0 || len(upper) == 0 {
+		return nil, &ParseError{Token: from + " - " + to, Reason: "invalid hyphenated range"}
+	}
+
+	vi := VersionInterval{constraint(OperatorGreaterOrEqual, from)}
+
+	// a partial upper bound matches every version with the same prefix, i.e. "1.0 - 2.0" includes 2.0.5
+	if len(upper) < 3 {
+		return append(vi, constraint(OperatorLessThan, packagistUpperBound(upper, len(upper)-1))), nil
+	}
+
+	return append(vi, constraint(OperatorLessOrEqual, to)), nil
+}
+
+func parsePackagistConstraint(c string) (VersionInterval, error) {
+	match := MustCompile(`^(<>|!=|>=|<=|==|=|>|<|\^|~)?(.+)$`).FindStringSubmatch(c)
+	operator, version := match[1], match[2]
+
+	if version == "*" {
+		return VersionInterval{}, nil
+	}
+
+	numbers := packagistNumbers(version)
+
+	// branches (i.e. "dev-main") can only be matched exactly
+	if len(numbers) == 0 {
+		if !strings.HasPrefix(version, "dev-") || (operator != "" && operator != "==" && operator != "=") {
+			return nil, &ParseError{Token: c, Reason: "invalid constraint"}
+		}
+
+		return VersionInterval{constraint(OperatorEqual, version)}, nil
+	}
+
+	if prefix, isWildcard := strings.CutSuffix(version, ".*"); isWildcard {
+		if operator != "" || len(packagistNumbers(prefix)) == 0 {
+			return nil, &ParseError{Token: c, Reason: "invalid wildcard"}
+		}
+
+		return VersionInterval{
+			constraint(OperatorGreaterOrEqual, prefix),
+			constraint(OperatorLessThan, packagistUpperBound(numbers, len(numbers)-1)),
+		}, nil
+	}
+
+	switch operator {
+	case "~":
+		// the last specified component may change, but only if there are at least two
+		i := max(len(numbers)-2, 0)
+
+		return VersionInterval{
+			constraint(OperatorGreaterOrEqual, version),
+			constraint(OperatorLessThan, packagistUpperBound(numbers, i)),
+		}, nil
+	case "^":
+		// every component up to and including the first non-zero one must stay the same
+		i := len(numbers) - 1
+
+		for j, n := range numbers {
+			if strings.TrimLeft(n, "0") != "" {
+				i = j
+
+				break
+			}
+		}
+
+		return VersionInterval{
+			constraint(OperatorGreaterOrEqual, version),
+			constraint(OperatorLessThan, packagistUpperBound(numbers, i)),
+		}, nil
+	case "<>":
+		return VersionInterval{constraint(OperatorNotEqual, version)}, nil
+	case "", "==":
+		return VersionInterval{constraint(OperatorEqual, version)}, nil
+	}
+
+	return VersionInterval{constraint(operator, version)}, nil
+}
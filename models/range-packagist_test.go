@@ -0,0 +1,21 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestParseVersionRangePackagist(t *testing.T) {
+	expectRangeMembership(t, "Packagist", []rangeMembership{
+		{"^1.2", []string{"1.2.0", "1.9.9"}, []string{"2.0.0", "1.1.9"}},
+		{"~2.0.3", []string{"2.0.3", "2.0.9"}, []string{"2.1.0", "2.0.2"}},
+		{"~2.0", []string{"2.0.0", "2.9.0"}, []string{"3.0.0"}},
+		{">=1.0 <1.1 || >=1.2", []string{"1.0", "1.0.9", "1.2", "5.0"}, []string{"1.1", "0.9"}},
+		{"1.0 - 2.0", []string{"1.0.0", "2.0.9"}, []string{"2.1.0", "0.9.0"}},
+		{"1.0.*", []string{"1.0.0", "1.0.9"}, []string{"1.1.0"}},
+	})
+
+	expectRangeParseErrors(t, "Packagist", []string{
+		">=foo",
+		"^",
+	})
+}
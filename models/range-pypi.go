@@ -0,0 +1,99 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"strings"
+)
+
+// pypiPrefixBounds returns the lowest version that matches the prefix of a wildcard
+// (i.e. "1.4" of "==1.4.*") and the lowest version that comes after all of them
+func pypiPrefixBounds(prefix string) (string, string, bool) {
+	epoch := ""
+
+	if i := strings.Index(prefix, "!"); i != -1 {
+		epoch, prefix = prefix[:i+1], prefix[i+1:]
+	}
+
+	components := strings.Split(prefix, ".")
+	last := len(components) - 1
+
+	for _, c := range components {
+		if _, isNumber := convertToBigInt(c); !isNumber {
+			return "", "", false
+		}
+	}
+
+	next := append([]string{}, components...)
+	next[last] = incrementNumber(next[last])
+
+	return epoch + prefix + ".dev0", epoch + strings.Join(next, ".") + ".dev0", true
+}
+
+// parsePyPIRange parses a PEP 440 version specifier, i.e. ">=2.0,<2.17.1" or "~=1.4.5"
+//
+// See https://peps.python.org/pep-0440/#version-specifiers
+func parsePyPIRange(str string) ([]VersionInterval, error) {
+	intervals := []VersionInterval{{}}
+
+	for _, clause := range strings.Split(str, ",") {
+		clause = strings.TrimSpace(clause)
+
+		match := MustCompile(`^(~=|===|==|!=|<=|>=|<|>)\s*(\S+)$`).FindStringSubmatch(clause)
+		if len(match) == 0 {
+			return nil, &ParseError{Token: clause, Reason: "invalid version specifier"}
+		}
+
+		clauseIntervals, err := parsePyPIClause(match[1], match[2])
+		if err != nil {
+			return nil, err
+		}
+
+		intervals = intersectIntervals(intervals, clauseIntervals)
+	}
+
+	return intervals, nil
+}
+
+func parsePyPIClause(operator, version string) ([]VersionInterval, error) {
+	// prefix matching is only allowed for == and !=
+	if prefix, isPrefix := strings.CutSuffix(version, ".*"); isPrefix {
+		lower, upper, ok := pypiPrefixBounds(prefix)
+
+		if !ok || (operator != "==" && operator != OperatorNotEqual) {
+			return nil, &ParseError{Token: operator + version, Reason: "invalid prefix match"}
+		}
+
+		if operator == OperatorNotEqual {
+			return exclusionIntervals(lower, upper), nil
+		}
+
+		return []VersionInterval{{
+			constraint(OperatorGreaterOrEqual, lower),
+			constraint(OperatorLessThan, upper),
+		}}, nil
+	}
+
+	switch operator {
+	case "~=":
+		// a compatible release is at least the version, and matches
+		// the prefix of the version without its last component
+		release := MustCompile(`^(?:\d+!)?\d+(?:\.\d+)*`).FindString(version)
+
+		i := strings.LastIndex(release, ".")
+		if i == -1 {
+			return nil, &ParseError{Token: operator + version, Reason: "compatible release requires at least two components"}
+		}
+
+		_, upper, _ := pypiPrefixBounds(release[:i])
+
+		return []VersionInterval{{
+			constraint(OperatorGreaterOrEqual, version),
+			constraint(OperatorLessThan, upper),
+		}}, nil
+	case "==", "===":
+		return []VersionInterval{{constraint(OperatorEqual, version)}}, nil
+	}
+
+	return []VersionInterval{{constraint(operator, version)}}, nil
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestParseVersionRangePyPI(t *testing.T) {
+	expectRangeMembership(t, "PyPI", []rangeMembership{
+		{">=2.0,<2.17.1", []string{"2.0", "2.17.0"}, []string{"1.9", "2.17.1"}},
+		{"~=1.4.5", []string{"1.4.5", "1.4.9"}, []string{"1.5.0", "1.4.4"}},
+		{"~=2.2", []string{"2.2", "2.9"}, []string{"3.0", "2.1"}},
+		{"==1.4.*", []string{"1.4", "1.4.0", "1.4.99"}, []string{"1.5", "1.3.9"}},
+		{"!=1.5", []string{"1.4", "1.6"}, []string{"1.5", "1.5.0"}},
+		{"==1.0", []string{"1.0", "1.0.0"}, []string{"1.0.1"}},
+	})
+
+	expectRangeParseErrors(t, "PyPI", []string{
+		"~=1",
+		">=foo bar",
+		"=>1.0",
+	})
+}
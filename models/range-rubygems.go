@@ -0,0 +1,60 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"strings"
+)
+
+// rubyGemsBump returns the version that a pessimistic constraint must be below,
+// which is the version with its last release segment dropped and the one before
+// incremented, i.e. 6.2 for "~> 6.1.2" and 7 for "~> 6.1"
+func rubyGemsBump(version string) string {
+	numbers, _ := groupSegments(strings.Split(canonicalizeRubyGemVersion(version), "."))
+
+	if len(numbers) > 1 {
+		numbers = numbers[:len(numbers)-1]
+	}
+
+	if len(numbers) == 0 {
+		return "0"
+	}
+
+	numbers[len(numbers)-1] = incrementNumber(numbers[len(numbers)-1])
+
+	return strings.Join(numbers, ".")
+}
+
+// parseRubyGemsRange parses a comma separated list of RubyGems requirements, i.e. "~> 6.1, >= 6.1.7"
+//
+// See https://guides.rubygems.org/patterns/#declaring-dependencies
+func parseRubyGemsRange(str string) ([]VersionInterval, error) {
+	vi := VersionInterval{}
+
+	for _, requirement := range strings.Split(str, ",") {
+		requirement = strings.TrimSpace(requirement)
+
+		match := MustCompile(`^(=|!=|>=|<=|>|<|~>)?\s*(\d\S*)$`).FindStringSubmatch(requirement)
+		if len(match) == 0 {
+			return nil, &ParseError{Token: requirement, Reason: "invalid requirement"}
+		}
+
+		operator, version := match[1], match[2]
+
+		switch operator {
+		case "~>":
+			// the ".a" ensures prereleases of the bumped version are excluded,
+			// as "a" sorts before every other prerelease segment
+			vi = append(vi,
+				constraint(OperatorGreaterOrEqual, version),
+				constraint(OperatorLessThan, rubyGemsBump(version)+".a"),
+			)
+		case "":
+			vi = append(vi, constraint(OperatorEqual, version))
+		default:
+			vi = append(vi, constraint(operator, version))
+		}
+	}
+
+	return []VersionInterval{vi}, nil
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestParseVersionRangeRubyGems(t *testing.T) {
+	expectRangeMembership(t, "RubyGems", []rangeMembership{
+		{"~> 6.1", []string{"6.1", "6.9.9"}, []string{"7.0", "6.0"}},
+		{"~> 6.1.3", []string{"6.1.3", "6.1.9"}, []string{"6.2.0", "6.1.2"}},
+		{">= 1.0, < 2.0", []string{"1.0", "1.9"}, []string{"2.0", "0.9"}},
+		{"!= 1.5", []string{"1.4", "1.6"}, []string{"1.5"}},
+		{"= 1.2", []string{"1.2", "1.2.0"}, []string{"1.2.1"}},
+	})
+
+	expectRangeParseErrors(t, "RubyGems", []string{
+		"~~> 1.0",
+		">= 1.0, foo",
+	})
+}
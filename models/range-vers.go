@@ -0,0 +1,118 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// versSchemes maps the versioning scheme of a vers range to the ecosystem used to compare its versions
+var versSchemes = map[string]Ecosystem{
+	"alpine":   EcosystemAlpine,
+	"apk":      EcosystemAlpine,
+	"cargo":    EcosystemCratesIO,
+	"composer": EcosystemPackagist,
+	"conan":    EcosystemConanCenter,
+	"deb":      EcosystemDebian,
+	"gem":      EcosystemRubyGems,
+	"golang":   EcosystemGo,
+	"hex":      EcosystemHex,
+	"maven":    EcosystemMaven,
+	"npm":      EcosystemNPM,
+	"nuget":    EcosystemNuGet,
+	"pub":      EcosystemPub,
+	"pypi":     EcosystemPyPI,
+	"rpm":      EcosystemRockyLinux,
+	"semver":   EcosystemNPM,
+}
+
+// ParseVers parses a range written in the purl "vers:" syntax, i.e. "vers:npm/>=1.0.0|<2.0.0"
+//
+// See https://github.com/package-url/purl-spec/blob/master/VERSION-RANGE-SPEC.rst
+func ParseVers(str string) (VersionRange, error) {
+	vers := strings.ReplaceAll(strings.TrimSpace(str), " ", "")
+
+	scheme, constraints, found := strings.Cut(strings.TrimPrefix(vers, "vers:"), "/")
+	if !strings.HasPrefix(vers, "vers:") || !found {
+		return VersionRange{}, &ParseError{Input: str, Reason: `expected "vers:<scheme>/<constraints>"`}
+	}
+
+	ecosystem, ok := versSchemes[strings.ToLower(scheme)]
+	if !ok {
+		return VersionRange{}, &ParseError{Input: str, Token: scheme, Reason: "unsupported versioning scheme"}
+	}
+
+	if constraints == "*" {
+		return newVersionRange(str, ecosystem, []VersionInterval{{}})
+	}
+
+	var bounds, excluded []VersionConstraint
+
+	for _, c := range strings.Split(constraints, "|") {
+		match := MustCompile(`^(>=|<=|!=|<|>|=)?(.+)$`).FindStringSubmatch(c)
+		if len(match) == 0 {
+			return VersionRange{}, rangeError(&ParseError{Token: c, Reason: "empty constraint"}, str, ecosystem)
+		}
+
+		version, err := url.PathUnescape(match[2])
+		if err != nil {
+			return VersionRange{}, rangeError(&ParseError{Token: c, Reason: "invalid escape sequence"}, str, ecosystem)
+		}
+
+		operator := match[1]
+		if operator == "" {
+			operator = OperatorEqual
+		}
+
+		parsed, err := Parse(version, ecosystem)
+		if err != nil {
+			return VersionRange{}, rangeError(err, str, ecosystem)
+		}
+
+		if operator == OperatorNotEqual {
+			excluded = append(excluded, VersionConstraint{operator, version, parsed})
+		} else {
+			bounds = append(bounds, VersionConstraint{operator, version, parsed})
+		}
+	}
+
+	// constraints are meant to be in order already, but are sorted
+	// so that the bounds of each interval are next to each other
+	sort.SliceStable(bounds, func(i, j int) bool {
+		return bounds[i].parsed.Compare(bounds[j].parsed) < 0
+	})
+
+	var intervals []VersionInterval
+	var current VersionInterval
+
+	for _, c := range bounds {
+		switch c.Operator {
+		case OperatorEqual:
+			intervals = append(intervals, VersionInterval{c})
+		case OperatorGreaterThan, OperatorGreaterOrEqual:
+			if current == nil {
+				current = VersionInterval{c}
+			}
+		case OperatorLessThan, OperatorLessOrEqual:
+			intervals = append(intervals, append(current, c))
+			current = nil
+		}
+	}
+
+	if current != nil {
+		intervals = append(intervals, current)
+	}
+
+	// excluded versions apply to every interval, or to all versions if there are no bounds
+	if len(intervals) == 0 {
+		intervals = []VersionInterval{{}}
+	}
+
+	for i := range intervals {
+		intervals[i] = append(intervals[i], excluded...)
+	}
+
+	return VersionRange{Ecosystem: ecosystem, Original: str, Intervals: intervals}, nil
+}
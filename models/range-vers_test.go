@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestParseVers(t *testing.T) {
+	tests := []struct {
+		rng string
+		in  []string
+		out []string
+	}{
+		{"vers:npm/>=1.0.0|<2.0.0", []string{"1.0.0", "1.9.9"}, []string{"2.0.0", "0.9.9"}},
+		{"vers:pypi/<1.0|>=2.0", []string{"0.9", "2.0"}, []string{"1.0", "1.5"}},
+		{"vers:maven/1.0|1.2", []string{"1.0", "1.2"}, []string{"1.1"}},
+		{"vers:deb/>=1.0-1|<1.0-3", []string{"1.0-1", "1.0-2"}, []string{"1.0-3"}},
+		{"vers:npm/*", []string{"0.0.1", "5.0.0"}, nil},
+	}
+
+	for _, tt := range tests {
+		vr, err := ParseVers(tt.rng)
+		if err != nil {
+			t.Errorf("ParseVers(%q) returned %v", tt.rng, err)
+			continue
+		}
+
+		for _, v := range tt.in {
+			if ok, err := vr.ContainsStr(v); err != nil || !ok {
+				t.Errorf("%q ContainsStr(%q) = %v, %v, want true", tt.rng, v, ok, err)
+			}
+		}
+
+		for _, v := range tt.out {
+			if ok, err := vr.ContainsStr(v); err != nil || ok {
+				t.Errorf("%q ContainsStr(%q) = %v, %v, want false", tt.rng, v, ok, err)
+			}
+		}
+	}
+
+	for _, str := range []string{"npm/>=1.0.0", "vers:unknown/1.0", "vers:npm/>=1.0.0||<2.0.0"} {
+		if _, err := ParseVers(str); err == nil {
+			t.Errorf("ParseVers(%q) returned no error", str)
+		}
+	}
+}
@@ -0,0 +1,414 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// ErrUnrepresentableRange defines the error for a VersionRange that cannot be expressed as OSV events
+var ErrUnrepresentableRange = errors.New("range cannot be represented as OSV events")
+
+// Constants defining the operators used by a VersionConstraint
+const (
+	OperatorEqual          = "="
+	OperatorNotEqual       = "!="
+	OperatorLessThan       = "<"
+	OperatorLessOrEqual    = "<="
+	OperatorGreaterThan    = ">"
+	OperatorGreaterOrEqual = ">="
+)
+
+// VersionConstraint defines a single comparison that a version must satisfy, such as >=1.2.3
+type VersionConstraint struct {
+	Operator string `json:"operator"`
+	Version  string `json:"version"`
+	parsed   Version
+}
+
+// String returns the constraint as the operator followed by the version
+func (c VersionConstraint) String() string {
+	return c.Operator + c.Version
+}
+
+// compareTo returns the sort order of the given version relative to the version of the constraint,
+// which is only parsed beforehand if the constraint was created by a parser
+func (c VersionConstraint) compareTo(v Version) int {
+	if c.parsed == nil {
+		return v.CompareStr(c.Version)
+	}
+
+	return v.Compare(c.parsed)
+}
+
+// satisfiedBy checks if the given version satisfies the constraint
+func (c VersionConstraint) satisfiedBy(v Version) bool {
+	diff := c.compareTo(v)
+
+	switch c.Operator {
+	case OperatorEqual:
+		return diff == 0
+	case OperatorNotEqual:
+		return diff != 0
+	case OperatorLessThan:
+		return diff < 0
+	case OperatorLessOrEqual:
+		return diff <= 0
+	case OperatorGreaterThan:
+		return diff > 0
+	case OperatorGreaterOrEqual:
+		return diff >= 0
+	}
+
+	return false
+}
+
+func (c VersionConstraint) isLowerBound() bool {
+	return c.Operator == OperatorGreaterThan || c.Operator == OperatorGreaterOrEqual || c.Operator == OperatorEqual
+}
+
+func (c VersionConstraint) isUpperBound() bool {
+	return c.Operator == OperatorLessThan || c.Operator == OperatorLessOrEqual || c.Operator == OperatorEqual
+}
+
+// VersionInterval defines a set of constraints that a version must all satisfy,
+// where an empty interval is satisfied by every version
+type VersionInterval []VersionConstraint
+
+// String returns the constraints of the interval separated by spaces
+func (vi VersionInterval) String() string {
+	if len(vi) == 0 {
+		return "*"
+	}
+
+	constraints := make([]string, 0, len(vi))
+
+	for _, c := range vi {
+		constraints = append(constraints, c.String())
+	}
+
+	return strings.Join(constraints, " ")
+}
+
+// Contains checks if the given version satisfies every constraint of the interval
+func (vi VersionInterval) Contains(v Version) bool {
+	for _, c := range vi {
+		if !c.satisfiedBy(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// bounds returns the highest lower bound and the lowest upper bound of the interval
+func (vi VersionInterval) bounds() (lower *VersionConstraint, upper *VersionConstraint) {
+	for i := range vi {
+		c := &vi[i]
+
+		if c.isLowerBound() {
+			if lower == nil || c.parsed.Compare(lower.parsed) > 0 ||
+				(c.parsed.Compare(lower.parsed) == 0 && c.Operator == OperatorGreaterThan) {
+				lower = c
+			}
+		}
+
+		if c.isUpperBound() {
+			if upper == nil || c.parsed.Compare(upper.parsed) < 0 ||
+				(c.parsed.Compare(upper.parsed) == 0 && c.Operator == OperatorLessThan) {
+				upper = c
+			}
+		}
+	}
+
+	return lower, upper
+}
+
+// VersionRange defines a set of versions for an ecosystem, expressed as a union
+// of intervals so that ranges written in any of the native constraint syntaxes
+// (i.e. "^4.17.0", "[1.0,2.0)", ">=2.0,<2.17.1" or "~> 6.1") share a single form.
+type VersionRange struct {
+	Ecosystem Ecosystem         `json:"ecosystem"`
+	Original  string            `json:"original,omitempty"`
+	Intervals []VersionInterval `json:"intervals"`
+}
+
+// String returns the intervals of the range separated by "||"
+func (vr VersionRange) String() string {
+	if len(vr.Intervals) == 0 {
+		return ""
+	}
+
+	intervals := make([]string, 0, len(vr.Intervals))
+
+	for _, vi := range vr.Intervals {
+		intervals = append(intervals, vi.String())
+	}
+
+	return strings.Join(intervals, " || ")
+}
+
+// Contains checks if the given version is within any of the intervals of the range
+func (vr VersionRange) Contains(v Version) bool {
+	for _, vi := range vr.Intervals {
+		if vi.Contains(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ContainsStr parses the given version string for the ecosystem
+// of the range and checks if it is within the range
+func (vr VersionRange) ContainsStr(str string) (bool, error) {
+	v, err := Parse(str, vr.Ecosystem)
+	if err != nil {
+		return false, err
+	}
+
+	return vr.Contains(v), nil
+}
+
+// ToOSV converts the range into an "ECOSYSTEM" OSV Range, returning an
+// ErrUnrepresentableRange if the range uses constraints that cannot be expressed
+// as events, such as exclusive lower bounds or excluded versions
+func (vr VersionRange) ToOSV() (Range, error) {
+	type bounded struct {
+		lower *VersionConstraint
+		upper *VersionConstraint
+	}
+
+	// ranges that were not created by a parser (i.e. unmarshalled from JSON) are yet to have their versions parsed
+	vr, err := newVersionRange(vr.Original, vr.Ecosystem, vr.Intervals)
+	if err != nil {
+		return Range{}, err
+	}
+
+	intervals := make([]bounded, 0, len(vr.Intervals))
+
+	for _, vi := range vr.Intervals {
+		for _, c := range vi {
+			if c.Operator == OperatorNotEqual {
+				return Range{}, fmt.Errorf("%w: %s excludes a version", ErrUnrepresentableRange, vi)
+			}
+		}
+
+		lower, upper := vi.bounds()
+
+		if lower != nil && lower.Operator == OperatorGreaterThan {
+			return Range{}, fmt.Errorf("%w: %s has an exclusive lower bound", ErrUnrepresentableRange, vi)
+		}
+
+		intervals = append(intervals, bounded{lower, upper})
+	}
+
+	// the events of each interval need to be in order for them to be evaluated correctly
+	sort.SliceStable(intervals, func(i, j int) bool {
+		if intervals[j].lower == nil {
+			return false
+		}
+		if intervals[i].lower == nil {
+			return true
+		}
+
+		return intervals[i].lower.parsed.Compare(intervals[j].lower.parsed) < 0
+	})
+
+	r := Range{Type: RangeEcosystem, Events: []Event{}}
+
+	for _, b := range intervals {
+		if b.lower == nil {
+			r.Events = append(r.Events, Event{Introduced: "0"})
+		} else {
+			r.Events = append(r.Events, Event{Introduced: b.lower.Version})
+		}
+
+		if b.upper == nil {
+			continue
+		}
+
+		if b.upper.Operator == OperatorLessThan {
+			r.Events = append(r.Events, Event{Fixed: b.upper.Version})
+		} else {
+			r.Events = append(r.Events, Event{LastAffected: b.upper.Version})
+		}
+	}
+
+	return r, nil
+}
+
+// VersionRangeFromOSV converts the events of an "ECOSYSTEM" or "SEMVER" OSV Range into a VersionRange
+func VersionRangeFromOSV(r Range, ecosystem Ecosystem) (VersionRange, error) {
+	vr := VersionRange{Ecosystem: ecosystem, Intervals: []VersionInterval{}}
+
+	if r.Type != RangeEcosystem && r.Type != RangeSemVer {
+		return vr, fmt.Errorf("%w: %s ranges are not version based", ErrUnrepresentableRange, r.Type)
+	}
+
+	events, err := sortEvents(r.Events, ecosystem)
+	if err != nil {
+		return vr, err
+	}
+
+	var current VersionInterval
+	var limit *VersionConstraint
+
+	open := false
+
+	for _, e := range events {
+		switch {
+		case e.Introduced != "":
+			if open {
+				continue
+			}
+
+			open = true
+			current = VersionInterval{}
+
+			if e.Introduced != "0" {
+				current = append(current, VersionConstraint{OperatorGreaterOrEqual, e.Introduced, e.version})
+			}
+		case e.Fixed != "":
+			if open {
+				vr.Intervals = append(vr.Intervals, append(current, VersionConstraint{OperatorLessThan, e.Fixed, e.version}))
+				open = false
+			}
+		case e.LastAffected != "":
+			if open {
+				vr.Intervals = append(vr.Intervals, append(current, VersionConstraint{OperatorLessOrEqual, e.LastAffected, e.version}))
+				open = false
+			}
+		case e.Limit != "" && e.Limit != "*":
+			// versions only need to be below one of the limits, so the highest is used
+			limit = &VersionConstraint{OperatorLessThan, e.Limit, e.version}
+		}
+	}
+
+	if open {
+		vr.Intervals = append(vr.Intervals, current)
+	}
+
+	if limit != nil {
+		for i := range vr.Intervals {
+			vr.Intervals[i] = append(vr.Intervals[i], *limit)
+		}
+	}
+
+	return vr, nil
+}
+
+// ParseVersionRange parses a range written in the native constraint syntax of the ecosystem,
+// or in the purl "vers:" syntax if it starts with "vers:"
+func ParseVersionRange(str string, ecosystem Ecosystem) (VersionRange, error) {
+	if strings.HasPrefix(strings.TrimSpace(str), "vers:") {
+		return ParseVers(str)
+	}
+
+	var intervals []VersionInterval
+	var err error
+
+	//nolint:exhaustive // Using strings to specify ecosystem instead of lockfile types
+	switch ecosystem {
+	case "npm":
+		intervals, err = parseNpmRange(str)
+	case "Maven":
+		intervals, err = parseMavenRange(str)
+	case "NuGet":
+		intervals, err = parseNuGetRange(str)
+	case "PyPI":
+		intervals, err = parsePyPIRange(str)
+	case "RubyGems":
+		intervals, err = parseRubyGemsRange(str)
+	case "Packagist":
+		intervals, err = parsePackagistRange(str)
+	default:
+		return VersionRange{}, fmt.Errorf("%w %s", ErrUnsupportedEcosystem, ecosystem)
+	}
+
+	if err != nil {
+		return VersionRange{}, rangeError(err, str, ecosystem)
+	}
+
+	return newVersionRange(str, ecosystem, intervals)
+}
+
+// newVersionRange parses the version of each constraint so that the range can be compared against
+func newVersionRange(str string, ecosystem Ecosystem, intervals []VersionInterval) (VersionRange, error) {
+	var err error
+
+	for _, vi := range intervals {
+		for i := range vi {
+			if vi[i].parsed, err = Parse(vi[i].Version, ecosystem); err != nil {
+				return VersionRange{}, rangeError(err, str, ecosystem)
+			}
+		}
+	}
+
+	return VersionRange{Ecosystem: ecosystem, Original: str, Intervals: intervals}, nil
+}
+
+// rangeError converts an error from parsing part of a range into a ParseError for the whole range
+func rangeError(err error, str string, ecosystem Ecosystem) error {
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		return err
+	}
+
+	token := perr.Token
+	if token == "" {
+		token = perr.Input
+	}
+
+	reason := perr.Reason
+	if perr.Input != "" && perr.Input != str && perr.Input != token {
+		reason = fmt.Sprintf("%s in %q", reason, perr.Input)
+	}
+
+	return &ParseError{Ecosystem: ecosystem, Input: str, Token: token, Reason: reason}
+}
+
+// intersectIntervals returns the intervals of versions that are within both a and b
+func intersectIntervals(a, b []VersionInterval) []VersionInterval {
+	intervals := make([]VersionInterval, 0, len(a)*len(b))
+
+	for _, ai := range a {
+		for _, bi := range b {
+			vi := make(VersionInterval, 0, len(ai)+len(bi))
+			vi = append(vi, ai...)
+			vi = append(vi, bi...)
+
+			intervals = append(intervals, vi)
+		}
+	}
+
+	return intervals
+}
+
+// exclusionIntervals returns the intervals of versions that are outside of the given
+// inclusive lower and exclusive upper bounds, used for excluding a wildcard such as "!=1.2.*"
+func exclusionIntervals(lower, upper string) []VersionInterval {
+	return []VersionInterval{
+		{constraint(OperatorLessThan, lower)},
+		{constraint(OperatorGreaterOrEqual, upper)},
+	}
+}
+
+// incrementNumber increments a string of digits by one
+func incrementNumber(str string) string {
+	n, ok := convertToBigInt(str)
+	if !ok {
+		return str
+	}
+
+	return n.Add(n, big.NewInt(1)).String()
+}
+
+// constraint returns a VersionConstraint with the given operator and version
+func constraint(operator, version string) VersionConstraint {
+	return VersionConstraint{Operator: operator, Version: version}
+}
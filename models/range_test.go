@@ -0,0 +1,86 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+// rangeMembership is a range along with versions that are within it and versions that are not
+type rangeMembership struct {
+	rng string
+	in  []string
+	out []string
+}
+
+// expectRangeMembership checks that each range parses for the ecosystem and contains exactly the expected versions
+func expectRangeMembership(t *testing.T, ecosystem Ecosystem, ranges []rangeMembership) {
+	t.Helper()
+
+	for _, tt := range ranges {
+		vr, err := ParseVersionRange(tt.rng, ecosystem)
+		if err != nil {
+			t.Errorf("ParseVersionRange(%q, %s) returned %v", tt.rng, ecosystem, err)
+			continue
+		}
+
+		for _, v := range tt.in {
+			if ok, err := vr.ContainsStr(v); err != nil || !ok {
+				t.Errorf("%q (%s) ContainsStr(%q) = %v, %v, want true", tt.rng, vr, v, ok, err)
+			}
+		}
+
+		for _, v := range tt.out {
+			if ok, err := vr.ContainsStr(v); err != nil || ok {
+				t.Errorf("%q (%s) ContainsStr(%q) = %v, %v, want false", tt.rng, vr, v, ok, err)
+			}
+		}
+	}
+}
+
+// expectRangeParseErrors checks that each range fails to parse for the ecosystem with a *ParseError for the whole range
+func expectRangeParseErrors(t *testing.T, ecosystem Ecosystem, ranges []string) {
+	t.Helper()
+
+	for _, str := range ranges {
+		_, err := ParseVersionRange(str, ecosystem)
+
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("ParseVersionRange(%q, %s) returned %v, want a *ParseError", str, ecosystem, err)
+			continue
+		}
+
+		if perr.Input != str {
+			t.Errorf("ParseVersionRange(%q, %s) returned an error for %q", str, ecosystem, perr.Input)
+		}
+	}
+}
+
+func TestVersionRangeToOSV(t *testing.T) {
+	vr, err := ParseVersionRange(">=1.0.0 <1.2.0 || >=2.0.0 <2.0.5", "npm")
+	if err != nil {
+		t.Fatalf("ParseVersionRange returned %v", err)
+	}
+
+	r, err := vr.ToOSV()
+	if err != nil {
+		t.Fatalf("ToOSV returned %v", err)
+	}
+
+	back, err := VersionRangeFromOSV(r, "npm")
+	if err != nil {
+		t.Fatalf("VersionRangeFromOSV returned %v", err)
+	}
+
+	for _, v := range []string{"0.9.0", "1.0.0", "1.1.9", "1.2.0", "1.9.0", "2.0.0", "2.0.4", "2.0.5"} {
+		want, _ := vr.ContainsStr(v)
+
+		if got, _ := back.ContainsStr(v); got != want {
+			t.Errorf("round trip through %+v ContainsStr(%q) = %v, want %v", r, v, got, want)
+		}
+
+		if got, _ := RangeContains(r, PackageDetails{Name: "pkg", Version: v, Ecosystem: "npm", CompareAs: "npm"}); got != want {
+			t.Errorf("RangeContains(%+v, %q) = %v, want %v", r, v, got, want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"sort"
+)
+
+// Remediation defines the upgrade that clears the vulnerabilities affecting a package
+type Remediation struct {
+	// FixedIn is the lowest version that clears each vulnerability, keyed by its ID
+	// or by the primary ID of the group when remediating groups of vulnerabilities
+	FixedIn map[string]string `json:"fixed_in"`
+	// RecommendedVersion is the lowest version that clears every vulnerability,
+	// preferring one with the same major version as the package
+	RecommendedVersion string `json:"recommended_version,omitempty"`
+	// SameMajor is true if the recommended version has the same major version as the package
+	SameMajor bool `json:"same_major"`
+	// Unfixed lists the IDs of the vulnerabilities (or groups) that no known version clears
+	Unfixed []string `json:"unfixed,omitempty"`
+}
+
+// HasFix checks if there is a version that clears every vulnerability
+func (r Remediation) HasFix() bool {
+	return r.RecommendedVersion != ""
+}
+
+// fixCandidates returns the versions after the given version that one of the vulnerabilities
+// is fixed in, sorted lowest first, as these are the only versions known to exist
+func fixCandidates(current Version, pkg PackageDetails, vulns []Vulnerability) []Version {
+	var candidates []Version
+
+	seen := make(map[string]bool)
+
+	for _, v := range vulns {
+		for _, affected := range v.Affected {
+			if !affectsPackage(affected, pkg) {
+				continue
+			}
+
+			for _, r := range affected.Ranges {
+				if r.Type != RangeEcosystem && r.Type != RangeSemVer {
+					continue
+				}
+
+				for _, e := range r.Events {
+					if e.Fixed == "" {
+						continue
+					}
+
+					fixed, err := Parse(e.Fixed, pkg.CompareAs)
+					if err != nil || fixed.Compare(current) <= 0 || seen[fixed.Canonical()] {
+						continue
+					}
+
+					seen[fixed.Canonical()] = true
+					candidates = append(candidates, fixed)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Compare(candidates[j]) < 0
+	})
+
+	return candidates
+}
+
+// clears checks if upgrading the package to the version would clear the vulnerabilities,
+// where a vulnerability that cannot be evaluated for the version is not considered cleared
+func clears(version Version, pkg PackageDetails, vulns ...Vulnerability) bool {
+	pkg.Version = version.String()
+	pkg.Commit = ""
+
+	for _, v := range vulns {
+		if isAffected, err := IsAffected(v, pkg); isAffected || err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Remediate computes the lowest version of the package that clears each of the
+// vulnerabilities, along with the lowest version that clears all of them.
+//
+// The versions considered are those that the vulnerabilities are fixed in, so
+// that "last_affected" events are honored by checking that a candidate is after
+// them, but a vulnerability with only "last_affected" events is left unfixed
+// unless another is fixed in a later version. Stable versions are recommended
+// over prereleases unless the package is a prerelease itself, and then those
+// with the same major version as the package are preferred.
+func Remediate(pkg PackageDetails, vulns []Vulnerability) (Remediation, error) {
+	groups := make([]VulnerabilityGroup, 0, len(vulns))
+
+	for _, v := range vulns {
+		groups = append(groups, VulnerabilityGroup{ID: v.ID, Vulnerabilities: []Vulnerability{v}})
+	}
+
+	return RemediateGroups(pkg, groups)
+}
+
+// RemediateGroups computes the remediation of the package in the same way as Remediate,
+// except that each group is only cleared by a version that clears all of its records
+func RemediateGroups(pkg PackageDetails, groups []VulnerabilityGroup) (Remediation, error) {
+	r := Remediation{FixedIn: make(map[string]string)}
+
+	if pkg.Version == "" {
+		for _, g := range groups {
+			r.Unfixed = append(r.Unfixed, g.ID)
+		}
+
+		return r, nil
+	}
+
+	current, err := Parse(pkg.Version, pkg.CompareAs)
+	if err != nil {
+		return r, err
+	}
+
+	var vulns []Vulnerability
+
+	for _, g := range groups {
+		vulns = append(vulns, g.Vulnerabilities...)
+	}
+
+	candidates := fixCandidates(current, pkg, vulns)
+
+	for _, g := range groups {
+		for _, c := range candidates {
+			if clears(c, pkg, g.Vulnerabilities...) {
+				r.FixedIn[g.ID] = c.String()
+
+				break
+			}
+		}
+
+		if r.FixedIn[g.ID] == "" {
+			r.Unfixed = append(r.Unfixed, g.ID)
+		}
+	}
+
+	sameMajor := func(c Version) bool {
+		return c.Release().Major().Cmp(current.Release().Major()) == 0
+	}
+	stable := func(c Version) bool {
+		return current.IsPrerelease() || !c.IsPrerelease()
+	}
+
+	preferences := []func(c Version) bool{
+		func(c Version) bool { return stable(c) && sameMajor(c) },
+		stable,
+		sameMajor,
+		func(Version) bool { return true },
+	}
+
+	for _, prefer := range preferences {
+		for _, c := range candidates {
+			if prefer(c) && clears(c, pkg, vulns...) {
+				r.RecommendedVersion = c.String()
+				r.SameMajor = sameMajor(c)
+
+				return r, nil
+			}
+		}
+	}
+
+	return r, nil
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"testing"
+)
+
+// rangesVulnerability returns a vulnerability of the package with an ECOSYSTEM range for each list of events
+func rangesVulnerability(id string, ecosystem Ecosystem, name string, ranges ...[]Event) Vulnerability {
+	affected := Affected{Package: Package{Ecosystem: ecosystem, Name: name}}
+
+	for _, events := range ranges {
+		affected.Ranges = append(affected.Ranges, Range{Type: RangeEcosystem, Events: events})
+	}
+
+	return Vulnerability{ID: id, Affected: []Affected{affected}}
+}
+
+func TestRemediate(t *testing.T) {
+	tests := []struct {
+		name        string
+		pkg         PackageDetails
+		vulns       []Vulnerability
+		fixedIn     map[string]string
+		recommended string
+		sameMajor   bool
+		unfixed     []string
+	}{
+		{
+			name: "lowest version that fixes every vulnerability",
+			pkg:  PackageDetails{Name: "lodash", Version: "1.2.0", Ecosystem: "npm", CompareAs: "npm"},
+			vulns: []Vulnerability{
+				ecosystemVulnerability("A", "npm", "lodash", "0", "1.2.5"),
+				ecosystemVulnerability("B", "npm", "lodash", "1.0.0", "1.3.0"),
+			},
+			fixedIn:     map[string]string{"A": "1.2.5", "B": "1.3.0"},
+			recommended: "1.3.0",
+			sameMajor:   true,
+		},
+		{
+			name: "backported fix in the same major version",
+			pkg:  PackageDetails{Name: "lodash", Version: "1.3.0", Ecosystem: "npm", CompareAs: "npm"},
+			vulns: []Vulnerability{
+				rangesVulnerability("A", "npm", "lodash",
+					[]Event{{Introduced: "0"}, {Fixed: "1.4.1"}},
+					[]Event{{Introduced: "2.0.0"}, {Fixed: "2.0.1"}},
+				),
+			},
+			fixedIn:     map[string]string{"A": "1.4.1"},
+			recommended: "1.4.1",
+			sameMajor:   true,
+		},
+		{
+			name: "fix only in the next major version",
+			pkg:  PackageDetails{Name: "lodash", Version: "1.3.0", Ecosystem: "npm", CompareAs: "npm"},
+			vulns: []Vulnerability{
+				ecosystemVulnerability("A", "npm", "lodash", "0", "2.0.0"),
+			},
+			fixedIn:     map[string]string{"A": "2.0.0"},
+			recommended: "2.0.0",
+			sameMajor:   false,
+		},
+		{
+			name: "only a prerelease fixes every vulnerability",
+			pkg:  PackageDetails{Name: "lodash", Version: "1.0.0", Ecosystem: "npm", CompareAs: "npm"},
+			vulns: []Vulnerability{
+				ecosystemVulnerability("A", "npm", "lodash", "0", "1.5.0-beta.1"),
+				ecosystemVulnerability("B", "npm", "lodash", "0", "1.4.0"),
+			},
+			fixedIn:     map[string]string{"A": "1.5.0-beta.1", "B": "1.4.0"},
+			recommended: "1.5.0-beta.1",
+			sameMajor:   true,
+		},
+		{
+			name: "trailing zeros of maven versions",
+			pkg:  PackageDetails{Name: "org.example:lib", Version: "1.0.0", Ecosystem: "Maven", CompareAs: "Maven"},
+			vulns: []Vulnerability{
+				rangesVulnerability("A", "Maven", "org.example:lib",
+					[]Event{{Introduced: "0"}, {Fixed: "1.5"}},
+					[]Event{{Introduced: "2.0"}, {Fixed: "2.3"}},
+				),
+			},
+			fixedIn:     map[string]string{"A": "1.5"},
+			recommended: "1.5",
+			sameMajor:   true,
+		},
+		{
+			name: "only last affected",
+			pkg:  PackageDetails{Name: "requests", Version: "2.0", Ecosystem: "PyPI", CompareAs: "PyPI"},
+			vulns: []Vulnerability{
+				{ID: "A", Affected: []Affected{{
+					Package: Package{Ecosystem: "PyPI", Name: "requests"},
+					Ranges:  []Range{{Type: RangeEcosystem, Events: []Event{{Introduced: "0"}, {LastAffected: "2.5"}}}},
+				}}},
+			},
+			fixedIn: map[string]string{},
+			unfixed: []string{"A"},
+		},
+	}
+
+	for _, tt := range tests {
+		r, err := Remediate(tt.pkg, tt.vulns)
+		if err != nil {
+			t.Errorf("%s: Remediate returned %v", tt.name, err)
+			continue
+		}
+
+		if len(r.FixedIn) != len(tt.fixedIn) {
+			t.Errorf("%s: FixedIn = %v, want %v", tt.name, r.FixedIn, tt.fixedIn)
+		}
+
+		for id, want := range tt.fixedIn {
+			if r.FixedIn[id] != want {
+				t.Errorf("%s: FixedIn[%s] = %q, want %q", tt.name, id, r.FixedIn[id], want)
+			}
+		}
+
+		if r.RecommendedVersion != tt.recommended || r.SameMajor != tt.sameMajor {
+			t.Errorf("%s: recommended %q (same major %v), want %q (same major %v)", tt.name, r.RecommendedVersion, r.SameMajor, tt.recommended, tt.sameMajor)
+		}
+
+		if len(r.Unfixed) != len(tt.unfixed) {
+			t.Errorf("%s: Unfixed = %v, want %v", tt.name, r.Unfixed, tt.unfixed)
+		}
+	}
+}
+
+func TestRemediateInvalidVersion(t *testing.T) {
+	pkg := PackageDetails{Name: "lodash", Version: "not-a-version", Ecosystem: "npm", CompareAs: "npm"}
+
+	if _, err := Remediate(pkg, []Vulnerability{ecosystemVulnerability("A", "npm", "lodash", "0", "1.0.0")}); err == nil {
+		t.Errorf("Remediate of an invalid version returned no error")
+	}
+}
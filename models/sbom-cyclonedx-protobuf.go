@@ -0,0 +1,359 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	cyclonedx "github.com/CycloneDX/cyclonedx-go"
+	"github.com/ortelius/scec-deppkg/models/cdxpb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// cdxProtoFields maps the fields of the CycloneDX protobuf messages whose JSON names are not the camel case
+// of their protobuf names, by the name of the field or by the name of the message and field
+//
+//nolint:gochecknoglobals // this is read-only and the nicest implementation
+var cdxProtoFields = map[string]string{
+	"bom_ref":                        "bom-ref",
+	"mime_type":                      "mime-type",
+	"x_trust_boundary":               "x-trust-boundary",
+	"AttachedText.value":             "content",
+	"Hash.value":                     "content",
+	"DataFlow.value":                 "classification",
+	"Dependency.dependencies":        "dependsOn",
+	"GraphicsCollection.graphic":     "collection",
+	"PostalAddressType.postalCodeue": "postalCode",
+}
+
+// cdxProtoEnums maps the values of the CycloneDX protobuf enums whose JSON values are not the lower case of
+// their names, with hyphens for underscores, once the prefix that all the values of the enum share is removed
+//
+//nolint:gochecknoglobals // this is read-only and the nicest implementation
+var cdxProtoEnums = map[string]string{
+	"HASH_ALG_MD_5":                                     "MD5",
+	"HASH_ALG_SHA_1":                                    "SHA-1",
+	"HASH_ALG_SHA_256":                                  "SHA-256",
+	"HASH_ALG_SHA_384":                                  "SHA-384",
+	"HASH_ALG_SHA_512":                                  "SHA-512",
+	"HASH_ALG_SHA_3_256":                                "SHA3-256",
+	"HASH_ALG_SHA_3_384":                                "SHA3-384",
+	"HASH_ALG_SHA_3_512":                                "SHA3-512",
+	"HASH_ALG_BLAKE_2_B_256":                            "BLAKE2b-256",
+	"HASH_ALG_BLAKE_2_B_384":                            "BLAKE2b-384",
+	"HASH_ALG_BLAKE_2_B_512":                            "BLAKE2b-512",
+	"HASH_ALG_BLAKE_3":                                  "BLAKE3",
+	"SCORE_METHOD_CVSSV2":                               "CVSSv2",
+	"SCORE_METHOD_CVSSV3":                               "CVSSv3",
+	"SCORE_METHOD_CVSSV31":                              "CVSSv31",
+	"SCORE_METHOD_CVSSV4":                               "CVSSv4",
+	"SCORE_METHOD_OWASP":                                "OWASP",
+	"SCORE_METHOD_SSVC":                                 "SSVC",
+	"VULNERABILITY_AFFECTED_STATUS_NOT_AFFECTED":        "unaffected",
+	"EVIDENCE_FIELD_OMNIBOR_ID":                         "omniborId",
+	"MODEL_PARAMETER_APPROACH_TYPE_REINFORCED_LEARNING": "reinforcement-learning",
+	"ENERGY_MEASURE_UNIT_TYPE_KILOWATT_HOURS":           "kWh",
+	"CO2_MEASURE_UNIT_TYPE_TONNES_CO2_EQUIVALENT":       "tCO2eq",
+	"CRYPTO_IMPLEMENTATION_PLATFORM_X86_32":             "x86_32",
+	"CRYPTO_IMPLEMENTATION_PLATFORM_X86_64":             "x86_64",
+	"CRYPTO_IMPLEMENTATION_PLATFORM_ARMV7A":             "armv7-a",
+	"CRYPTO_IMPLEMENTATION_PLATFORM_ARMV7M":             "armv7-m",
+	"CRYPTO_IMPLEMENTATION_PLATFORM_ARMV8A":             "armv8-a",
+	"CRYPTO_IMPLEMENTATION_PLATFORM_ARMV8M":             "armv8-m",
+	"CRYPTO_IMPLEMENTATION_PLATFORM_ARMV9A":             "armv9-a",
+	"CRYPTO_IMPLEMENTATION_PLATFORM_ARMV9M":             "armv9-m",
+	"CRYPTO_IMPLEMENTATION_PLATFORM_X390X":              "s390x",
+}
+
+// cdxProtoSnakeEnums defines the CycloneDX protobuf enums whose JSON values keep their underscores, i.e. "not_affected"
+//
+//nolint:gochecknoglobals // this is read-only and the nicest implementation
+var cdxProtoSnakeEnums = map[string]bool{
+	"Aggregate":                   true,
+	"ImpactAnalysisState":         true,
+	"ImpactAnalysisJustification": true,
+	"VulnerabilityResponse":       true,
+}
+
+// cdxProtoEnumPrefix returns the prefix that all the values of the enum share, i.e. "CLASSIFICATION_"
+func cdxProtoEnumPrefix(ed protoreflect.EnumDescriptor) string {
+	values := ed.Values()
+	words := strings.Split(string(values.Get(0).Name()), "_")
+
+	for n := len(words) - 1; n > 0; n-- {
+		prefix := strings.Join(words[:n], "_") + "_"
+		shared := true
+
+		for i := 1; i < values.Len() && shared; i++ {
+			shared = strings.HasPrefix(string(values.Get(i).Name()), prefix)
+		}
+
+		if shared {
+			return prefix
+		}
+	}
+
+	return ""
+}
+
+// cdxProtoEnum returns the JSON value of an enum value, or "" for the zero value that stands for no value
+func cdxProtoEnum(ed protoreflect.EnumDescriptor, number protoreflect.EnumNumber) string {
+	value := ed.Values().ByNumber(number)
+	if value == nil {
+		return "" // a value of a newer spec
+	}
+
+	if name, ok := cdxProtoEnums[string(value.Name())]; ok {
+		return name
+	}
+
+	name := strings.TrimPrefix(string(value.Name()), cdxProtoEnumPrefix(ed))
+
+	if number == 0 && (name == "NULL" || name == "UNSPECIFIED") {
+		return ""
+	}
+
+	if cdxProtoSnakeEnums[string(ed.Name())] {
+		return strings.ToLower(name)
+	}
+
+	return strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+}
+
+// cdxProtoFieldName returns the JSON name of a field of a CycloneDX protobuf message
+func cdxProtoFieldName(md protoreflect.MessageDescriptor, fd protoreflect.FieldDescriptor) string {
+	if name, ok := cdxProtoFields[string(md.Name())+"."+string(fd.Name())]; ok {
+		return name
+	}
+
+	if name, ok := cdxProtoFields[string(fd.Name())]; ok {
+		return name
+	}
+
+	return fd.JSONName()
+}
+
+// cdxProtoValue returns the CycloneDX JSON value of the protojson value of a field of a CycloneDX protobuf message
+func cdxProtoValue(fd protoreflect.FieldDescriptor, value interface{}) interface{} {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return cdxProtoJSON(fd.Message(), value)
+	case protoreflect.EnumKind:
+		// protojson writes the name of the enum value, or its number for a value of a newer spec
+		name, _ := value.(string)
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(name)); ev != nil {
+			return cdxProtoEnum(fd.Enum(), ev.Number())
+		}
+
+		return ""
+	default:
+		return value
+	}
+}
+
+// cdxProtoJSON rewrites the protojson form of a CycloneDX protobuf message into its CycloneDX JSON form, where
+// the fields and enum values are renamed, the dependencies of a dependency are written as the refs that it
+// depends on and the legacy fields of the tools as a list of tools
+func cdxProtoJSON(md protoreflect.MessageDescriptor, value interface{}) interface{} {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return value // a well known type such as a timestamp, which protojson writes as a string
+	}
+
+	fields := md.Fields()
+
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+
+		v, found := obj[fd.JSONName()]
+		delete(obj, fd.JSONName())
+
+		// an enum without presence, i.e. the type of an external reference, is written even when it is the zero value
+		if !found && fd.Kind() == protoreflect.EnumKind && !fd.HasPresence() && !fd.IsList() {
+			v, found = string(fd.Enum().Values().ByNumber(0).Name()), true
+		}
+
+		if !found {
+			continue
+		}
+
+		name := cdxProtoFieldName(md, fd)
+
+		list, isList := v.([]interface{})
+		if !fd.IsList() || !isList {
+			if value := cdxProtoValue(fd, v); value != "" {
+				obj[name] = value
+			}
+
+			continue
+		}
+
+		values := make([]interface{}, 0, len(list))
+
+		for _, item := range list {
+			if dependency, ok := item.(map[string]interface{}); ok && name == "dependsOn" {
+				values = append(values, dependency["ref"])
+			} else {
+				values = append(values, cdxProtoValue(fd, item))
+			}
+		}
+
+		// the declarations and definitions of a bom are a list in protobuf but an object in JSON
+		if md.Name() == "Bom" && (name == "declarations" || name == "definitions") {
+			obj[name] = values[0]
+			continue
+		}
+
+		obj[name] = values
+	}
+
+	if md.Name() == "Tool" && obj["components"] == nil && obj["services"] == nil {
+		return []interface{}{obj}
+	}
+
+	return obj
+}
+
+// cdxProtoToJSON returns the CycloneDX JSON form of a CycloneDX protobuf message
+func cdxProtoToJSON(m proto.Message) (interface{}, error) {
+	content, err := protojson.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+
+	// the numbers are kept as they are, so that a confidence of 0.8 is not written as 0.800000011920929
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return cdxProtoJSON(m.ProtoReflect().Descriptor(), value), nil
+}
+
+// cdxProtoFieldBytes returns the encoded messages of each occurrence of the field in the encoded message
+func cdxProtoFieldBytes(b []byte, number protowire.Number) [][]byte {
+	values := [][]byte{}
+
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return values
+		}
+
+		b = b[n:]
+
+		if num == number && typ == protowire.BytesType {
+			value, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return values
+			}
+
+			values = append(values, value)
+			b = b[n:]
+
+			continue
+		}
+
+		if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+			return values
+		}
+
+		b = b[n:]
+	}
+
+	return values
+}
+
+// cdxProtoTools returns the tools of each occurrence of the tools field of the encoded message, which is a list
+// of tools in the 1.3 and 1.4 formats that would otherwise be merged into one tool by the 1.6 message
+func cdxProtoTools(b []byte, number protowire.Number) ([]interface{}, bool) {
+	occurrences := cdxProtoFieldBytes(b, number)
+	if len(occurrences) < 2 {
+		return nil, false
+	}
+
+	tools := make([]interface{}, 0, len(occurrences))
+
+	for _, occurrence := range occurrences {
+		var tool cdxpb.Tool
+
+		if err := proto.Unmarshal(occurrence, &tool); err != nil {
+			return nil, false
+		}
+
+		value, err := cdxProtoToJSON(&tool)
+		if err != nil {
+			return nil, false
+		}
+
+		if legacy, ok := value.([]interface{}); ok {
+			tools = append(tools, legacy...)
+		}
+	}
+
+	return tools, true
+}
+
+// CycloneDXProtobufToJSON converts a CycloneDX protobuf BOM of the 1.3 to 1.6 formats into the JSON form
+// of its spec version, returning a *SBOMParseError with the reason that it could not be converted
+func CycloneDXProtobufToJSON(b []byte) (json.RawMessage, error) {
+	var msg cdxpb.Bom
+
+	if err := proto.Unmarshal(b, &msg); err != nil {
+		return nil, &SBOMParseError{Format: SBOMFormatCycloneDX, Reason: err.Error()}
+	}
+
+	if msg.GetSpecVersion() == "" {
+		return nil, &SBOMParseError{Format: SBOMFormatCycloneDX, Element: "spec_version", Reason: "expected the spec version of the bom"}
+	}
+
+	if _, err := cycloneDXSpecVersion(msg.GetSpecVersion()); err != nil {
+		return nil, &SBOMParseError{Format: SBOMFormatCycloneDX, Element: "spec_version", Reason: err.Error()}
+	}
+
+	value, err := cdxProtoToJSON(&msg)
+	if err != nil {
+		return nil, &SBOMParseError{Format: SBOMFormatCycloneDX, Reason: err.Error()}
+	}
+
+	obj, _ := value.(map[string]interface{})
+
+	// the metadata and vulnerabilities are read again for the tools that are listed in the older formats
+	fields := msg.ProtoReflect().Descriptor().Fields()
+
+	metadataField, vulnsField := fields.ByName("metadata"), fields.ByName("vulnerabilities")
+
+	if metadata := cdxProtoFieldBytes(b, metadataField.Number()); len(metadata) > 0 {
+		if tools, ok := cdxProtoTools(metadata[len(metadata)-1], metadataField.Message().Fields().ByName("tools").Number()); ok {
+			obj["metadata"].(map[string]interface{})["tools"] = tools
+		}
+	}
+
+	for i, vuln := range cdxProtoFieldBytes(b, vulnsField.Number()) {
+		if tools, ok := cdxProtoTools(vuln, vulnsField.Message().Fields().ByName("tools").Number()); ok {
+			obj["vulnerabilities"].([]interface{})[i].(map[string]interface{})["tools"] = tools
+		}
+	}
+
+	content, err := json.Marshal(obj)
+	if err != nil {
+		return nil, &SBOMParseError{Format: SBOMFormatCycloneDX, Reason: err.Error()}
+	}
+
+	var bom cyclonedx.BOM
+
+	if err := json.Unmarshal(content, &bom); err != nil {
+		return nil, &SBOMParseError{Format: SBOMFormatCycloneDX, Reason: err.Error()}
+	}
+
+	return cycloneDXToJSON(&bom)
+}
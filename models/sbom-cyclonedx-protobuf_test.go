@@ -0,0 +1,280 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	cyclonedx "github.com/CycloneDX/cyclonedx-go"
+	"github.com/ortelius/scec-deppkg/models/cdxpb"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// mustMarshalProto encodes the message, failing the test if it cannot be encoded
+func mustMarshalProto(t *testing.T, m proto.Message) []byte {
+	t.Helper()
+
+	b, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatalf("proto.Marshal returned an error: %v", err)
+	}
+
+	return b
+}
+
+// appendProtoField appends an occurrence of the field, encoded as the message, to the encoded message
+func appendProtoField(t *testing.T, b []byte, number protowire.Number, m proto.Message) []byte {
+	t.Helper()
+
+	b = protowire.AppendTag(b, number, protowire.BytesType)
+
+	return protowire.AppendBytes(b, mustMarshalProto(t, m))
+}
+
+// expectCycloneDXJSON checks that the sbom is the CycloneDX JSON of the expected BOM
+func expectCycloneDXJSON(t *testing.T, got []byte, expected *cyclonedx.BOM) {
+	t.Helper()
+
+	want, err := cycloneDXToJSON(expected)
+	if err != nil {
+		t.Fatalf("cycloneDXToJSON returned an error: %v", err)
+	}
+
+	if string(got) != string(want) {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCycloneDXProtobufToJSON(t *testing.T) {
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	score := 7.5
+
+	tests := []struct {
+		name     string
+		bom      *cdxpb.Bom
+		expected *cyclonedx.BOM
+	}{
+		{
+			name: "components, dependencies and vulnerabilities",
+			bom: &cdxpb.Bom{
+				SpecVersion:  "1.5",
+				Version:      proto.Int32(3),
+				SerialNumber: proto.String("urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79"),
+				Metadata: &cdxpb.Metadata{
+					Timestamp: timestamppb.New(timestamp),
+					Component: &cdxpb.Component{Type: cdxpb.Classification_CLASSIFICATION_APPLICATION, BomRef: proto.String("app"), Name: "app", Version: "1.0.0"},
+				},
+				Components: []*cdxpb.Component{
+					{
+						Type:    cdxpb.Classification_CLASSIFICATION_LIBRARY,
+						BomRef:  proto.String("pkg:npm/lodash@4.17.20"),
+						Name:    "lodash",
+						Version: "4.17.20",
+						Purl:    proto.String("pkg:npm/lodash@4.17.20"),
+						Hashes:  []*cdxpb.Hash{{Alg: cdxpb.HashAlg_HASH_ALG_SHA_256, Value: "abc123"}},
+						Licenses: []*cdxpb.LicenseChoice{
+							{Choice: &cdxpb.LicenseChoice_License{License: &cdxpb.License{License: &cdxpb.License_Id{Id: "MIT"}}}},
+						},
+						ExternalReferences: []*cdxpb.ExternalReference{
+							{Type: cdxpb.ExternalReferenceType_EXTERNAL_REFERENCE_TYPE_VCS, Url: "https://github.com/lodash/lodash"},
+							{Type: cdxpb.ExternalReferenceType_EXTERNAL_REFERENCE_TYPE_OTHER, Url: "https://lodash.com"},
+						},
+						Properties: []*cdxpb.Property{{Name: "cdx:npm:package:development", Value: proto.String("false")}},
+					},
+					{
+						Type:     cdxpb.Classification_CLASSIFICATION_LIBRARY,
+						BomRef:   proto.String("pkg:npm/minimist@1.2.5"),
+						Name:     "minimist",
+						Version:  "1.2.5",
+						Licenses: []*cdxpb.LicenseChoice{{Choice: &cdxpb.LicenseChoice_Expression{Expression: "MIT OR Apache-2.0"}}},
+					},
+				},
+				Dependencies: []*cdxpb.Dependency{
+					{Ref: "app", Dependencies: []*cdxpb.Dependency{{Ref: "pkg:npm/lodash@4.17.20"}}},
+					{Ref: "pkg:npm/lodash@4.17.20", Dependencies: []*cdxpb.Dependency{{Ref: "pkg:npm/minimist@1.2.5"}}},
+				},
+				Vulnerabilities: []*cdxpb.Vulnerability{
+					{
+						Id: proto.String("CVE-2021-23337"),
+						Ratings: []*cdxpb.VulnerabilityRating{
+							{Score: proto.Float64(score), Severity: cdxpb.Severity_SEVERITY_HIGH.Enum(), Method: cdxpb.ScoreMethod_SCORE_METHOD_CVSSV31.Enum()},
+						},
+						Cwes:      []int32{94},
+						Published: timestamppb.New(timestamp),
+						Analysis: &cdxpb.VulnerabilityAnalysis{
+							State:         cdxpb.ImpactAnalysisState_IMPACT_ANALYSIS_STATE_NOT_AFFECTED.Enum(),
+							Justification: cdxpb.ImpactAnalysisJustification_IMPACT_ANALYSIS_JUSTIFICATION_CODE_NOT_REACHABLE.Enum(),
+							Response:      []cdxpb.VulnerabilityResponse{cdxpb.VulnerabilityResponse_VULNERABILITY_RESPONSE_UPDATE},
+						},
+						Affects: []*cdxpb.VulnerabilityAffects{{Ref: "pkg:npm/lodash@4.17.20"}},
+					},
+				},
+			},
+			expected: &cyclonedx.BOM{
+				SpecVersion:  cyclonedx.SpecVersion1_5,
+				Version:      3,
+				SerialNumber: "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+				Metadata: &cyclonedx.Metadata{
+					Timestamp: "2024-01-02T03:04:05Z",
+					Component: &cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, BOMRef: "app", Name: "app", Version: "1.0.0"},
+				},
+				Components: &[]cyclonedx.Component{
+					{
+						Type:       cyclonedx.ComponentTypeLibrary,
+						BOMRef:     "pkg:npm/lodash@4.17.20",
+						Name:       "lodash",
+						Version:    "4.17.20",
+						PackageURL: "pkg:npm/lodash@4.17.20",
+						Hashes:     &[]cyclonedx.Hash{{Algorithm: cyclonedx.HashAlgoSHA256, Value: "abc123"}},
+						Licenses:   &cyclonedx.Licenses{{License: &cyclonedx.License{ID: "MIT"}}},
+						ExternalReferences: &[]cyclonedx.ExternalReference{
+							{Type: cyclonedx.ERTypeVCS, URL: "https://github.com/lodash/lodash"},
+							{Type: cyclonedx.ERTypeOther, URL: "https://lodash.com"},
+						},
+						Properties: &[]cyclonedx.Property{{Name: "cdx:npm:package:development", Value: "false"}},
+					},
+					{
+						Type:     cyclonedx.ComponentTypeLibrary,
+						BOMRef:   "pkg:npm/minimist@1.2.5",
+						Name:     "minimist",
+						Version:  "1.2.5",
+						Licenses: &cyclonedx.Licenses{{Expression: "MIT OR Apache-2.0"}},
+					},
+				},
+				Dependencies: &[]cyclonedx.Dependency{
+					{Ref: "app", Dependencies: &[]string{"pkg:npm/lodash@4.17.20"}},
+					{Ref: "pkg:npm/lodash@4.17.20", Dependencies: &[]string{"pkg:npm/minimist@1.2.5"}},
+				},
+				Vulnerabilities: &[]cyclonedx.Vulnerability{
+					{
+						ID:        "CVE-2021-23337",
+						Ratings:   &[]cyclonedx.VulnerabilityRating{{Score: &score, Severity: cyclonedx.SeverityHigh, Method: cyclonedx.ScoringMethodCVSSv31}},
+						CWEs:      &[]int{94},
+						Published: "2024-01-02T03:04:05Z",
+						Analysis: &cyclonedx.VulnerabilityAnalysis{
+							State:         cyclonedx.IASNotAffected,
+							Justification: cyclonedx.IAJCodeNotReachable,
+							Response:      &[]cyclonedx.ImpactAnalysisResponse{cyclonedx.IARUpdate},
+						},
+						Affects: &[]cyclonedx.Affects{{Ref: "pkg:npm/lodash@4.17.20"}},
+					},
+				},
+			},
+		},
+		{
+			name: "the version defaults to 1",
+			bom: &cdxpb.Bom{
+				SpecVersion: "1.6",
+				Components:  []*cdxpb.Component{{Type: cdxpb.Classification_CLASSIFICATION_LIBRARY, Name: "zlib", Version: "1.3"}},
+			},
+			expected: &cyclonedx.BOM{
+				SpecVersion: cyclonedx.SpecVersion1_6,
+				Version:     1,
+				Components:  &[]cyclonedx.Component{{Type: cyclonedx.ComponentTypeLibrary, Name: "zlib", Version: "1.3"}},
+			},
+		},
+		{
+			name: "tools as components",
+			bom: &cdxpb.Bom{
+				SpecVersion: "1.6",
+				Version:     proto.Int32(1),
+				Metadata: &cdxpb.Metadata{
+					Tools: &cdxpb.Tool{Components: []*cdxpb.Component{{Type: cdxpb.Classification_CLASSIFICATION_APPLICATION, Name: "syft", Version: "1.0.0"}}},
+				},
+			},
+			expected: &cyclonedx.BOM{
+				SpecVersion: cyclonedx.SpecVersion1_6,
+				Version:     1,
+				Metadata: &cyclonedx.Metadata{
+					Tools: &cyclonedx.ToolsChoice{Components: &[]cyclonedx.Component{{Type: cyclonedx.ComponentTypeApplication, Name: "syft", Version: "1.0.0"}}},
+				},
+			},
+		},
+		{
+			name: "legacy tool",
+			bom: &cdxpb.Bom{
+				SpecVersion: "1.4",
+				Version:     proto.Int32(1),
+				Metadata: &cdxpb.Metadata{
+					Tools: &cdxpb.Tool{Vendor: proto.String("anchore"), Name: proto.String("syft"), Version: proto.String("0.90.0")},
+				},
+			},
+			expected: &cyclonedx.BOM{
+				SpecVersion: cyclonedx.SpecVersion1_4,
+				Version:     1,
+				Metadata: &cyclonedx.Metadata{
+					Tools: &cyclonedx.ToolsChoice{Tools: &[]cyclonedx.Tool{{Vendor: "anchore", Name: "syft", Version: "0.90.0"}}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CycloneDXProtobufToJSON(mustMarshalProto(t, tt.bom))
+			if err != nil {
+				t.Fatalf("CycloneDXProtobufToJSON returned an error: %v", err)
+			}
+
+			expectCycloneDXJSON(t, got, tt.expected)
+
+			// the stored JSON is read the same way as an sbom that was uploaded as JSON
+			if _, err := NormalizeSBOM(got); err != nil {
+				t.Errorf("NormalizeSBOM returned an error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCycloneDXProtobufToJSONLegacyTools(t *testing.T) {
+	// the 1.3 and 1.4 formats repeat the tools field for each tool, which the 1.6 message would merge into one
+	metadata := appendProtoField(t, nil, 2, &cdxpb.Tool{Vendor: proto.String("anchore"), Name: proto.String("syft")})
+	metadata = appendProtoField(t, metadata, 2, &cdxpb.Tool{Name: proto.String("grype"), Version: proto.String("0.70.0")})
+
+	b := mustMarshalProto(t, &cdxpb.Bom{SpecVersion: "1.4", Version: proto.Int32(2)})
+	b = protowire.AppendTag(b, 4, protowire.BytesType)
+	b = protowire.AppendBytes(b, metadata)
+
+	got, err := CycloneDXProtobufToJSON(b)
+	if err != nil {
+		t.Fatalf("CycloneDXProtobufToJSON returned an error: %v", err)
+	}
+
+	expectCycloneDXJSON(t, got, &cyclonedx.BOM{
+		SpecVersion: cyclonedx.SpecVersion1_4,
+		Version:     2,
+		Metadata: &cyclonedx.Metadata{
+			Tools: &cyclonedx.ToolsChoice{Tools: &[]cyclonedx.Tool{{Vendor: "anchore", Name: "syft"}, {Name: "grype", Version: "0.70.0"}}},
+		},
+	})
+}
+
+func TestCycloneDXProtobufToJSONErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		element string
+	}{
+		{"not protobuf", []byte("{\"bomFormat\": \"CycloneDX\"}"), ""},
+		{"no spec version", mustMarshalProto(t, &cdxpb.Bom{Version: proto.Int32(1)}), "spec_version"},
+		{"unsupported spec version", mustMarshalProto(t, &cdxpb.Bom{SpecVersion: "1.1"}), "spec_version"},
+		{"unknown spec version", mustMarshalProto(t, &cdxpb.Bom{SpecVersion: "9.9"}), "spec_version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CycloneDXProtobufToJSON(tt.content)
+
+			var perr *SBOMParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("CycloneDXProtobufToJSON error = %v, want a *SBOMParseError", err)
+			}
+
+			if perr.Format != SBOMFormatCycloneDX || perr.Element != tt.element {
+				t.Errorf("CycloneDXProtobufToJSON error = %+v, want the %q element of a CycloneDX sbom", perr, tt.element)
+			}
+		})
+	}
+}
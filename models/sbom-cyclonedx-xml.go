@@ -0,0 +1,40 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"path"
+
+	cyclonedx "github.com/CycloneDX/cyclonedx-go"
+)
+
+// CycloneDXXMLToJSON converts a CycloneDX XML BOM into the JSON form of the same spec version, which is
+// taken from the namespace of the bom element, returning a *SBOMParseError with the line that could not be read
+func CycloneDXXMLToJSON(r io.Reader) (json.RawMessage, error) {
+	var bom cyclonedx.BOM
+
+	decoder := xml.NewDecoder(r)
+
+	if err := decoder.Decode(&bom); err != nil {
+		line, _ := decoder.InputPos()
+		return nil, &SBOMParseError{Format: SBOMFormatCycloneDX, Line: line, Reason: err.Error()}
+	}
+
+	if bom.XMLNS == "" {
+		return nil, &SBOMParseError{Format: SBOMFormatCycloneDX, Element: "bom", Reason: "expected the namespace of a CycloneDX spec version"}
+	}
+
+	// i.e. 1.6 for "http://cyclonedx.org/schema/bom/1.6"
+	specVersion, err := cycloneDXSpecVersion(path.Base(bom.XMLNS))
+	if err != nil {
+		return nil, &SBOMParseError{Format: SBOMFormatCycloneDX, Element: "bom", Reason: err.Error()}
+	}
+
+	bom.SpecVersion = specVersion
+	bom.XMLNS = ""
+
+	return cycloneDXToJSON(&bom)
+}
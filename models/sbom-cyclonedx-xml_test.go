@@ -0,0 +1,139 @@
+package models
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	cyclonedx "github.com/CycloneDX/cyclonedx-go"
+)
+
+func TestCycloneDXXMLToJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		bom  *cyclonedx.BOM
+	}{
+		{
+			name: "components and dependencies",
+			bom: &cyclonedx.BOM{
+				SpecVersion:  cyclonedx.SpecVersion1_5,
+				Version:      2,
+				SerialNumber: "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+				Metadata: &cyclonedx.Metadata{
+					Timestamp: "2024-01-02T03:04:05Z",
+					Tools:     &cyclonedx.ToolsChoice{Tools: &[]cyclonedx.Tool{{Vendor: "anchore", Name: "syft", Version: "0.90.0"}}},
+					Component: &cyclonedx.Component{Type: cyclonedx.ComponentTypeApplication, BOMRef: "app", Name: "app", Version: "1.0.0"},
+				},
+				Components: &[]cyclonedx.Component{
+					{
+						Type:       cyclonedx.ComponentTypeLibrary,
+						BOMRef:     "pkg:npm/lodash@4.17.20",
+						Name:       "lodash",
+						Version:    "4.17.20",
+						PackageURL: "pkg:npm/lodash@4.17.20",
+						Hashes:     &[]cyclonedx.Hash{{Algorithm: cyclonedx.HashAlgoSHA256, Value: "abc123"}},
+						Licenses:   &cyclonedx.Licenses{{License: &cyclonedx.License{ID: "MIT"}}},
+						ExternalReferences: &[]cyclonedx.ExternalReference{
+							{Type: cyclonedx.ERTypeVCS, URL: "https://github.com/lodash/lodash"},
+						},
+						Components: &[]cyclonedx.Component{{Type: cyclonedx.ComponentTypeFile, Name: "lodash.min.js"}},
+					},
+					{
+						Type:     cyclonedx.ComponentTypeLibrary,
+						BOMRef:   "pkg:npm/minimist@1.2.5",
+						Name:     "minimist",
+						Version:  "1.2.5",
+						Licenses: &cyclonedx.Licenses{{Expression: "MIT OR Apache-2.0"}},
+					},
+				},
+				Dependencies: &[]cyclonedx.Dependency{
+					{Ref: "app", Dependencies: &[]string{"pkg:npm/lodash@4.17.20"}},
+					{Ref: "pkg:npm/lodash@4.17.20", Dependencies: &[]string{"pkg:npm/minimist@1.2.5"}},
+				},
+			},
+		},
+		{
+			name: "older spec version",
+			bom: &cyclonedx.BOM{
+				SpecVersion: cyclonedx.SpecVersion1_2,
+				Version:     1,
+				Components:  &[]cyclonedx.Component{{Type: cyclonedx.ComponentTypeLibrary, Name: "zlib", Version: "1.3"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			if err := cyclonedx.NewBOMEncoder(&buf, cyclonedx.BOMFileFormatXML).EncodeVersion(tt.bom, tt.bom.SpecVersion); err != nil {
+				t.Fatalf("failed to encode the XML: %v", err)
+			}
+
+			got, err := CycloneDXXMLToJSON(&buf)
+			if err != nil {
+				t.Fatalf("CycloneDXXMLToJSON returned an error: %v", err)
+			}
+
+			tt.bom.XMLNS = ""
+			expectCycloneDXJSON(t, got, tt.bom)
+
+			if _, err := NormalizeSBOM(got); err != nil {
+				t.Errorf("NormalizeSBOM returned an error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCycloneDXXMLToJSONVersion(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<bom xmlns="http://cyclonedx.org/schema/bom/1.4">
+  <components>
+    <component type="library">
+      <name>zlib</name>
+      <version>1.3</version>
+    </component>
+  </components>
+</bom>`
+
+	got, err := CycloneDXXMLToJSON(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("CycloneDXXMLToJSON returned an error: %v", err)
+	}
+
+	expectCycloneDXJSON(t, got, &cyclonedx.BOM{
+		SpecVersion: cyclonedx.SpecVersion1_4,
+		Version:     1,
+		Components:  &[]cyclonedx.Component{{Type: cyclonedx.ComponentTypeLibrary, Name: "zlib", Version: "1.3"}},
+	})
+}
+
+func TestCycloneDXXMLToJSONErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		line    int
+		element string
+	}{
+		{"malformed", "<bom xmlns=\"http://cyclonedx.org/schema/bom/1.5\">\n<components>\n<component>\n</bom>", 4, ""},
+		{"no namespace", "<bom version=\"1\"></bom>", 0, "bom"},
+		{"spec version without json", "<bom xmlns=\"http://cyclonedx.org/schema/bom/1.1\"></bom>", 0, "bom"},
+		{"unknown spec version", "<bom xmlns=\"http://cyclonedx.org/schema/bom/9.9\"></bom>", 0, "bom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CycloneDXXMLToJSON(strings.NewReader(tt.content))
+
+			var perr *SBOMParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("CycloneDXXMLToJSON error = %v, want a *SBOMParseError", err)
+			}
+
+			if perr.Line != tt.line || perr.Element != tt.element {
+				t.Errorf("CycloneDXXMLToJSON error = %+v, want line %d and element %q", perr, tt.line, tt.element)
+			}
+		})
+	}
+}
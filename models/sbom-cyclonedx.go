@@ -0,0 +1,163 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	cyclonedx "github.com/CycloneDX/cyclonedx-go"
+)
+
+// cdxLicenseChoice defines a license or license expression of a CycloneDX component
+type cdxLicenseChoice struct {
+	License *struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"license"`
+	Expression string `json:"expression"`
+}
+
+// cdxComponent defines the fields of a CycloneDX component that are normalized
+type cdxComponent struct {
+	BOMRef     string              `json:"bom-ref"`
+	Type       string              `json:"type"`
+	Name       string              `json:"name"`
+	Version    string              `json:"version"`
+	Purl       string              `json:"purl"`
+	CPE        string              `json:"cpe"`
+	SWID       *ComponentSWID      `json:"swid"`
+	Licenses   []cdxLicenseChoice  `json:"licenses"`
+	Properties []ComponentProperty `json:"properties"`
+	Components []cdxComponent      `json:"components"`
+}
+
+// cdxBOM defines the fields of a CycloneDX BOM that are normalized
+type cdxBOM struct {
+	Metadata struct {
+		Component *cdxComponent `json:"component"`
+	} `json:"metadata"`
+	Components   []cdxComponent `json:"components"`
+	Dependencies []struct {
+		Ref       string   `json:"ref"`
+		DependsOn []string `json:"dependsOn"`
+	} `json:"dependencies"`
+}
+
+// toComponent converts the CycloneDX component, where a license without an id is named by
+// the part of its name before any "----" separator
+func (c cdxComponent) toComponent() Component {
+	comp := Component{
+		Ref:        c.BOMRef,
+		Type:       c.Type,
+		Name:       c.Name,
+		Version:    c.Version,
+		Purl:       c.Purl,
+		CPE:        c.CPE,
+		SWID:       c.SWID,
+		Licenses:   []string{},
+		Properties: c.Properties,
+	}
+
+	for _, lic := range c.Licenses {
+		switch {
+		case lic.License != nil && lic.License.ID != "":
+			comp.Licenses = append(comp.Licenses, lic.License.ID)
+		case lic.License != nil:
+			name, _, _ := strings.Cut(lic.License.Name, "----")
+			comp.Licenses = append(comp.Licenses, name)
+		case lic.Expression != "":
+			comp.Licenses = append(comp.Licenses, LicenseIDs(lic.Expression)...)
+		}
+	}
+
+	return comp
+}
+
+// addNested adds the components nested within the component, and those nested within them, to the sbom,
+// where each is contained by the component it is nested within
+func (sbom *NormalizedSBOM) addNested(parent cdxComponent) {
+	for _, c := range parent.Components {
+		sbom.Components = append(sbom.Components, c.toComponent())
+
+		if parent.BOMRef != "" && c.BOMRef != "" {
+			sbom.Relationships = append(sbom.Relationships, Relationship{From: parent.BOMRef, To: c.BOMRef, Type: RelationshipContains})
+		}
+
+		sbom.addNested(c)
+	}
+}
+
+// normalizeCycloneDX reads the components of a CycloneDX JSON BOM, including those nested within other
+// components, and their dependencies
+func normalizeCycloneDX(content []byte) (*NormalizedSBOM, error) {
+	var bom cdxBOM
+
+	if err := json.Unmarshal(content, &bom); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSBOM, err)
+	}
+
+	sbom := &NormalizedSBOM{
+		Components:    make([]Component, 0, len(bom.Components)),
+		Relationships: []Relationship{},
+	}
+
+	for _, c := range bom.Components {
+		sbom.Components = append(sbom.Components, c.toComponent())
+		sbom.addNested(c)
+	}
+
+	if bom.Metadata.Component != nil {
+		root := bom.Metadata.Component.toComponent()
+		sbom.Root = &root
+		sbom.addNested(*bom.Metadata.Component)
+		sbom.OperatingSystem = operatingSystem(append([]Component{root}, sbom.Components...)...)
+	} else {
+		sbom.OperatingSystem = operatingSystem(sbom.Components...)
+	}
+
+	for _, dep := range bom.Dependencies {
+		for _, to := range dep.DependsOn {
+			sbom.Relationships = append(sbom.Relationships, Relationship{From: dep.Ref, To: to, Type: RelationshipDependsOn})
+		}
+	}
+
+	return sbom, nil
+}
+
+// cycloneDXSpecVersion returns the spec version of a CycloneDX BOM, i.e. "1.6", that can be written as JSON
+func cycloneDXSpecVersion(version string) (cyclonedx.SpecVersion, error) {
+	var sv cyclonedx.SpecVersion
+
+	quoted, _ := json.Marshal(version)
+
+	if err := sv.UnmarshalJSON(quoted); err != nil {
+		return 0, fmt.Errorf("unsupported CycloneDX version %q", version)
+	}
+
+	if sv < cyclonedx.SpecVersion1_2 {
+		return 0, fmt.Errorf("CycloneDX %s cannot be written as JSON, which needs 1.2 or later", version)
+	}
+
+	return sv, nil
+}
+
+// cycloneDXToJSON writes the BOM in the CycloneDX JSON form of its spec version, which is how sboms are stored
+func cycloneDXToJSON(bom *cyclonedx.BOM) (json.RawMessage, error) {
+	var buf bytes.Buffer
+
+	bom.BOMFormat = cyclonedx.BOMFormat
+
+	// the version of a BOM defaults to 1 when it is left out, which is not a valid version itself
+	if bom.Version == 0 {
+		bom.Version = 1
+	}
+
+	if err := cyclonedx.NewBOMEncoder(&buf, cyclonedx.BOMFileFormatJSON).SetEscapeHTML(false).Encode(bom); err != nil {
+		return nil, &SBOMParseError{Format: SBOMFormatCycloneDX, Reason: err.Error()}
+	}
+
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
@@ -0,0 +1,346 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// spdxTagFields maps the tags of the sections of an SPDX 2.x tag-value document to the fields of the JSON form,
+// where a tag whose field is a list is appended to, rather than replaced, each time it appears
+//
+//nolint:gochecknoglobals // this is read-only and the nicest implementation
+var spdxTagFields = map[string]map[string]string{
+	"document": {
+		"SPDXVersion":       "spdxVersion",
+		"DataLicense":       "dataLicense",
+		"SPDXID":            "SPDXID",
+		"DocumentName":      "name",
+		"DocumentNamespace": "documentNamespace",
+		"DocumentComment":   "comment",
+	},
+	"package": {
+		"SPDXID":                      "SPDXID",
+		"PackageVersion":              "versionInfo",
+		"PackageFileName":             "packageFileName",
+		"PackageSupplier":             "supplier",
+		"PackageOriginator":           "originator",
+		"PackageDownloadLocation":     "downloadLocation",
+		"PackageHomePage":             "homepage",
+		"PackageSourceInfo":           "sourceInfo",
+		"PackageLicenseConcluded":     "licenseConcluded",
+		"PackageLicenseInfoFromFiles": "[]licenseInfoFromFiles",
+		"PackageLicenseDeclared":      "licenseDeclared",
+		"PackageLicenseComments":      "licenseComments",
+		"PackageCopyrightText":        "copyrightText",
+		"PackageSummary":              "summary",
+		"PackageDescription":          "description",
+		"PackageComment":              "comment",
+		"PackageAttributionText":      "[]attributionTexts",
+		"PrimaryPackagePurpose":       "primaryPackagePurpose",
+		"ReleaseDate":                 "releaseDate",
+		"BuiltDate":                   "builtDate",
+		"ValidUntilDate":              "validUntilDate",
+	},
+	"file": {
+		"SPDXID":              "SPDXID",
+		"FileType":            "[]fileTypes",
+		"LicenseConcluded":    "licenseConcluded",
+		"LicenseInfoInFile":   "[]licenseInfoInFiles",
+		"LicenseComments":     "licenseComments",
+		"FileCopyrightText":   "copyrightText",
+		"FileComment":         "comment",
+		"FileNotice":          "noticeText",
+		"FileContributor":     "[]fileContributors",
+		"FileAttributionText": "[]attributionTexts",
+	},
+	"license": {
+		"ExtractedText":         "extractedText",
+		"LicenseName":           "name",
+		"LicenseCrossReference": "[]seeAlsos",
+		"LicenseComment":        "comment",
+	},
+}
+
+// spdxTagValueParser builds the JSON form of an SPDX tag-value document, one tag at a time
+type spdxTagValueParser struct {
+	doc     map[string]interface{}
+	section string                 // section of the document that the tags belong to
+	element map[string]interface{} // package, file or extracted license that the tags belong to
+	line    int                    // line of the tag being parsed
+}
+
+// fail returns the parse error for the current line
+func (p *spdxTagValueParser) fail(format string, args ...interface{}) error {
+	return &SBOMParseError{Format: SBOMFormatSPDX, Line: p.line, Reason: fmt.Sprintf(format, args...)}
+}
+
+// appendTo appends the value to the list field of the object
+func appendTo(obj map[string]interface{}, field string, value interface{}) {
+	list, _ := obj[field].([]interface{})
+	obj[field] = append(list, value)
+}
+
+// startElement starts a section for a new element, which is added to the list field of the document
+func (p *spdxTagValueParser) startElement(section, field string, element map[string]interface{}) {
+	p.section = section
+	p.element = element
+
+	appendTo(p.doc, field, element)
+}
+
+// checksum parses a checksum, i.e. "SHA256: 2c26b46b..."
+func (p *spdxTagValueParser) checksum(value string) (map[string]interface{}, error) {
+	algorithm, checksum, ok := strings.Cut(value, ":")
+	if !ok {
+		return nil, p.fail("expected a checksum of the form ALGORITHM: VALUE, got %q", value)
+	}
+
+	return map[string]interface{}{"algorithm": strings.TrimSpace(algorithm), "checksumValue": strings.TrimSpace(checksum)}, nil
+}
+
+// add adds the tag to the document, in the section that the preceding tags started
+func (p *spdxTagValueParser) add(tag, value string) error {
+	switch tag {
+	case "PackageName":
+		p.startElement("package", "packages", map[string]interface{}{"name": value})
+		return nil
+	case "FileName":
+		p.startElement("file", "files", map[string]interface{}{"fileName": value})
+		return nil
+	case "LicenseID":
+		p.startElement("license", "hasExtractedLicensingInfos", map[string]interface{}{"licenseId": value})
+		return nil
+	case "SnippetSPDXID":
+		// snippets are not used to find the packages of the sbom, so their tags are skipped
+		p.section, p.element = "snippet", nil
+		return nil
+	case "Creator", "Created", "LicenseListVersion", "CreatorComment":
+		info, _ := p.doc["creationInfo"].(map[string]interface{})
+		if info == nil {
+			info = map[string]interface{}{}
+			p.doc["creationInfo"] = info
+		}
+
+		switch tag {
+		case "Creator":
+			appendTo(info, "creators", value)
+		case "CreatorComment":
+			info["comment"] = value
+		default:
+			info[strings.ToLower(tag[:1])+tag[1:]] = value
+		}
+
+		return nil
+	case "Relationship":
+		fields := strings.Fields(value)
+		if len(fields) != 3 {
+			return p.fail("expected a relationship of the form SPDXRef-A TYPE SPDXRef-B, got %q", value)
+		}
+
+		appendTo(p.doc, "relationships", map[string]interface{}{
+			"spdxElementId":      fields[0],
+			"relationshipType":   fields[1],
+			"relatedSpdxElement": fields[2],
+		})
+
+		return nil
+	case "RelationshipComment":
+		if rels, _ := p.doc["relationships"].([]interface{}); len(rels) > 0 {
+			rels[len(rels)-1].(map[string]interface{})["comment"] = value
+		}
+
+		return nil
+	case "ExternalDocumentRef":
+		fields := strings.Fields(value)
+		if len(fields) != 3 {
+			return p.fail("expected an external document ref of the form DocumentRef-ID URI ALGORITHM: VALUE, got %q", value)
+		}
+
+		checksum, err := p.checksum(fields[2])
+		if err != nil {
+			return err
+		}
+
+		appendTo(p.doc, "externalDocumentRefs", map[string]interface{}{
+			"externalDocumentId": fields[0],
+			"spdxDocument":       fields[1],
+			"checksum":           checksum,
+		})
+
+		return nil
+	}
+
+	if p.section == "snippet" {
+		return nil
+	}
+
+	obj := p.doc
+	if p.section != "document" {
+		obj = p.element
+	}
+
+	switch tag {
+	case "ExternalRef":
+		if p.section != "package" {
+			return p.fail("%s must follow a PackageName", tag)
+		}
+
+		fields := strings.Fields(value)
+		if len(fields) != 3 {
+			return p.fail("expected an external ref of the form CATEGORY TYPE LOCATOR, got %q", value)
+		}
+
+		appendTo(obj, "externalRefs", map[string]interface{}{
+			"referenceCategory": fields[0],
+			"referenceType":     fields[1],
+			"referenceLocator":  fields[2],
+		})
+
+		return nil
+	case "ExternalRefComment":
+		if refs, _ := obj["externalRefs"].([]interface{}); len(refs) > 0 {
+			refs[len(refs)-1].(map[string]interface{})["comment"] = value
+			return nil
+		}
+
+		return p.fail("%s must follow an ExternalRef", tag)
+	case "PackageChecksum", "FileChecksum":
+		checksum, err := p.checksum(value)
+		if err != nil {
+			return err
+		}
+
+		appendTo(obj, "checksums", checksum)
+
+		return nil
+	case "FilesAnalyzed":
+		switch strings.ToLower(value) {
+		case "true":
+			obj["filesAnalyzed"] = true
+		case "false":
+			obj["filesAnalyzed"] = false
+		default:
+			return p.fail("expected true or false for %s, got %q", tag, value)
+		}
+
+		return nil
+	case "PackageVerificationCode":
+		code, excludes, _ := strings.Cut(value, "(")
+		verification := map[string]interface{}{"packageVerificationCodeValue": strings.TrimSpace(code)}
+
+		if excludes = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(excludes), ")")); excludes != "" {
+			verification["packageVerificationCodeExcludedFiles"] = []interface{}{strings.TrimSpace(strings.TrimPrefix(excludes, "excludes:"))}
+		}
+
+		obj["packageVerificationCode"] = verification
+
+		return nil
+	}
+
+	field, ok := spdxTagFields[p.section][tag]
+	if !ok {
+		// a tag of another section shows the document is out of order, rather than using a newer version of the spec
+		for section, fields := range spdxTagFields {
+			if _, known := fields[tag]; known {
+				return p.fail("%s is a %s tag, but it follows a %s", tag, section, p.section)
+			}
+		}
+
+		return nil
+	}
+
+	if list, ok := strings.CutPrefix(field, "[]"); ok {
+		appendTo(obj, list, value)
+	} else {
+		obj[field] = value
+	}
+
+	return nil
+}
+
+// SPDXTagValueToJSON converts an SPDX 2.x tag-value document into its JSON form, returning a *SBOMParseError
+// with the line of the tag that could not be converted. The packages, files, extracted licenses and
+// relationships are converted, while snippets and tags that are not part of the spec are left out.
+func SPDXTagValueToJSON(r io.Reader) (json.RawMessage, error) {
+	p := &spdxTagValueParser{doc: map[string]interface{}{}, section: "document"}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		p.line++
+
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		tag, value, ok := strings.Cut(text, ":")
+		if !ok || strings.ContainsAny(tag, " \t") {
+			return nil, p.fail("expected a line of the form Tag: Value, got %q", text)
+		}
+
+		start := p.line
+		value = strings.TrimSpace(value)
+
+		// a <text> value may span several lines, up to the closing </text>
+		if rest, ok := strings.CutPrefix(value, "<text>"); ok {
+			lines := []string{}
+
+			for {
+				if text, done := strings.CutSuffix(rest, "</text>"); done {
+					lines = append(lines, text)
+					break
+				}
+
+				lines = append(lines, rest)
+
+				if !scanner.Scan() {
+					p.line = start
+					return nil, p.fail("%s has a <text> value without a closing </text>", tag)
+				}
+
+				p.line++
+				rest = scanner.Text()
+			}
+
+			value = strings.Join(lines, "\n")
+		}
+
+		line := p.line
+		p.line = start
+
+		if err := p.add(tag, value); err != nil {
+			return nil, err
+		}
+
+		p.line = line
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, p.fail("%v", err)
+	}
+
+	version, _ := p.doc["spdxVersion"].(string)
+	if !strings.HasPrefix(version, "SPDX-2.") {
+		p.line = 0
+		return nil, p.fail("expected an SPDXVersion tag of SPDX-2.x, got %q", version)
+	}
+
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(p.doc); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
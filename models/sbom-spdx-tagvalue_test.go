@@ -0,0 +1,242 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// spdxTagValueFixture is an SPDX 2.3 tag-value document of an application that depends on a library
+const spdxTagValueFixture = `SPDXVersion: SPDX-2.3
+DataLicense: CC0-1.0
+SPDXID: SPDXRef-DOCUMENT
+DocumentName: app
+DocumentNamespace: https://example.com/app
+Creator: Tool: syft-0.90.0
+Created: 2024-01-02T03:04:05Z
+DocumentComment: <text>built by
+the release pipeline</text>
+
+# the application
+PackageName: app
+SPDXID: SPDXRef-app
+PackageVersion: 1.0.0
+PackageDownloadLocation: NOASSERTION
+FilesAnalyzed: false
+PackageLicenseDeclared: Apache-2.0
+PrimaryPackagePurpose: APPLICATION
+
+PackageName: lodash
+SPDXID: SPDXRef-lodash
+PackageVersion: 4.17.20
+PackageDownloadLocation: https://registry.npmjs.org/lodash/-/lodash-4.17.20.tgz
+PackageChecksum: SHA256: 2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae
+PackageLicenseConcluded: (MIT OR Apache-2.0)
+PackageLicenseDeclared: MIT
+ExternalRef: PACKAGE-MANAGER purl pkg:npm/lodash@4.17.20
+ExternalRefComment: from the lockfile
+ExternalRef: SECURITY cpe23Type cpe:2.3:a:lodash:lodash:4.17.20:*:*:*:*:*:*:*
+
+FileName: ./LICENSE
+SPDXID: SPDXRef-license-file
+FileChecksum: SHA1: 85ed0817af83a24ad8da68c2b5094de69833983c
+LicenseConcluded: MIT
+
+LicenseID: LicenseRef-custom
+ExtractedText: <text>a license
+of its own</text>
+LicenseName: custom
+
+Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-app
+Relationship: SPDXRef-lodash DEPENDENCY_OF SPDXRef-app
+RelationshipComment: a runtime dependency
+Relationship: SPDXRef-lodash CONTAINS SPDXRef-license-file
+`
+
+func TestSPDXTagValueToJSON(t *testing.T) {
+	content, err := SPDXTagValueToJSON(strings.NewReader(spdxTagValueFixture))
+	if err != nil {
+		t.Fatalf("SPDXTagValueToJSON returned an error: %v", err)
+	}
+
+	var doc struct {
+		SPDXVersion  string `json:"spdxVersion"`
+		Comment      string `json:"comment"`
+		CreationInfo struct {
+			Creators []string `json:"creators"`
+			Created  string   `json:"created"`
+		} `json:"creationInfo"`
+		Packages []struct {
+			SPDXID        string `json:"SPDXID"`
+			FilesAnalyzed *bool  `json:"filesAnalyzed"`
+			Checksums     []struct {
+				Algorithm string `json:"algorithm"`
+				Value     string `json:"checksumValue"`
+			} `json:"checksums"`
+			ExternalRefs []struct {
+				Comment string `json:"comment"`
+			} `json:"externalRefs"`
+		} `json:"packages"`
+		Files                      []map[string]interface{} `json:"files"`
+		HasExtractedLicensingInfos []map[string]interface{} `json:"hasExtractedLicensingInfos"`
+		Relationships              []map[string]interface{} `json:"relationships"`
+	}
+
+	if err := json.Unmarshal(content, &doc); err != nil {
+		t.Fatalf("failed to read the JSON: %v", err)
+	}
+
+	if doc.SPDXVersion != "SPDX-2.3" || doc.Comment != "built by\nthe release pipeline" {
+		t.Errorf("spdxVersion = %q and comment = %q", doc.SPDXVersion, doc.Comment)
+	}
+
+	if !reflect.DeepEqual(doc.CreationInfo.Creators, []string{"Tool: syft-0.90.0"}) || doc.CreationInfo.Created != "2024-01-02T03:04:05Z" {
+		t.Errorf("creationInfo = %+v", doc.CreationInfo)
+	}
+
+	if len(doc.Packages) != 2 || doc.Packages[0].FilesAnalyzed == nil || *doc.Packages[0].FilesAnalyzed {
+		t.Fatalf("packages = %+v, want 2 with the first not analyzed", doc.Packages)
+	}
+
+	if checksums := doc.Packages[1].Checksums; len(checksums) != 1 || checksums[0].Algorithm != "SHA256" {
+		t.Errorf("checksums = %+v", checksums)
+	}
+
+	if refs := doc.Packages[1].ExternalRefs; len(refs) != 2 || refs[0].Comment != "from the lockfile" {
+		t.Errorf("externalRefs = %+v", refs)
+	}
+
+	if len(doc.Files) != 1 || len(doc.HasExtractedLicensingInfos) != 1 || doc.HasExtractedLicensingInfos[0]["extractedText"] != "a license\nof its own" {
+		t.Errorf("files = %v and hasExtractedLicensingInfos = %v", doc.Files, doc.HasExtractedLicensingInfos)
+	}
+
+	if len(doc.Relationships) != 3 || doc.Relationships[1]["comment"] != "a runtime dependency" {
+		t.Errorf("relationships = %v", doc.Relationships)
+	}
+
+	sbom, err := NormalizeSBOM(content)
+	if err != nil {
+		t.Fatalf("NormalizeSBOM returned an error: %v", err)
+	}
+
+	if sbom.Root == nil || sbom.Root.Ref != "SPDXRef-app" || !reflect.DeepEqual(sbom.Root.Licenses, []string{"Apache-2.0"}) {
+		t.Errorf("Root = %+v, want SPDXRef-app with the declared license", sbom.Root)
+	}
+
+	want := []Component{{
+		Ref:      "SPDXRef-lodash",
+		Name:     "lodash",
+		Version:  "4.17.20",
+		Purl:     "pkg:npm/lodash@4.17.20",
+		CPE:      "cpe:2.3:a:lodash:lodash:4.17.20:*:*:*:*:*:*:*",
+		Licenses: []string{"MIT", "Apache-2.0"},
+	}}
+
+	if !reflect.DeepEqual(sbom.Components, want) {
+		t.Errorf("Components = %+v, want %+v", sbom.Components, want)
+	}
+
+	relationships := []Relationship{
+		{From: "SPDXRef-app", To: "SPDXRef-lodash", Type: RelationshipDependsOn},
+		{From: "SPDXRef-lodash", To: "SPDXRef-license-file", Type: RelationshipContains},
+	}
+
+	if !reflect.DeepEqual(sbom.Relationships, relationships) {
+		t.Errorf("Relationships = %+v, want %+v", sbom.Relationships, relationships)
+	}
+}
+
+func TestSPDXTagValueToJSONErrors(t *testing.T) {
+	header := "SPDXVersion: SPDX-2.3\nSPDXID: SPDXRef-DOCUMENT\n"
+
+	tests := []struct {
+		name    string
+		content string
+		line    int
+		reason  string
+	}{
+		{
+			name:    "unterminated text",
+			content: header + "PackageName: app\nPackageComment: <text>never\nclosed\n",
+			line:    4,
+			reason:  "PackageComment has a <text> value without a closing </text>",
+		},
+		{
+			name:    "relationship without a related element",
+			content: header + "PackageName: app\nSPDXID: SPDXRef-app\nRelationship: SPDXRef-DOCUMENT DESCRIBES\n",
+			line:    5,
+			reason:  "expected a relationship of the form SPDXRef-A TYPE SPDXRef-B",
+		},
+		{
+			name:    "relationship after a multi-line value",
+			content: header + "DocumentComment: <text>one\ntwo</text>\nRelationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-app extra\n",
+			line:    5,
+			reason:  "expected a relationship of the form SPDXRef-A TYPE SPDXRef-B",
+		},
+		{
+			name:    "file tag in a package",
+			content: header + "PackageName: app\nFileType: SOURCE\n",
+			line:    4,
+			reason:  "FileType is a file tag, but it follows a package",
+		},
+		{
+			name:    "package tag in the document",
+			content: header + "PackageVersion: 1.0.0\n",
+			line:    3,
+			reason:  "PackageVersion is a package tag, but it follows a document",
+		},
+		{
+			name:    "external ref outside of a package",
+			content: header + "ExternalRef: PACKAGE-MANAGER purl pkg:npm/app@1.0.0\n",
+			line:    3,
+			reason:  "ExternalRef must follow a PackageName",
+		},
+		{
+			name:    "external ref comment without an external ref",
+			content: header + "PackageName: app\nExternalRefComment: orphaned\n",
+			line:    4,
+			reason:  "ExternalRefComment must follow an ExternalRef",
+		},
+		{
+			name:    "checksum without an algorithm",
+			content: header + "PackageName: app\nPackageChecksum: 2c26b46b\n",
+			line:    4,
+			reason:  "expected a checksum of the form ALGORITHM: VALUE",
+		},
+		{
+			name:    "files analyzed that is not a boolean",
+			content: header + "PackageName: app\nFilesAnalyzed: maybe\n",
+			line:    4,
+			reason:  "expected true or false for FilesAnalyzed",
+		},
+		{
+			name:    "line without a tag",
+			content: header + "\n# a comment\nnot a tag\n",
+			line:    5,
+			reason:  "expected a line of the form Tag: Value",
+		},
+		{
+			name:    "no spdx version",
+			content: "SPDXID: SPDXRef-DOCUMENT\nPackageName: app\n",
+			line:    0,
+			reason:  "expected an SPDXVersion tag of SPDX-2.x",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SPDXTagValueToJSON(strings.NewReader(tt.content))
+
+			var perr *SBOMParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("SPDXTagValueToJSON error = %v, want a *SBOMParseError", err)
+			}
+
+			if perr.Format != SBOMFormatSPDX || perr.Line != tt.line || !strings.HasPrefix(perr.Reason, tt.reason) {
+				t.Errorf("SPDXTagValueToJSON error = %+v, want line %d and a reason starting with %q", perr, tt.line, tt.reason)
+			}
+		})
+	}
+}
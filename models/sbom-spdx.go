@@ -0,0 +1,177 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// spdxDocumentID defines the SPDX identifier of the document itself
+const spdxDocumentID = "SPDXRef-DOCUMENT"
+
+// spdxExternalRef defines a reference from an SPDX package to an external identifier, such as a purl or cpe
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+// spdxPackage defines the fields of an SPDX 2.x package that are normalized
+type spdxPackage struct {
+	SPDXID                string            `json:"SPDXID"`
+	Name                  string            `json:"name"`
+	VersionInfo           string            `json:"versionInfo"`
+	LicenseConcluded      string            `json:"licenseConcluded"`
+	LicenseDeclared       string            `json:"licenseDeclared"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs"`
+}
+
+// spdxRelationship defines a relationship between two SPDX elements
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// spdxDocument defines the fields of an SPDX 2.x JSON document that are normalized
+type spdxDocument struct {
+	DocumentDescribes []string           `json:"documentDescribes"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+// toComponent converts the SPDX package, where the licenses are those concluded by the
+// creator of the SBOM, or those declared by the package when none were concluded
+func (p spdxPackage) toComponent() Component {
+	comp := Component{
+		Ref:      p.SPDXID,
+		Type:     strings.ToLower(strings.ReplaceAll(p.PrimaryPackagePurpose, "_", "-")),
+		Name:     p.Name,
+		Version:  p.VersionInfo,
+		Licenses: LicenseIDs(p.LicenseConcluded),
+	}
+
+	if len(comp.Licenses) == 0 {
+		comp.Licenses = LicenseIDs(p.LicenseDeclared)
+	}
+
+	for _, ref := range p.ExternalRefs {
+		switch ref.ReferenceType {
+		case "purl":
+			if comp.Purl == "" {
+				comp.Purl = ref.ReferenceLocator
+			}
+		case "cpe23Type", "cpe22Type":
+			// a 2.3 formatted string is preferred over a 2.2 uri
+			if comp.CPE == "" || ref.ReferenceType == "cpe23Type" && !strings.HasPrefix(comp.CPE, "cpe:2.3:") {
+				comp.CPE = ref.ReferenceLocator
+			}
+		}
+	}
+
+	return comp
+}
+
+// normalizeSPDXRelationship converts the relationship into the direction of the dependency or containment,
+// i.e. "A DEPENDENCY_OF B" becomes "B DEPENDS_ON A", while relationships of other types are kept as they are
+func normalizeSPDXRelationship(r spdxRelationship) Relationship {
+	from, to := r.SPDXElementID, r.RelatedSPDXElement
+
+	switch r.RelationshipType {
+	case "DEPENDENCY_OF", "DEV_DEPENDENCY_OF", "BUILD_DEPENDENCY_OF", "RUNTIME_DEPENDENCY_OF",
+		"OPTIONAL_DEPENDENCY_OF", "PROVIDED_DEPENDENCY_OF", "TEST_DEPENDENCY_OF":
+		return Relationship{From: to, To: from, Type: RelationshipDependsOn}
+	case "CONTAINED_BY":
+		return Relationship{From: to, To: from, Type: RelationshipContains}
+	}
+
+	return Relationship{From: from, To: to, Type: r.RelationshipType}
+}
+
+// normalizeSPDX reads the packages of an SPDX 2.x JSON or SPDX 3.0 JSON-LD document and the relationships
+// between them, where the package that the document describes is the root, if it describes only one
+func normalizeSPDX(content []byte, specVersion string) (*NormalizedSBOM, error) {
+	if strings.HasPrefix(specVersion, "3.") {
+		return normalizeSPDX3(content)
+	}
+
+	if !strings.HasPrefix(specVersion, "2.") {
+		return nil, fmt.Errorf("%w: unsupported SPDX version %q", ErrInvalidSBOM, specVersion)
+	}
+
+	var doc spdxDocument
+
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSBOM, err)
+	}
+
+	return newSPDXSBOM(doc.DocumentDescribes, doc.Packages, doc.Relationships), nil
+}
+
+// newSPDXSBOM normalizes the packages and relationships of an SPDX document, whichever serialization it was read from
+func newSPDXSBOM(describes []string, packages []spdxPackage, relationships []spdxRelationship) *NormalizedSBOM {
+	sbom := &NormalizedSBOM{
+		Components:    make([]Component, 0, len(packages)),
+		Relationships: []Relationship{},
+	}
+
+	described := append([]string{}, describes...)
+
+	for _, r := range relationships {
+		if r.RelatedSPDXElement == "NOASSERTION" || r.RelatedSPDXElement == "NONE" {
+			continue
+		}
+
+		switch {
+		case r.RelationshipType == "DESCRIBES" && r.SPDXElementID == spdxDocumentID:
+			described = append(described, r.RelatedSPDXElement)
+		case r.RelationshipType == "DESCRIBED_BY" && r.RelatedSPDXElement == spdxDocumentID:
+			described = append(described, r.SPDXElementID)
+		default:
+			sbom.Relationships = append(sbom.Relationships, normalizeSPDXRelationship(r))
+		}
+	}
+
+	rootID := ""
+
+	if unique := uniqueStrings(described); len(unique) == 1 {
+		rootID = unique[0]
+	}
+
+	all := make([]Component, 0, len(packages))
+
+	for _, p := range packages {
+		comp := p.toComponent()
+		all = append(all, comp)
+
+		// the package that the document describes is kept apart, as the metadata component is in CycloneDX
+		if comp.Ref == rootID {
+			sbom.Root = &comp
+			continue
+		}
+
+		sbom.Components = append(sbom.Components, comp)
+	}
+
+	sbom.OperatingSystem = operatingSystem(all...)
+
+	return sbom
+}
+
+// uniqueStrings returns the strings without duplicates, in the order they first appear
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool)
+	unique := []string{}
+
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+
+	return unique
+}
@@ -0,0 +1,253 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// spdx3Element defines the fields of the elements of an SPDX 3.0 JSON-LD graph that are normalized,
+// where the type is the compact name of the class, i.e. "software_Package"
+type spdx3Element struct {
+	Type               string            `json:"type"`
+	SPDXID             string            `json:"spdxId"`
+	ID                 string            `json:"@id"`
+	Name               string            `json:"name"`
+	SpecVersion        string            `json:"specVersion"`
+	RootElement        []json.RawMessage `json:"rootElement"`
+	PackageVersion     string            `json:"software_packageVersion"`
+	PackageURL         string            `json:"software_packageUrl"`
+	PrimaryPurpose     string            `json:"software_primaryPurpose"`
+	LicenseExpression  string            `json:"simplelicensing_licenseExpression"`
+	From               json.RawMessage   `json:"from"`
+	To                 []json.RawMessage `json:"to"`
+	RelationshipType   string            `json:"relationshipType"`
+	ExternalIdentifier []struct {
+		ExternalIdentifierType string `json:"externalIdentifierType"`
+		Identifier             string `json:"identifier"`
+	} `json:"externalIdentifier"`
+}
+
+// class returns the name of the class of the element without its profile, i.e. "Package" for "software_Package"
+func (e spdx3Element) class() string {
+	if i := strings.LastIndex(e.Type, "_"); i >= 0 {
+		return e.Type[i+1:]
+	}
+
+	return e.Type
+}
+
+// id returns the identifier of the element, which is a blank node id for elements such as the CreationInfo
+func (e spdx3Element) id() string {
+	if e.SPDXID != "" {
+		return e.SPDXID
+	}
+
+	return e.ID
+}
+
+// spdx3Ref returns the id of an element that is referenced either by its id or inline
+func spdx3Ref(raw json.RawMessage) string {
+	var id string
+
+	if err := json.Unmarshal(raw, &id); err == nil {
+		return id
+	}
+
+	var element spdx3Element
+
+	if err := json.Unmarshal(raw, &element); err == nil {
+		return element.id()
+	}
+
+	return ""
+}
+
+// spdx3Constant converts a camel case vocabulary value into the upper case used by SPDX 2, i.e. "dependsOn" to "DEPENDS_ON"
+func spdx3Constant(value string) string {
+	var sb strings.Builder
+
+	for i, c := range value {
+		if c >= 'A' && c <= 'Z' && i > 0 {
+			sb.WriteByte('_')
+		}
+
+		sb.WriteRune(c)
+	}
+
+	return strings.ToUpper(sb.String())
+}
+
+// spdx3LicenseID returns the id of a listed license from its IRI, i.e. "MIT" for "https://spdx.org/licenses/MIT"
+func spdx3LicenseID(iri string) string {
+	if i := strings.Index(iri, "spdx.org/licenses/"); i >= 0 {
+		return strings.TrimSuffix(iri[i+len("spdx.org/licenses/"):], ".html")
+	}
+
+	return iri
+}
+
+// spdx3SpecVersion returns the version of the spec from the @context of an SPDX 3 JSON-LD document,
+// i.e. "3.0.1" for "https://spdx.org/rdf/3.0.1/spdx-context.jsonld"
+func spdx3SpecVersion(context json.RawMessage) (string, bool) {
+	match := MustCompile(`spdx\.org/rdf/(3[0-9.]*)`).FindSubmatch(context)
+	if match == nil {
+		return "", false
+	}
+
+	return strings.TrimSuffix(string(match[1]), "."), true
+}
+
+// CheckSPDX3Context checks that a JSON-LD document has the @context of an SPDX 3 document, returning a
+// *SBOMParseError for the @context if it does not, as the document would otherwise not be detected as an sbom
+func CheckSPDX3Context(content []byte) error {
+	var doc struct {
+		Context json.RawMessage `json:"@context"`
+	}
+
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return &SBOMParseError{Format: SBOMFormatSPDX, Reason: err.Error()}
+	}
+
+	if _, ok := spdx3SpecVersion(doc.Context); !ok {
+		return &SBOMParseError{Format: SBOMFormatSPDX, Element: "@context", Reason: "expected the context of an SPDX 3 document, i.e. https://spdx.org/rdf/3.0.1/spdx-context.jsonld"}
+	}
+
+	return nil
+}
+
+// normalizeSPDX3 reads the packages of an SPDX 3.0 JSON-LD document and the relationships between them, where
+// the licenses of a package are those of its hasConcludedLicense relationships, or its hasDeclaredLicense
+// relationships when it has none, and the package that the document or its sbom has as the root element is the root
+func normalizeSPDX3(content []byte) (*NormalizedSBOM, error) {
+	var doc struct {
+		Graph []json.RawMessage `json:"@graph"`
+	}
+
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, &SBOMParseError{Format: SBOMFormatSPDX, Reason: err.Error()}
+	}
+
+	if doc.Graph == nil {
+		return nil, &SBOMParseError{Format: SBOMFormatSPDX, Element: "@graph", Reason: "expected a JSON-LD document with a @graph of elements"}
+	}
+
+	elements := make([]spdx3Element, 0, len(doc.Graph))
+	byID := make(map[string]spdx3Element)
+
+	for i, raw := range doc.Graph {
+		var element spdx3Element
+
+		ref := fmt.Sprintf("@graph[%d]", i)
+
+		if err := json.Unmarshal(raw, &element); err != nil {
+			return nil, &SBOMParseError{Format: SBOMFormatSPDX, Element: ref, Reason: err.Error()}
+		}
+
+		if element.id() != "" {
+			ref = element.id()
+		}
+
+		if element.Type == "" {
+			return nil, &SBOMParseError{Format: SBOMFormatSPDX, Element: ref, Reason: "element has no type"}
+		}
+
+		switch element.class() {
+		case "Package":
+			if element.Name == "" {
+				return nil, &SBOMParseError{Format: SBOMFormatSPDX, Element: ref, Reason: "package has no name"}
+			}
+		case "Relationship":
+			if spdx3Ref(element.From) == "" || len(element.To) == 0 || element.RelationshipType == "" {
+				return nil, &SBOMParseError{Format: SBOMFormatSPDX, Element: ref, Reason: "relationship needs a from, to and relationshipType"}
+			}
+		}
+
+		elements = append(elements, element)
+		byID[element.id()] = element
+	}
+
+	// the licenses are the expressions or listed licenses that the relationships of each package point to
+	concluded := make(map[string][]string)
+	declared := make(map[string][]string)
+
+	packages := []spdxPackage{}
+	relationships := []spdxRelationship{}
+	describes := []string{}
+
+	for _, element := range elements {
+		switch element.class() {
+		case "Package":
+			pkg := spdxPackage{
+				SPDXID:                element.id(),
+				Name:                  element.Name,
+				VersionInfo:           element.PackageVersion,
+				PrimaryPackagePurpose: spdx3Constant(element.PrimaryPurpose),
+			}
+
+			if element.PackageURL != "" {
+				pkg.ExternalRefs = append(pkg.ExternalRefs, spdxExternalRef{ReferenceType: "purl", ReferenceLocator: element.PackageURL})
+			}
+
+			for _, ext := range element.ExternalIdentifier {
+				switch ext.ExternalIdentifierType {
+				case "packageUrl":
+					pkg.ExternalRefs = append(pkg.ExternalRefs, spdxExternalRef{ReferenceType: "purl", ReferenceLocator: ext.Identifier})
+				case "cpe23", "cpe22":
+					pkg.ExternalRefs = append(pkg.ExternalRefs, spdxExternalRef{ReferenceType: ext.ExternalIdentifierType + "Type", ReferenceLocator: ext.Identifier})
+				}
+			}
+
+			packages = append(packages, pkg)
+		case "SpdxDocument", "Sbom":
+			for _, root := range element.RootElement {
+				id := spdx3Ref(root)
+
+				// the root of the document may be the sbom, whose root is the package
+				if nested, ok := byID[id]; ok && nested.class() == "Sbom" {
+					continue
+				}
+
+				describes = append(describes, id)
+			}
+		case "Relationship":
+			from := spdx3Ref(element.From)
+
+			for _, to := range element.To {
+				id := spdx3Ref(to)
+
+				switch element.RelationshipType {
+				case "hasConcludedLicense", "hasDeclaredLicense":
+					licenses := []string{spdx3LicenseID(id)}
+
+					if license, ok := byID[id]; ok && license.LicenseExpression != "" {
+						licenses = LicenseIDs(license.LicenseExpression)
+					}
+
+					if element.RelationshipType == "hasConcludedLicense" {
+						concluded[from] = append(concluded[from], licenses...)
+					} else {
+						declared[from] = append(declared[from], licenses...)
+					}
+				case "describes":
+					describes = append(describes, id)
+				default:
+					relationships = append(relationships, spdxRelationship{
+						SPDXElementID:      from,
+						RelationshipType:   spdx3Constant(element.RelationshipType),
+						RelatedSPDXElement: id,
+					})
+				}
+			}
+		}
+	}
+
+	for i, pkg := range packages {
+		packages[i].LicenseConcluded = strings.Join(concluded[pkg.SPDXID], " AND ")
+		packages[i].LicenseDeclared = strings.Join(declared[pkg.SPDXID], " AND ")
+	}
+
+	return newSPDXSBOM(describes, packages, relationships), nil
+}
@@ -0,0 +1,188 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// spdx3Fixture is an SPDX 3.0.1 JSON-LD document whose root element is an sbom, whose root element is the application
+const spdx3Fixture = `{
+	"@context": "https://spdx.org/rdf/3.0.1/spdx-context.jsonld",
+	"@graph": [
+		{"type": "CreationInfo", "@id": "_:creationinfo", "specVersion": "3.0.1", "created": "2024-01-02T03:04:05Z"},
+		{"type": "SpdxDocument", "spdxId": "urn:doc", "creationInfo": "_:creationinfo", "rootElement": ["urn:sbom"]},
+		{"type": "software_Sbom", "spdxId": "urn:sbom", "creationInfo": "_:creationinfo", "rootElement": ["urn:app"]},
+		{"type": "software_Package", "spdxId": "urn:app", "name": "app", "software_packageVersion": "1.0.0",
+			"software_primaryPurpose": "application"},
+		{"type": "software_Package", "spdxId": "urn:lodash", "name": "lodash", "software_packageVersion": "4.17.20",
+			"software_packageUrl": "pkg:npm/lodash@4.17.20",
+			"externalIdentifier": [{"type": "ExternalIdentifier", "externalIdentifierType": "cpe23", "identifier": "cpe:2.3:a:lodash:lodash:4.17.20:*:*:*:*:*:*:*"}]},
+		{"type": "software_Package", "spdxId": "urn:zlib", "name": "zlib", "software_packageVersion": "1.3",
+			"externalIdentifier": [{"type": "ExternalIdentifier", "externalIdentifierType": "packageUrl", "identifier": "pkg:generic/zlib@1.3"}]},
+		{"type": "simplelicensing_LicenseExpression", "spdxId": "urn:license-lodash", "simplelicensing_licenseExpression": "MIT OR Apache-2.0"},
+		{"type": "Relationship", "spdxId": "urn:rel-1", "from": "urn:app", "to": ["https://spdx.org/licenses/Apache-2.0"], "relationshipType": "hasDeclaredLicense"},
+		{"type": "Relationship", "spdxId": "urn:rel-2", "from": "urn:lodash", "to": ["urn:license-lodash"], "relationshipType": "hasConcludedLicense"},
+		{"type": "Relationship", "spdxId": "urn:rel-3", "from": "urn:lodash", "to": ["https://spdx.org/licenses/MIT"], "relationshipType": "hasDeclaredLicense"},
+		{"type": "Relationship", "spdxId": "urn:rel-4", "from": "urn:app", "to": ["urn:lodash", "urn:zlib"], "relationshipType": "dependsOn"},
+		{"type": "Relationship", "spdxId": "urn:rel-5", "from": {"type": "software_Package", "spdxId": "urn:zlib"}, "to": ["urn:lodash"], "relationshipType": "contains"}
+	]
+}`
+
+func TestNormalizeSPDX3(t *testing.T) {
+	sbom, err := NormalizeSBOM([]byte(spdx3Fixture))
+	if err != nil {
+		t.Fatalf("NormalizeSBOM returned an error: %v", err)
+	}
+
+	if sbom.Format != SBOMFormatSPDX || sbom.SpecVersion != "3.0.1" {
+		t.Errorf("Format = %s and SpecVersion = %s, want SPDX 3.0.1", sbom.Format, sbom.SpecVersion)
+	}
+
+	root := &Component{Ref: "urn:app", Type: "application", Name: "app", Version: "1.0.0", Licenses: []string{"Apache-2.0"}}
+
+	if !reflect.DeepEqual(sbom.Root, root) {
+		t.Errorf("Root = %+v, want %+v", sbom.Root, root)
+	}
+
+	components := []Component{
+		{
+			Ref:      "urn:lodash",
+			Name:     "lodash",
+			Version:  "4.17.20",
+			Purl:     "pkg:npm/lodash@4.17.20",
+			CPE:      "cpe:2.3:a:lodash:lodash:4.17.20:*:*:*:*:*:*:*",
+			Licenses: []string{"MIT", "Apache-2.0"}, // the concluded expression rather than the declared license
+		},
+		{Ref: "urn:zlib", Name: "zlib", Version: "1.3", Purl: "pkg:generic/zlib@1.3", Licenses: []string{}},
+	}
+
+	if !reflect.DeepEqual(sbom.Components, components) {
+		t.Errorf("Components = %+v, want %+v", sbom.Components, components)
+	}
+
+	relationships := []Relationship{
+		{From: "urn:app", To: "urn:lodash", Type: RelationshipDependsOn},
+		{From: "urn:app", To: "urn:zlib", Type: RelationshipDependsOn},
+		{From: "urn:zlib", To: "urn:lodash", Type: RelationshipContains},
+	}
+
+	if !reflect.DeepEqual(sbom.Relationships, relationships) {
+		t.Errorf("Relationships = %+v, want %+v", sbom.Relationships, relationships)
+	}
+}
+
+func TestNormalizeSPDX3Root(t *testing.T) {
+	graph := func(elements string) []byte {
+		return []byte(`{"@context": "https://spdx.org/rdf/3.0.1/spdx-context.jsonld", "@graph": [
+			{"type": "software_Package", "spdxId": "urn:a", "name": "a"},
+			{"type": "software_Package", "spdxId": "urn:b", "name": "b"}` + elements + `]}`)
+	}
+
+	tests := []struct {
+		name       string
+		content    []byte
+		root       string
+		components int
+	}{
+		{"root element of the document", graph(`, {"type": "SpdxDocument", "spdxId": "urn:doc", "rootElement": ["urn:b"]}`), "urn:b", 1},
+		{"root element of the sbom", graph(`, {"type": "software_Sbom", "spdxId": "urn:sbom", "rootElement": ["urn:a"]}`), "urn:a", 1},
+		{"inline root element", graph(`, {"type": "SpdxDocument", "spdxId": "urn:doc", "rootElement": [{"type": "software_Package", "spdxId": "urn:a"}]}`), "urn:a", 1},
+		{"describes relationship", graph(`, {"type": "Relationship", "spdxId": "urn:r", "from": "urn:doc", "to": ["urn:b"], "relationshipType": "describes"}`), "urn:b", 1},
+		{"several root elements", graph(`, {"type": "SpdxDocument", "spdxId": "urn:doc", "rootElement": ["urn:a", "urn:b"]}`), "", 2},
+		{"no root element", graph(""), "", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sbom, err := NormalizeSBOM(tt.content)
+			if err != nil {
+				t.Fatalf("NormalizeSBOM returned an error: %v", err)
+			}
+
+			root := ""
+			if sbom.Root != nil {
+				root = sbom.Root.Ref
+			}
+
+			if root != tt.root {
+				t.Errorf("Root = %q, want %q", root, tt.root)
+			}
+
+			if len(sbom.Components) != tt.components {
+				t.Errorf("Components = %+v, want %d packages that are not the root", sbom.Components, tt.components)
+			}
+		})
+	}
+}
+
+func TestNormalizeSPDX3Errors(t *testing.T) {
+	context := `"@context": "https://spdx.org/rdf/3.0.1/spdx-context.jsonld"`
+
+	tests := []struct {
+		name    string
+		content string
+		element string
+	}{
+		{"no graph", `{` + context + `}`, "@graph"},
+		{"element without a type", `{` + context + `, "@graph": [{"spdxId": "urn:a", "name": "a"}]}`, "urn:a"},
+		{"element without a type or id", `{` + context + `, "@graph": [{"name": "a"}]}`, "@graph[0]"},
+		{"element that is not an object", `{` + context + `, "@graph": [{"type": "software_Package", "spdxId": "urn:a", "name": "a"}, 42]}`, "@graph[1]"},
+		{"package without a name", `{` + context + `, "@graph": [{"type": "software_Package", "spdxId": "urn:a"}]}`, "urn:a"},
+		{"relationship without a to", `{` + context + `, "@graph": [{"type": "Relationship", "spdxId": "urn:r", "from": "urn:a", "relationshipType": "dependsOn"}]}`, "urn:r"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NormalizeSBOM([]byte(tt.content))
+
+			var perr *SBOMParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("NormalizeSBOM error = %v, want a *SBOMParseError", err)
+			}
+
+			if perr.Format != SBOMFormatSPDX || perr.Element != tt.element {
+				t.Errorf("NormalizeSBOM error = %+v, want the %q element", perr, tt.element)
+			}
+		})
+	}
+}
+
+func TestCheckSPDX3Context(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		element string
+		valid   bool
+	}{
+		{"spdx 3.0.1", `{"@context": "https://spdx.org/rdf/3.0.1/spdx-context.jsonld", "@graph": []}`, "", true},
+		{"spdx 3 among other contexts", `{"@context": ["https://schema.org", "https://spdx.org/rdf/3.0.0/spdx-context.jsonld"]}`, "", true},
+		{"another vocabulary", `{"@context": "https://schema.org", "@graph": []}`, "@context", false},
+		{"no context", `{"@graph": []}`, "@context", false},
+		{"spdx 2 json", `{"spdxVersion": "SPDX-2.3", "packages": []}`, "@context", false},
+		{"not json", `<rdf:RDF/>`, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckSPDX3Context([]byte(tt.content))
+
+			if tt.valid {
+				if err != nil {
+					t.Errorf("CheckSPDX3Context returned an error: %v", err)
+				}
+
+				return
+			}
+
+			var perr *SBOMParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("CheckSPDX3Context error = %v, want a *SBOMParseError", err)
+			}
+
+			if perr.Format != SBOMFormatSPDX || perr.Element != tt.element {
+				t.Errorf("CheckSPDX3Context error = %+v, want the %q element", perr, tt.element)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownSBOMFormat defines the error for a document that is not an SBOM in a supported format
+var ErrUnknownSBOMFormat = errors.New("expected a CycloneDX or SPDX sbom")
+
+// ErrInvalidSBOM defines the error for an SBOM that is in a supported format but cannot be read
+var ErrInvalidSBOM = errors.New("invalid sbom")
+
+// SBOMParseError describes where an SBOM failed to parse, by the line of a tag-value
+// document or the element of a JSON-LD document, and why
+type SBOMParseError struct {
+	Format  SBOMFormat `json:"format"`
+	Line    int        `json:"line,omitempty"`
+	Element string     `json:"element,omitempty"`
+	Reason  string     `json:"reason"`
+}
+
+// Error implements the error interface
+func (e *SBOMParseError) Error() string {
+	switch {
+	case e.Line > 0:
+		return fmt.Sprintf("invalid %s sbom at line %d: %s", e.Format, e.Line, e.Reason)
+	case e.Element != "":
+		return fmt.Sprintf("invalid %s sbom at element %q: %s", e.Format, e.Element, e.Reason)
+	}
+
+	return fmt.Sprintf("invalid %s sbom: %s", e.Format, e.Reason)
+}
+
+// Unwrap returns ErrInvalidSBOM, so that a parse error is matched by errors.Is
+func (e *SBOMParseError) Unwrap() error {
+	return ErrInvalidSBOM
+}
+
+// SBOMFormat defines the specification that an SBOM is written in
+type SBOMFormat string
+
+// Define the SBOMFormat constants for the supported specifications
+const (
+	SBOMFormatCycloneDX SBOMFormat = "CycloneDX"
+	SBOMFormatSPDX      SBOMFormat = "SPDX"
+)
+
+// Relationship types between the components of an SBOM
+const (
+	RelationshipDependsOn = "DEPENDS_ON"
+	RelationshipContains  = "CONTAINS"
+)
+
+// ComponentSWID defines the SWID tag of a component, which identifies it when it has no purl
+type ComponentSWID struct {
+	TagID   string `json:"tagId"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// ComponentProperty defines a name/value property of a component
+type ComponentProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Component defines a package of an SBOM in the same form whichever format the SBOM is written in,
+// where the licenses are the SPDX license ids, or names when there is no id, that apply to it
+type Component struct {
+	Ref        string              `json:"ref,omitempty"`
+	Type       string              `json:"type,omitempty"`
+	Name       string              `json:"name"`
+	Version    string              `json:"version,omitempty"`
+	Purl       string              `json:"purl,omitempty"`
+	CPE        string              `json:"cpe,omitempty"`
+	SWID       *ComponentSWID      `json:"swid,omitempty"`
+	Licenses   []string            `json:"licenses"`
+	Properties []ComponentProperty `json:"properties,omitempty"`
+}
+
+// Relationship defines a relationship from one component of an SBOM to another, by their refs
+type Relationship struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type"`
+}
+
+// NormalizedSBOM defines the components of an SBOM and the relationships between them, where the
+// root is the component that the SBOM describes, such as the application or container image
+type NormalizedSBOM struct {
+	Format          SBOMFormat     `json:"format"`
+	SpecVersion     string         `json:"specversion"`
+	OperatingSystem string         `json:"os,omitempty"`
+	Root            *Component     `json:"root,omitempty"`
+	Components      []Component    `json:"components"`
+	Relationships   []Relationship `json:"relationships"`
+}
+
+// DetectSBOMFormat returns the format and specification version of the SBOM
+func DetectSBOMFormat(content []byte) (SBOMFormat, string, error) {
+	var header struct {
+		BOMFormat   string          `json:"bomFormat"`
+		SpecVersion string          `json:"specVersion"`
+		Schema      string          `json:"$schema"`
+		SPDXVersion string          `json:"spdxVersion"`
+		Context     json.RawMessage `json:"@context"`
+		Components  json.RawMessage `json:"components"`
+		Metadata    json.RawMessage `json:"metadata"`
+	}
+
+	if err := json.Unmarshal(content, &header); err != nil {
+		return "", "", fmt.Errorf("%w: %w", ErrUnknownSBOMFormat, err)
+	}
+
+	if version, ok := spdx3SpecVersion(header.Context); ok {
+		return SBOMFormatSPDX, version, nil
+	}
+
+	switch {
+	case header.SPDXVersion != "":
+		return SBOMFormatSPDX, strings.TrimPrefix(header.SPDXVersion, "SPDX-"), nil
+	case header.BOMFormat == string(SBOMFormatCycloneDX), strings.Contains(strings.ToLower(header.Schema), "cyclonedx"):
+		return SBOMFormatCycloneDX, header.SpecVersion, nil
+	case header.BOMFormat == "" && (header.Components != nil || header.Metadata != nil):
+		// older tools leave out the bomFormat, which is otherwise required
+		return SBOMFormatCycloneDX, header.SpecVersion, nil
+	}
+
+	return "", "", ErrUnknownSBOMFormat
+}
+
+// NormalizeSBOM detects the format of the SBOM and reads its components and relationships,
+// with the purls of the components in their canonical form
+func NormalizeSBOM(content []byte) (*NormalizedSBOM, error) {
+	format, specVersion, err := DetectSBOMFormat(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var sbom *NormalizedSBOM
+
+	switch format {
+	case SBOMFormatSPDX:
+		sbom, err = normalizeSPDX(content, specVersion)
+	case SBOMFormatCycloneDX:
+		sbom, err = normalizeCycloneDX(content)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	sbom.Format = format
+	sbom.SpecVersion = specVersion
+
+	for i := range sbom.Components {
+		sbom.Components[i].canonicalize()
+	}
+
+	if sbom.Root != nil {
+		sbom.Root.canonicalize()
+	}
+
+	return sbom, nil
+}
+
+// canonicalize replaces the purl of the component with its canonical form, if it is valid
+func (c *Component) canonicalize() {
+	if canonical, err := CanonicalPURL(c.Purl); err == nil {
+		c.Purl = canonical
+	}
+
+	if c.Licenses == nil {
+		c.Licenses = []string{}
+	}
+}
+
+// operatingSystem returns the name and version of the first operating system component, i.e. "debian-12"
+func operatingSystem(components ...Component) string {
+	for _, c := range components {
+		if c.Type != "operating-system" {
+			continue
+		}
+
+		parts := []string{}
+
+		for _, p := range []string{c.Name, c.Version} {
+			if p != "" {
+				parts = append(parts, p)
+			}
+		}
+
+		return strings.Join(parts, "-")
+	}
+
+	return ""
+}
+
+// LicenseIDs returns the licenses of an SPDX license expression, i.e. "MIT" and "Apache-2.0" for
+// "(MIT OR Apache-2.0)", leaving out the exceptions and the NOASSERTION and NONE values
+func LicenseIDs(expression string) []string {
+	ids := []string{}
+	seen := make(map[string]bool)
+
+	fields := strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(expression))
+
+	for i := 0; i < len(fields); i++ {
+		switch field := fields[i]; strings.ToUpper(field) {
+		case "AND", "OR", "NOASSERTION", "NONE":
+			continue
+		case "WITH":
+			i++ // the exception applies to the license before it
+		default:
+			if !seen[field] {
+				seen[field] = true
+				ids = append(ids, field)
+			}
+		}
+	}
+
+	return ids
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeSBOMErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		unknown bool // the content is not detected as CycloneDX or SPDX, so it is stored without normalizing it
+	}{
+		{"json in another format", `{"name": "app", "dependencies": {"lodash": "4.17.21"}}`, true},
+		{"json array", `[{"name": "lodash"}]`, true},
+		{"not json", `name: app`, true},
+		{"cyclonedx with invalid components", `{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": {"name": "lodash"}}`, false},
+		{"spdx of an unsupported version", `{"spdxVersion": "SPDX-1.2", "packages": []}`, false},
+		{"spdx with invalid packages", `{"spdxVersion": "SPDX-2.3", "packages": "lodash"}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sbom, err := NormalizeSBOM([]byte(tt.content))
+			if err == nil {
+				t.Fatalf("NormalizeSBOM = %+v, want an error", sbom)
+			}
+
+			if unknown := errors.Is(err, ErrUnknownSBOMFormat); unknown != tt.unknown {
+				t.Errorf("NormalizeSBOM error %q is ErrUnknownSBOMFormat = %v, want %v", err, unknown, tt.unknown)
+			}
+
+			if invalid := errors.Is(err, ErrInvalidSBOM); invalid == tt.unknown {
+				t.Errorf("NormalizeSBOM error %q is ErrInvalidSBOM = %v, want %v", err, invalid, !tt.unknown)
+			}
+		})
+	}
+}
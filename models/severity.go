@@ -0,0 +1,179 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/goark/go-cvss/v2/metric"
+	metric_v3 "github.com/goark/go-cvss/v3/metric"
+	cvss40 "github.com/pandatix/go-cvss/40"
+)
+
+// ErrUnknownSeverity defines the error for a severity that cannot be scored
+var ErrUnknownSeverity = errors.New("unknown severity")
+
+// Constants defining where the score of a vulnerability came from
+const (
+	ScoreSourceSeverity         = "severity"
+	ScoreSourceAffectedSeverity = "affected.severity"
+	ScoreSourceDatabaseSpecific = "database_specific.severity"
+)
+
+// textualSeverities maps the textual severities used by GHSA, Ubuntu and Debian to the lowest
+// score of the equivalent CVSS rating, so that they can be ranked alongside CVSS scores
+//
+//nolint:gochecknoglobals // this is read-only and the nicest implementation
+var textualSeverities = map[string]float64{
+	"critical":    9.0,
+	"high":        7.0,
+	"important":   7.0,
+	"moderate":    4.0,
+	"medium":      4.0,
+	"low":         0.1,
+	"negligible":  0.0,
+	"unimportant": 0.0,
+	"none":        0.0,
+}
+
+// VulnerabilityScore defines the score of a vulnerability, along with the
+// source, type and vector of the severity entry that produced it
+type VulnerabilityScore struct {
+	Score    float64      `json:"score"`
+	Severity string       `json:"severity"`
+	Type     SeverityType `json:"type,omitempty"`
+	Vector   string       `json:"vector,omitempty"`
+	Source   string       `json:"source,omitempty"`
+}
+
+// rating returns the CVSS qualitative severity rating for the score
+func rating(score float64) string {
+	switch {
+	case score >= 9.0:
+		return "Critical"
+	case score >= 7.0:
+		return "High"
+	case score >= 4.0:
+		return "Medium"
+	case score >= 0.1:
+		return "Low"
+	}
+
+	return "None"
+}
+
+// ScoreSeverity calculates the score and rating of a single severity entry
+func ScoreSeverity(s Severity) (VulnerabilityScore, error) {
+	vs := VulnerabilityScore{Type: s.Type, Vector: s.Score}
+
+	switch s.Type {
+	case SeverityCVSSV2:
+		bm, err := metric.NewBase().Decode(s.Score)
+		if err != nil {
+			return vs, fmt.Errorf("%w %s %q: %w", ErrUnknownSeverity, s.Type, s.Score, err)
+		}
+
+		vs.Score = bm.Score()
+		vs.Severity = bm.Severity().String()
+	case SeverityCVSSV3:
+		bm, err := metric_v3.NewBase().Decode(s.Score)
+		if err != nil {
+			return vs, fmt.Errorf("%w %s %q: %w", ErrUnknownSeverity, s.Type, s.Score, err)
+		}
+
+		vs.Score = bm.Score()
+		vs.Severity = bm.Severity().String()
+	case SeverityCVSSV4:
+		bm, err := cvss40.ParseVector(s.Score)
+		if err != nil {
+			return vs, fmt.Errorf("%w %s %q: %w", ErrUnknownSeverity, s.Type, s.Score, err)
+		}
+
+		vs.Score = bm.Score()
+		vs.Severity = rating(vs.Score)
+	case SeverityUbuntu:
+		return scoreTextualSeverity(s.Score, s.Type)
+	default:
+		return vs, fmt.Errorf("%w type %s", ErrUnknownSeverity, s.Type)
+	}
+
+	return vs, nil
+}
+
+// scoreTextualSeverity scores a textual severity such as "HIGH" or "moderate"
+func scoreTextualSeverity(str string, t SeverityType) (VulnerabilityScore, error) {
+	score, ok := textualSeverities[strings.ToLower(strings.TrimSpace(str))]
+	if !ok {
+		return VulnerabilityScore{Type: t, Vector: str}, fmt.Errorf("%w %q", ErrUnknownSeverity, str)
+	}
+
+	return VulnerabilityScore{Score: score, Severity: rating(score), Type: t, Vector: str}, nil
+}
+
+// databaseSpecificSeverity returns the textual severity from the database_specific field, if there is one
+func databaseSpecificSeverity(databaseSpecific map[string]interface{}) string {
+	severity, _ := databaseSpecific["severity"].(string)
+
+	return severity
+}
+
+// ScoreVulnerability calculates the score of the vulnerability for the package, using the
+// highest scoring of the severity entries of the vulnerability and those of the affected
+// entries for the package, or if none of those can be scored, the textual severity in the
+// database_specific field of either (i.e. "HIGH" for GHSA or "medium" for Ubuntu).
+//
+// A vulnerability without any severity that can be scored has a score of 0 and a "None" severity.
+func ScoreVulnerability(v Vulnerability, pkg PackageDetails) VulnerabilityScore {
+	best := VulnerabilityScore{Severity: "None"}
+	found := false
+
+	consider := func(vs VulnerabilityScore, err error, source string) {
+		if err != nil || (found && vs.Score <= best.Score) {
+			return
+		}
+
+		vs.Source = source
+		best = vs
+		found = true
+	}
+
+	for _, s := range v.Severity {
+		vs, err := ScoreSeverity(s)
+		consider(vs, err, ScoreSourceSeverity)
+	}
+
+	for _, affected := range v.Affected {
+		if !affectsPackage(affected, pkg) {
+			continue
+		}
+
+		for _, s := range affected.Severity {
+			vs, err := ScoreSeverity(s)
+			consider(vs, err, ScoreSourceAffectedSeverity)
+		}
+	}
+
+	if found {
+		return best
+	}
+
+	if severity := databaseSpecificSeverity(v.DatabaseSpecific); severity != "" {
+		vs, err := scoreTextualSeverity(severity, "")
+		consider(vs, err, ScoreSourceDatabaseSpecific)
+	}
+
+	for _, affected := range v.Affected {
+		if !affectsPackage(affected, pkg) {
+			continue
+		}
+
+		if severity := databaseSpecificSeverity(affected.DatabaseSpecific); severity != "" {
+			vs, err := scoreTextualSeverity(severity, "")
+			consider(vs, err, ScoreSourceDatabaseSpecific)
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+const (
+	cvssV3Critical = "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"
+	cvssV3Medium   = "CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:U/C:L/I:L/A:N"
+	cvssV4Critical = "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"
+	cvssV4High     = "CVSS:4.0/AV:L/AC:L/AT:N/PR:L/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"
+)
+
+func TestScoreSeverity(t *testing.T) {
+	tests := []struct {
+		severity Severity
+		score    float64
+		rating   string
+		err      bool
+	}{
+		{Severity{SeverityCVSSV2, "AV:N/AC:L/Au:N/C:P/I:P/A:P"}, 7.5, "High", false},
+		{Severity{SeverityCVSSV2, "AV:L/AC:H/Au:M/C:N/I:N/A:P"}, 0.8, "Low", false},
+		{Severity{SeverityCVSSV3, cvssV3Critical}, 9.8, "Critical", false},
+		{Severity{SeverityCVSSV3, cvssV3Medium}, 5.4, "Medium", false},
+		{Severity{SeverityCVSSV3, "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"}, 10.0, "Critical", false},
+		{Severity{SeverityCVSSV4, cvssV4Critical}, 9.3, "Critical", false},
+		{Severity{SeverityCVSSV4, cvssV4High}, 8.5, "High", false},
+		{Severity{SeverityCVSSV4, "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:N/VI:N/VA:N/SC:N/SI:N/SA:N"}, 0.0, "None", false},
+		{Severity{SeverityUbuntu, "medium"}, 4.0, "Medium", false},
+		{Severity{SeverityUbuntu, "Negligible"}, 0.0, "None", false},
+		{Severity{SeverityCVSSV3, "CVSS:3.1/AV:N"}, 0, "", true},
+		{Severity{SeverityCVSSV4, cvssV3Critical}, 0, "", true},
+		{Severity{SeverityUbuntu, "urgent"}, 0, "", true},
+		{Severity{"CVSS_V5", "CVSS:5.0/AV:N"}, 0, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.severity.Type)+" "+tt.severity.Score, func(t *testing.T) {
+			got, err := ScoreSeverity(tt.severity)
+
+			if tt.err {
+				if !errors.Is(err, ErrUnknownSeverity) {
+					t.Errorf("ScoreSeverity error = %v, want %v", err, ErrUnknownSeverity)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ScoreSeverity returned an error: %v", err)
+			}
+
+			if got.Score != tt.score || got.Severity != tt.rating || got.Type != tt.severity.Type || got.Vector != tt.severity.Score {
+				t.Errorf("ScoreSeverity = %+v, want a score of %.1f rated %s", got, tt.score, tt.rating)
+			}
+		})
+	}
+}
+
+func TestScoreVulnerability(t *testing.T) {
+	pkg := PackageDetails{Name: "lodash", Version: "4.17.20", Ecosystem: EcosystemNPM, CompareAs: EcosystemNPM}
+
+	// vulnerability returns an advisory of lodash, and of another package that the severities of its affected entry are ignored for
+	vulnerability := func(severity []Severity, affected []Severity, databaseSpecific map[string]interface{}, affectedSpecific map[string]interface{}) Vulnerability {
+		v := ecosystemVulnerability("GHSA-test", EcosystemNPM, "lodash", "0", "4.17.21")
+		v.Severity = severity
+		v.DatabaseSpecific = databaseSpecific
+		v.Affected[0].Severity = affected
+		v.Affected[0].DatabaseSpecific = affectedSpecific
+
+		other := ecosystemVulnerability("GHSA-test", EcosystemNPM, "underscore", "0", "1.13.6").Affected[0]
+		other.Severity = []Severity{{SeverityCVSSV3, "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"}}
+		other.DatabaseSpecific = map[string]interface{}{"severity": "CRITICAL"}
+		v.Affected = append(v.Affected, other)
+
+		return v
+	}
+
+	tests := []struct {
+		name string
+		vuln Vulnerability
+		want VulnerabilityScore
+	}{
+		{
+			name: "cvss v4 severity",
+			vuln: vulnerability([]Severity{{SeverityCVSSV4, cvssV4High}}, nil, nil, nil),
+			want: VulnerabilityScore{Score: 8.5, Severity: "High", Type: SeverityCVSSV4, Vector: cvssV4High, Source: ScoreSourceSeverity},
+		},
+		{
+			name: "highest of the top-level severities",
+			vuln: vulnerability([]Severity{{SeverityCVSSV3, cvssV3Medium}, {SeverityCVSSV4, cvssV4Critical}}, nil, nil, nil),
+			want: VulnerabilityScore{Score: 9.3, Severity: "Critical", Type: SeverityCVSSV4, Vector: cvssV4Critical, Source: ScoreSourceSeverity},
+		},
+		{
+			name: "textual severity that is higher than the cvss severities",
+			vuln: vulnerability([]Severity{{SeverityCVSSV3, cvssV3Medium}, {SeverityUbuntu, "high"}, {SeverityCVSSV3, "CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N"}}, nil, nil, nil),
+			want: VulnerabilityScore{Score: 7.0, Severity: "High", Type: SeverityUbuntu, Vector: "high", Source: ScoreSourceSeverity},
+		},
+		{
+			name: "affected severity that is higher than the top-level one",
+			vuln: vulnerability([]Severity{{SeverityCVSSV3, cvssV3Medium}}, []Severity{{SeverityCVSSV3, cvssV3Critical}}, nil, nil),
+			want: VulnerabilityScore{Score: 9.8, Severity: "Critical", Type: SeverityCVSSV3, Vector: cvssV3Critical, Source: ScoreSourceAffectedSeverity},
+		},
+		{
+			name: "affected severity that is lower than the top-level one",
+			vuln: vulnerability([]Severity{{SeverityCVSSV3, cvssV3Critical}}, []Severity{{SeverityCVSSV3, cvssV3Medium}}, nil, nil),
+			want: VulnerabilityScore{Score: 9.8, Severity: "Critical", Type: SeverityCVSSV3, Vector: cvssV3Critical, Source: ScoreSourceSeverity},
+		},
+		{
+			name: "cvss severity over the database specific severity",
+			vuln: vulnerability([]Severity{{SeverityCVSSV3, cvssV3Medium}}, nil, map[string]interface{}{"severity": "CRITICAL"}, nil),
+			want: VulnerabilityScore{Score: 5.4, Severity: "Medium", Type: SeverityCVSSV3, Vector: cvssV3Medium, Source: ScoreSourceSeverity},
+		},
+		{
+			name: "unscorable severity falls back to the database specific severity",
+			vuln: vulnerability([]Severity{{SeverityCVSSV3, "CVSS:3.1/AV:N"}}, nil, map[string]interface{}{"severity": "MODERATE"}, nil),
+			want: VulnerabilityScore{Score: 4.0, Severity: "Medium", Vector: "MODERATE", Source: ScoreSourceDatabaseSpecific},
+		},
+		{
+			name: "highest of the database specific severities",
+			vuln: vulnerability(nil, nil, map[string]interface{}{"severity": "low"}, map[string]interface{}{"severity": "high"}),
+			want: VulnerabilityScore{Score: 7.0, Severity: "High", Vector: "high", Source: ScoreSourceDatabaseSpecific},
+		},
+		{
+			name: "no severity",
+			vuln: vulnerability(nil, nil, map[string]interface{}{"severity": 5}, nil),
+			want: VulnerabilityScore{Severity: "None"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScoreVulnerability(tt.vuln, pkg); got != tt.want {
+				t.Errorf("ScoreVulnerability = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
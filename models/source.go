@@ -0,0 +1,234 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/package-url/packageurl-go"
+)
+
+// ErrUnknownSourceMapFormat defines the error for a file that is neither a dpkg status nor an apk installed file
+var ErrUnknownSourceMapFormat = errors.New("expected a dpkg status or apk installed file")
+
+// SourcePackage defines the source package that a binary package was built from,
+// which is what Debian, Ubuntu and Alpine advisories are keyed by
+type SourcePackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// parseSourcePackage parses a source package in the form used by purl qualifiers ("openssl@3.0.11-1")
+// or by dpkg ("openssl (3.0.11-1)"), either of which may be only the name
+func parseSourcePackage(str string) SourcePackage {
+	str = strings.TrimSpace(str)
+
+	if name, version, ok := strings.Cut(str, "@"); ok {
+		return SourcePackage{strings.TrimSpace(name), strings.TrimSpace(version)}
+	}
+
+	if name, version, ok := strings.Cut(str, "("); ok {
+		return SourcePackage{strings.TrimSpace(name), strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(version), ")"))}
+	}
+
+	return SourcePackage{Name: str}
+}
+
+// getPURLSource returns the source package from the "upstream" or "source" qualifier, if the purl has one
+func getPURLSource(pkgURL packageurl.PackageURL) SourcePackage {
+	qualifiers := pkgURL.Qualifiers.Map()
+
+	for _, key := range []string{"upstream", "source"} {
+		if value := qualifiers[key]; value != "" {
+			return parseSourcePackage(value)
+		}
+	}
+
+	return SourcePackage{}
+}
+
+// SourceFromProperties returns the source package from the CycloneDX component
+// properties emitted by syft (syft:metadata:source, syft:metadata:originPackage)
+// or trivy (aquasecurity:trivy:SrcName), if there are any
+func SourceFromProperties(properties map[string]string) SourcePackage {
+	if name := properties["aquasecurity:trivy:SrcName"]; name != "" {
+		version := properties["aquasecurity:trivy:SrcVersion"]
+
+		if release := properties["aquasecurity:trivy:SrcRelease"]; release != "" && version != "" {
+			version += "-" + release
+		}
+
+		if epoch := properties["aquasecurity:trivy:SrcEpoch"]; epoch != "" && epoch != "0" && version != "" {
+			version = epoch + ":" + version
+		}
+
+		return SourcePackage{name, version}
+	}
+
+	if name := properties["syft:metadata:source"]; name != "" {
+		return SourcePackage{name, properties["syft:metadata:sourceVersion"]}
+	}
+
+	if name := properties["syft:metadata:originPackage"]; name != "" {
+		return SourcePackage{Name: name}
+	}
+
+	return SourcePackage{}
+}
+
+// SourceMap defines a mapping of binary packages to the source packages they were built from, for
+// SBOMs that do not record the source package, which can be loaded from dpkg status or apk installed files
+type SourceMap struct {
+	mu      sync.RWMutex
+	entries map[Ecosystem]map[string]SourcePackage
+}
+
+// NewSourceMap returns an empty SourceMap
+func NewSourceMap() *SourceMap {
+	return &SourceMap{entries: make(map[Ecosystem]map[string]SourcePackage)}
+}
+
+// Add maps the binary package of the ecosystem to the source package
+func (m *SourceMap) Add(ecosystem Ecosystem, binary string, source SourcePackage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.entries[ecosystem] == nil {
+		m.entries[ecosystem] = make(map[string]SourcePackage)
+	}
+
+	m.entries[ecosystem][binary] = source
+}
+
+// Lookup returns the source package of the binary package of the ecosystem, if it is known
+func (m *SourceMap) Lookup(ecosystem Ecosystem, binary string) (SourcePackage, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	source, ok := m.entries[ecosystem][binary]
+
+	return source, ok
+}
+
+// Len returns the number of binary packages that are mapped
+func (m *SourceMap) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	n := 0
+
+	for _, entries := range m.entries {
+		n += len(entries)
+	}
+
+	return n
+}
+
+// readStanzas reads the blank line separated stanzas of "key<sep>value" fields used by both
+// dpkg status and apk installed files, calling add with the fields of each stanza
+func readStanzas(r io.Reader, sep string, add func(fields map[string]string)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	fields := make(map[string]string)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.TrimSpace(line) == "" {
+			if len(fields) > 0 {
+				add(fields)
+			}
+
+			fields = make(map[string]string)
+
+			continue
+		}
+
+		// continuation lines (i.e. of a dpkg description) start with whitespace
+		if line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+
+		if key, value, ok := strings.Cut(line, sep); ok {
+			fields[key] = strings.TrimSpace(value)
+		}
+	}
+
+	if len(fields) > 0 {
+		add(fields)
+	}
+
+	return scanner.Err()
+}
+
+// LoadDpkgStatus adds the packages of a dpkg status file (i.e. /var/lib/dpkg/status) for both
+// Debian and Ubuntu, where the source package is the "Source" field, or the package itself if
+// there is no "Source" field, returning the number of packages that were added
+func (m *SourceMap) LoadDpkgStatus(r io.Reader) (int, error) {
+	n := 0
+
+	err := readStanzas(r, ":", func(fields map[string]string) {
+		binary := fields["Package"]
+		if binary == "" {
+			return
+		}
+
+		source := SourcePackage{Name: binary}
+
+		if fields["Source"] != "" {
+			source = parseSourcePackage(fields["Source"])
+		}
+
+		// the source version is only given when it differs from the binary version
+		if source.Version == "" {
+			source.Version = fields["Version"]
+		}
+
+		m.Add(EcosystemDebian, binary, source)
+		m.Add(EcosystemUbuntu, binary, source)
+		n++
+	})
+
+	return n, err
+}
+
+// LoadApkInstalled adds the packages of an apk installed file (i.e. /lib/apk/db/installed),
+// where the source package is the origin ("o:") field, returning the number of packages that were added
+func (m *SourceMap) LoadApkInstalled(r io.Reader) (int, error) {
+	n := 0
+
+	err := readStanzas(r, ":", func(fields map[string]string) {
+		binary := fields["P"]
+		if binary == "" || fields["o"] == "" {
+			return
+		}
+
+		m.Add(EcosystemAlpine, binary, SourcePackage{fields["o"], fields["V"]})
+		n++
+	})
+
+	return n, err
+}
+
+// Load adds the packages of either a dpkg status or an apk installed file, based on its first field
+func (m *SourceMap) Load(r io.Reader) (int, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+
+	switch first := bytes.TrimSpace(data); {
+	case bytes.HasPrefix(first, []byte("Package:")):
+		return m.LoadDpkgStatus(bytes.NewReader(data))
+	case bytes.HasPrefix(first, []byte("C:")), bytes.HasPrefix(first, []byte("P:")):
+		return m.LoadApkInstalled(bytes.NewReader(data))
+	}
+
+	return 0, ErrUnknownSourceMapFormat
+}
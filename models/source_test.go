@@ -0,0 +1,182 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// dpkgStatusFixture is a dpkg status file of a package built from a source package of the same version, one built from
+// a source package of another version, one that is its own source package and one with a multi-line description
+const dpkgStatusFixture = `Package: libssl3
+Status: install ok installed
+Source: openssl
+Version: 3.0.11-1~deb12u2
+Description: Secure Sockets Layer toolkit - shared libraries
+ This package is part of the OpenSSL project's implementation of the SSL
+ and TLS cryptographic protocols.
+
+Package: libsystemd0
+Source: systemd (252.17-1~deb12u1)
+Version: 252.19-1~deb12u1
+
+Package: bash
+Version: 5.2.15-2+b2
+Description: GNU Bourne Again SHell
+ Source: not a field of the package
+
+`
+
+func TestSourceMapLoadDpkgStatus(t *testing.T) {
+	m := NewSourceMap()
+
+	n, err := m.LoadDpkgStatus(strings.NewReader(dpkgStatusFixture))
+	if err != nil {
+		t.Fatalf("LoadDpkgStatus returned an error: %v", err)
+	}
+
+	if n != 3 || m.Len() != 6 {
+		t.Errorf("LoadDpkgStatus = %d and Len = %d, want 3 packages for both Debian and Ubuntu", n, m.Len())
+	}
+
+	tests := []struct {
+		binary string
+		want   SourcePackage
+	}{
+		{"libssl3", SourcePackage{"openssl", "3.0.11-1~deb12u2"}},
+		{"libsystemd0", SourcePackage{"systemd", "252.17-1~deb12u1"}},
+		{"bash", SourcePackage{"bash", "5.2.15-2+b2"}},
+	}
+
+	for _, tt := range tests {
+		for _, ecosystem := range []Ecosystem{EcosystemDebian, EcosystemUbuntu} {
+			if got, ok := m.Lookup(ecosystem, tt.binary); !ok || got != tt.want {
+				t.Errorf("Lookup(%s, %s) = %+v, %v, want %+v", ecosystem, tt.binary, got, ok, tt.want)
+			}
+		}
+	}
+
+	if got, ok := m.Lookup(EcosystemAlpine, "libssl3"); ok {
+		t.Errorf("Lookup(Alpine, libssl3) = %+v, want no source package", got)
+	}
+}
+
+func TestSourceMapLoad(t *testing.T) {
+	apkInstalled := "C:Q1abc=\nP:libcrypto3\nV:3.1.4-r1\no:openssl\n\nP:busybox\nV:1.36.1-r15\no:busybox\n\nP:orphan\nV:1.0-r0\n"
+
+	tests := []struct {
+		name      string
+		content   string
+		ecosystem Ecosystem
+		binary    string
+		want      SourcePackage
+		n         int
+		err       error
+	}{
+		{"dpkg status", dpkgStatusFixture, EcosystemDebian, "libssl3", SourcePackage{"openssl", "3.0.11-1~deb12u2"}, 3, nil},
+		{"apk installed", apkInstalled, EcosystemAlpine, "libcrypto3", SourcePackage{"openssl", "3.1.4-r1"}, 2, nil},
+		{"leading blank lines", "\n\nPackage: libc6\nSource: glibc\nVersion: 2.36-9\n", EcosystemUbuntu, "libc6", SourcePackage{"glibc", "2.36-9"}, 1, nil},
+		{"unknown format", `{"packages": []}`, "", "", SourcePackage{}, 0, ErrUnknownSourceMapFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewSourceMap()
+
+			n, err := m.Load(strings.NewReader(tt.content))
+			if !errors.Is(err, tt.err) || n != tt.n {
+				t.Fatalf("Load = %d, %v, want %d, %v", n, err, tt.n, tt.err)
+			}
+
+			if tt.binary == "" {
+				return
+			}
+
+			if got, ok := m.Lookup(tt.ecosystem, tt.binary); !ok || got != tt.want {
+				t.Errorf("Lookup(%s, %s) = %+v, %v, want %+v", tt.ecosystem, tt.binary, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceOfPURL(t *testing.T) {
+	tests := []struct {
+		purl   string
+		source SourcePackage
+		base   string
+	}{
+		{"pkg:deb/debian/libssl3@3.0.11-1~deb12u2?upstream=openssl", SourcePackage{Name: "openssl"}, "pkg:deb/debian/openssl"},
+		{"pkg:deb/debian/libsystemd0@252.19-1?upstream=systemd%40252.17-1&distro=debian-12", SourcePackage{"systemd", "252.17-1"}, "pkg:deb/debian/systemd"},
+		{"pkg:apk/alpine/libcrypto3@3.1.4-r1?source=openssl", SourcePackage{Name: "openssl"}, "pkg:apk/alpine/openssl"},
+		{"pkg:deb/debian/bash@5.2.15-2", SourcePackage{}, "pkg:deb/debian/bash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.purl, func(t *testing.T) {
+			pkg, err := PURLToPackage(tt.purl)
+			if err != nil {
+				t.Fatalf("PURLToPackage returned an error: %v", err)
+			}
+
+			if pkg.Source != tt.source {
+				t.Errorf("Source = %+v, want %+v", pkg.Source, tt.source)
+			}
+
+			name := tt.source.Name
+			if name == "" {
+				name = pkg.Name
+			}
+
+			if base, err := SourcePURL(tt.purl, name); err != nil || base != tt.base {
+				t.Errorf("SourcePURL = %q, %v, want %q", base, err, tt.base)
+			}
+		})
+	}
+}
+
+func TestSourceFromProperties(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties map[string]string
+		want       SourcePackage
+	}{
+		{
+			name: "trivy with a release and epoch",
+			properties: map[string]string{
+				"aquasecurity:trivy:SrcName":    "openssl",
+				"aquasecurity:trivy:SrcVersion": "3.0.7",
+				"aquasecurity:trivy:SrcRelease": "25.el9",
+				"aquasecurity:trivy:SrcEpoch":   "1",
+			},
+			want: SourcePackage{"openssl", "1:3.0.7-25.el9"},
+		},
+		{
+			name:       "trivy with a zero epoch",
+			properties: map[string]string{"aquasecurity:trivy:SrcName": "systemd", "aquasecurity:trivy:SrcVersion": "252.17-1", "aquasecurity:trivy:SrcEpoch": "0"},
+			want:       SourcePackage{"systemd", "252.17-1"},
+		},
+		{
+			name:       "syft source",
+			properties: map[string]string{"syft:metadata:source": "openssl", "syft:metadata:sourceVersion": "3.0.11-1"},
+			want:       SourcePackage{"openssl", "3.0.11-1"},
+		},
+		{
+			name:       "syft origin package",
+			properties: map[string]string{"syft:metadata:originPackage": "openssl"},
+			want:       SourcePackage{Name: "openssl"},
+		},
+		{
+			name:       "no source",
+			properties: map[string]string{"syft:package:type": "deb"},
+			want:       SourcePackage{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SourceFromProperties(tt.properties); got != tt.want {
+				t.Errorf("SourceFromProperties = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
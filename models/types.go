@@ -31,6 +31,8 @@ const (
 	EcosystemCRAN          Ecosystem = "CRAN"
 	EcosystemBioconductor  Ecosystem = "Bioconductor"
 	EcosystemSwiftURL      Ecosystem = "SwiftURL"
+	EcosystemUbuntu        Ecosystem = "Ubuntu"
+	EcosystemHackage       Ecosystem = "Hackage"
 )
 
 // Ecosystems defines a list of all the ecosystems
@@ -59,6 +61,8 @@ var Ecosystems = []Ecosystem{
 	EcosystemCRAN,
 	EcosystemBioconductor,
 	EcosystemSwiftURL,
+	EcosystemUbuntu,
+	EcosystemHackage,
 }
 
 // Constants for the different ecosystems
@@ -92,44 +96,88 @@ func KnownEcosystems() []Ecosystem {
 		PipEcosystem,
 		PubEcosystem,
 		ConanEcosystem,
-		// Disabled temporarily,
-		// see https://github.com/google/osv-scanner/pull/128 discussion for additional context
-		// AlpineEcosystem,
+		AlpineEcosystem,
 	}
 }
 
-// used like so: purlEcosystems[PkgURL.Type][PkgURL.Namespace]
-// * means it should match any namespace string
-var purlEcosystems = map[string]map[string]Ecosystem{
-	"apk":      {"alpine": EcosystemAlpine},
-	"cargo":    {"*": EcosystemCratesIO},
-	"deb":      {"debian": EcosystemDebian},
-	"hex":      {"*": EcosystemHex},
-	"golang":   {"*": EcosystemGo},
-	"maven":    {"*": EcosystemMaven},
-	"nuget":    {"*": EcosystemNuGet},
-	"npm":      {"*": EcosystemNPM},
-	"composer": {"*": EcosystemPackagist},
-	"generic":  {"*": EcosystemOSSFuzz},
-	"pypi":     {"*": EcosystemPyPI},
-	"gem":      {"*": EcosystemRubyGems},
+// purlNameRule defines how the namespace and name of a purl make up the name of an OSV package
+type purlNameRule int
+
+const (
+	// purlNameSlash joins the namespace, if there is one, and name with a "/", i.e. "@angular/core" or "github.com/gin-gonic/gin"
+	purlNameSlash purlNameRule = iota
+	// purlNameColon joins the namespace and name with a ":", i.e. "org.apache.logging.log4j:log4j-core"
+	purlNameColon
+	// purlNameOnly uses the name alone, as the namespace is the distribution (i.e. "debian") or vendor
+	purlNameOnly
+)
+
+// purlMapping maps a purl type and namespace to an OSV ecosystem, where a namespace of
+// "*" matches any namespace, and the first mapping for an ecosystem is used to create purls
+type purlMapping struct {
+	Type      string
+	Namespace string
+	Ecosystem Ecosystem
+	Name      purlNameRule
+}
+
+// purlMappings defines the purl types of each ecosystem
+//
+// CocoaPods has no OSV ecosystem, so "cocoapods" purls are not mapped
+//
+// See https://github.com/package-url/purl-spec/blob/master/PURL-TYPES.rst and https://ossf.github.io/osv-schema/#affectedpackage-field
+//
+//nolint:gochecknoglobals // this is read-only and the nicest implementation
+var purlMappings = []purlMapping{
+	{"golang", "*", EcosystemGo, purlNameSlash},
+	{"npm", "*", EcosystemNPM, purlNameSlash},
+	{"generic", "linux", EcosystemLinux, purlNameOnly},
+	{"generic", "android", EcosystemAndroid, purlNameOnly},
+	{"generic", "*", EcosystemOSSFuzz, purlNameSlash},
+	{"pypi", "*", EcosystemPyPI, purlNameSlash},
+	{"gem", "*", EcosystemRubyGems, purlNameSlash},
+	{"cargo", "*", EcosystemCratesIO, purlNameSlash},
+	{"composer", "*", EcosystemPackagist, purlNameSlash},
+	{"maven", "*", EcosystemMaven, purlNameColon},
+	{"nuget", "*", EcosystemNuGet, purlNameSlash},
+	{"deb", "debian", EcosystemDebian, purlNameOnly},
+	{"deb", "ubuntu", EcosystemUbuntu, purlNameOnly},
+	{"apk", "alpine", EcosystemAlpine, purlNameOnly},
+	{"hex", "*", EcosystemHex, purlNameSlash},
+	{"github", "*", EcosystemGitHubActions, purlNameSlash},
+	{"githubactions", "*", EcosystemGitHubActions, purlNameSlash},
+	{"pub", "*", EcosystemPub, purlNameSlash},
+	{"conan", "*", EcosystemConanCenter, purlNameSlash},
+	{"rpm", "rocky", EcosystemRockyLinux, purlNameOnly},
+	{"rpm", "rocky-linux", EcosystemRockyLinux, purlNameOnly},
+	{"rpm", "almalinux", EcosystemAlmaLinux, purlNameOnly},
+	{"rpm", "photon", EcosystemPhotonOS, purlNameOnly},
+	{"bitnami", "*", EcosystemBitnami, purlNameSlash},
+	{"cran", "*", EcosystemCRAN, purlNameSlash},
+	{"bioconductor", "*", EcosystemBioconductor, purlNameSlash},
+	{"swift", "*", EcosystemSwiftURL, purlNameSlash},
+	{"hackage", "*", EcosystemHackage, purlNameSlash},
 }
 
 // PackageInfo defines Specific package information
 type PackageInfo struct {
-	Name      string `json:"name"`
-	Version   string `json:"version"`
-	Ecosystem string `json:"ecosystem"`
-	Commit    string `json:"commit"`
+	Name      string        `json:"name"`
+	Version   string        `json:"version"`
+	Ecosystem string        `json:"ecosystem"`
+	Commit    string        `json:"commit"`
+	Distro    string        `json:"distro,omitempty"`
+	Source    SourcePackage `json:"source,omitempty"`
 }
 
-// SeverityType defines the CVSS format (V2 vs V3)
+// SeverityType defines the severity format (CVSS V2, V3 or V4, or an Ubuntu priority)
 type SeverityType string
 
-// Constants defining the CVSS V2 vs V3 format
+// Constants defining the CVSS V2, V3 and V4 formats and Ubuntu priorities
 const (
 	SeverityCVSSV2 SeverityType = "CVSS_V2"
 	SeverityCVSSV3 SeverityType = "CVSS_V3"
+	SeverityCVSSV4 SeverityType = "CVSS_V4"
+	SeverityUbuntu SeverityType = "Ubuntu"
 )
 
 // RangeType defines what type of range search should be used
@@ -183,4 +231,5 @@ type PackageDetails struct {
 	Commit    string    `json:"commit,omitempty"`
 	Ecosystem Ecosystem `json:"ecosystem,omitempty"`
 	CompareAs Ecosystem `json:"compareAs,omitempty"`
+	Distro    string    `json:"distro,omitempty"`
 }
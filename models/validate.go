@@ -0,0 +1,78 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidVulnerability defines the error for an OSV record that is missing required fields
+var ErrInvalidVulnerability = errors.New("invalid vulnerability")
+
+// Validate checks that the vulnerability has the fields required by the OSV schema,
+// and that each affected entry identifies a package or a git repository
+//
+// See https://ossf.github.io/osv-schema/#required-fields
+func (v Vulnerability) Validate() error {
+	if strings.TrimSpace(v.ID) == "" {
+		return fmt.Errorf("%w: missing id", ErrInvalidVulnerability)
+	}
+
+	if v.Modified.IsZero() {
+		return fmt.Errorf("%w %s: missing modified", ErrInvalidVulnerability, v.ID)
+	}
+
+	for i, affected := range v.Affected {
+		hasRepo := false
+
+		for _, r := range affected.Ranges {
+			switch r.Type {
+			case RangeGit:
+				if r.Repo == "" {
+					return fmt.Errorf("%w %s: affected[%d] has a GIT range without a repo", ErrInvalidVulnerability, v.ID, i)
+				}
+
+				hasRepo = true
+			case RangeSemVer, RangeEcosystem:
+			default:
+				return fmt.Errorf("%w %s: affected[%d] has an unknown range type %q", ErrInvalidVulnerability, v.ID, i, r.Type)
+			}
+
+			if len(r.Events) == 0 {
+				return fmt.Errorf("%w %s: affected[%d] has a range without events", ErrInvalidVulnerability, v.ID, i)
+			}
+		}
+
+		if (affected.Package.Name == "" || affected.Package.Ecosystem == "") && !hasRepo {
+			return fmt.Errorf("%w %s: affected[%d] is missing the package ecosystem or name", ErrInvalidVulnerability, v.ID, i)
+		}
+	}
+
+	return nil
+}
+
+// AffectedPURLs returns the purls, without versions, of the packages affected by the vulnerability, which
+// are the purl of the affected package if it has one, along with the purls for its name as given and normalized
+func (v Vulnerability) AffectedPURLs() []string {
+	var purls []string
+
+	for _, affected := range v.Affected {
+		if affected.Package.Name == "" {
+			continue
+		}
+
+		if base, err := PURLBase(affected.Package.Purl); err == nil {
+			purls = append(purls, base)
+		}
+
+		for _, name := range []string{affected.Package.Name, NormalizeName(affected.Package.Ecosystem, affected.Package.Name)} {
+			if purl, err := PackageToPURL(PackageInfo{Name: name, Ecosystem: string(affected.Package.Ecosystem)}); err == nil {
+				purls = append(purls, purl)
+			}
+		}
+	}
+
+	return UniquePURLs(purls)
+}
@@ -0,0 +1,297 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"math/big"
+	"strings"
+)
+
+// alpineSuffixes are the suffixes understood by apk-tools, in the order they are weighed.
+//
+// The "pre" release suffixes (alpha, beta, pre, rc) sort before a version without
+// a suffix, whereas the "post" release suffixes (cvs, svn, git, hg, p) sort after it.
+//
+// See https://gitlab.alpinelinux.org/alpine/apk-tools/-/blob/master/src/version.c
+//
+//nolint:gochecknoglobals // this is read-only and the nicest implementation
+var alpineSuffixes = []string{"alpha", "beta", "pre", "rc", "", "cvs", "svn", "git", "hg", "p"}
+
+func weighAlpineSuffix(suffix string) int {
+	for i, s := range alpineSuffixes {
+		if s == suffix {
+			return i
+		}
+	}
+
+	return -1
+}
+
+type alpineNumberComponent struct {
+	original string
+	value    *big.Int
+	index    int
+}
+
+// Cmp compares two number components, following the apk-tools rule that
+// all but the first component are compared lexically if either has a leading zero
+func (anc alpineNumberComponent) Cmp(b alpineNumberComponent) int {
+	if anc.index != 0 && b.index != 0 {
+		if strings.HasPrefix(anc.original, "0") || strings.HasPrefix(b.original, "0") {
+			return strings.Compare(anc.original, b.original)
+		}
+	}
+
+	return anc.value.Cmp(b.value)
+}
+
+type alpineSuffix struct {
+	weight int
+	number *big.Int
+}
+
+// AlpineVersion defines the Alpine (apk) Version string
+type AlpineVersion struct {
+	original   string
+	components []alpineNumberComponent
+	letter     string
+	suffixes   []alpineSuffix
+	hash       string
+	revision   *big.Int
+}
+
+func (v AlpineVersion) compareComponents(w AlpineVersion) int {
+	numberOfComponents := min(len(v.components), len(w.components))
+
+	for i := 0; i < numberOfComponents; i++ {
+		if diff := v.components[i].Cmp(w.components[i]); diff != 0 {
+			return diff
+		}
+	}
+
+	// a version with more components is considered greater
+	if len(v.components) > len(w.components) {
+		return +1
+	}
+	if len(v.components) < len(w.components) {
+		return -1
+	}
+
+	return 0
+}
+
+func (v AlpineVersion) compareLetters(w AlpineVersion) int {
+	// a version with a letter is greater than one without
+	if v.letter == "" && w.letter != "" {
+		return -1
+	}
+	if v.letter != "" && w.letter == "" {
+		return +1
+	}
+
+	return strings.Compare(v.letter, w.letter)
+}
+
+func (v AlpineVersion) fetchSuffix(n int) alpineSuffix {
+	if len(v.suffixes) <= n {
+		return alpineSuffix{weighAlpineSuffix(""), big.NewInt(0)}
+	}
+
+	return v.suffixes[n]
+}
+
+func (v AlpineVersion) compareSuffixes(w AlpineVersion) int {
+	numberOfSuffixes := max(len(v.suffixes), len(w.suffixes))
+
+	for i := 0; i < numberOfSuffixes; i++ {
+		vs := v.fetchSuffix(i)
+		ws := w.fetchSuffix(i)
+
+		if vs.weight > ws.weight {
+			return +1
+		}
+		if vs.weight < ws.weight {
+			return -1
+		}
+
+		if diff := vs.number.Cmp(ws.number); diff != 0 {
+			return diff
+		}
+	}
+
+	return 0
+}
+
+func (v AlpineVersion) compareHashes(w AlpineVersion) int {
+	return strings.Compare(v.hash, w.hash)
+}
+
+func (v AlpineVersion) compareRevisions(w AlpineVersion) int {
+	return v.revision.Cmp(w.revision)
+}
+
+func (v AlpineVersion) compare(w AlpineVersion) int {
+	if diff := v.compareComponents(w); diff != 0 {
+		return diff
+	}
+	if diff := v.compareLetters(w); diff != 0 {
+		return diff
+	}
+	if diff := v.compareSuffixes(w); diff != 0 {
+		return diff
+	}
+	if diff := v.compareHashes(w); diff != 0 {
+		return diff
+	}
+	if diff := v.compareRevisions(w); diff != 0 {
+		return diff
+	}
+
+	return 0
+}
+
+// Compare Alpine Version structs
+func (v AlpineVersion) Compare(w Version) int {
+	if aw, ok := w.(AlpineVersion); ok {
+		return v.compare(aw)
+	}
+
+	return v.CompareStr(w.String())
+}
+
+// CompareStr Alpine Version strings
+func (v AlpineVersion) CompareStr(str string) int {
+	w, _ := parseAlpineVersion(str)
+
+	return v.compare(w)
+}
+
+// String returns the Alpine Version as it was originally given
+func (v AlpineVersion) String() string {
+	return v.original
+}
+
+// Canonical returns the Alpine Version rebuilt from its parsed parts, without
+// any trailing characters, zero suffix numbers or a zero revision
+func (v AlpineVersion) Canonical() string {
+	var sb strings.Builder
+
+	for i, c := range v.components {
+		if i > 0 {
+			sb.WriteString(".")
+		}
+
+		sb.WriteString(c.original)
+	}
+
+	sb.WriteString(v.letter)
+
+	for _, s := range v.suffixes {
+		sb.WriteString("_" + alpineSuffixes[s.weight])
+
+		if s.number.Sign() != 0 {
+			sb.WriteString(s.number.String())
+		}
+	}
+
+	if v.hash != "" {
+		sb.WriteString("~" + v.hash)
+	}
+
+	if v.revision.Sign() != 0 {
+		sb.WriteString("-r" + v.revision.String())
+	}
+
+	return sb.String()
+}
+
+// IsPrerelease checks if the Alpine Version has an "alpha", "beta", "pre" or "rc" suffix
+func (v AlpineVersion) IsPrerelease() bool {
+	for _, s := range v.suffixes {
+		if s.weight < weighAlpineSuffix("") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Release returns the number components of the Alpine Version
+func (v AlpineVersion) Release() Components {
+	components := make(Components, 0, len(v.components))
+
+	for _, c := range v.components {
+		components = append(components, c.value)
+	}
+
+	return components.padded()
+}
+
+func parseAlpineNumberComponents(str string) []alpineNumberComponent {
+	var components []alpineNumberComponent
+
+	for i, c := range strings.Split(str, ".") {
+		// the components are made up of only digits, so this cannot fail
+		value, _ := convertToBigInt(c)
+
+		components = append(components, alpineNumberComponent{
+			original: c,
+			value:    value,
+			index:    i,
+		})
+	}
+
+	return components
+}
+
+func parseAlpineSuffixes(str string) []alpineSuffix {
+	var suffixes []alpineSuffix
+
+	re := MustCompile(`_(alpha|beta|pre|rc|cvs|svn|git|hg|p)(\d*)`)
+
+	for _, match := range re.FindAllStringSubmatch(str, -1) {
+		number := big.NewInt(0)
+
+		if match[2] != "" {
+			number, _ = convertToBigInt(match[2])
+		}
+
+		suffixes = append(suffixes, alpineSuffix{weighAlpineSuffix(match[1]), number})
+	}
+
+	return suffixes
+}
+
+// parseAlpineVersion parses a version in the format used by apk-tools:
+//
+//	number{.number}...{letter}{_suffix{number}}...{~hash}{-r#}
+//
+// If the string has anything after the longest valid prefix, the version
+// is still parsed from that prefix but an error is returned.
+func parseAlpineVersion(str string) (AlpineVersion, error) {
+	v := AlpineVersion{original: str, revision: big.NewInt(0)}
+
+	str = strings.TrimSpace(str)
+
+	re := MustCompile(`^(\d+(?:\.\d+)*)([a-z]?)((?:_(?:alpha|beta|pre|rc|cvs|svn|git|hg|p)\d*)*)(?:~([0-9a-f]+))?(?:-r(\d+))?`)
+	match := re.FindStringSubmatch(str)
+
+	if len(match) == 0 {
+		return v, &ParseError{Token: str, Reason: "does not start with a number"}
+	}
+
+	v.components = parseAlpineNumberComponents(match[1])
+	v.letter = match[2]
+	v.suffixes = parseAlpineSuffixes(match[3])
+	v.hash = match[4]
+
+	if match[5] != "" {
+		v.revision, _ = convertToBigInt(match[5])
+	}
+
+	if rest := str[len(match[0]):]; rest != "" {
+		return v, &ParseError{Token: rest, Reason: "unexpected trailing characters"}
+	}
+
+	return v, nil
+}
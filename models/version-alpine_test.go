@@ -0,0 +1,79 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestAlpineVersionCompare(t *testing.T) {
+	// based on the ordering cases of test/version.data in apk-tools
+	expectComparisons(t, "Alpine", []versionComparison{
+		{"1.0", "=", "1.0"},
+		{"1.0", "<", "1.1"},
+		{"2.34", ">", "0.1.0_alpha"},
+		{"0.1.0_alpha", "=", "0.1.0_alpha"},
+		{"0.1.0_alpha", "<", "0.1.3_alpha"},
+		{"0.1.0_alpha2", ">", "0.1.0_alpha"},
+		{"0.1.0_alpha", "<", "0.1.0_beta"},
+		{"0.1.0_beta", "<", "0.1.0_pre"},
+		{"0.1.0_pre", "<", "0.1.0_rc"},
+		{"0.1.0_rc", "<", "0.1.0"},
+		{"0.1.0", "<", "0.1.0_cvs"},
+		{"0.1.0_cvs", "<", "0.1.0_svn"},
+		{"0.1.0_svn", "<", "0.1.0_git"},
+		{"0.1.0_git", "<", "0.1.0_hg"},
+		{"0.1.0_hg", "<", "0.1.0_p"},
+		{"0.1.0_p", "<", "0.1.0_p1"},
+		{"1.2.3", "<", "1.2.10"},
+		{"1.0", "<", "1.0.0"},
+		{"1.0a", ">", "1.0"},
+		{"1.0b", ">", "1.0a"},
+		{"1.0", "<", "1.0-r1"},
+		{"1.0-r9", "<", "1.0-r10"},
+		{"1.0-r0", "=", "1.0"},
+		{"1.0_rc1-r5", "<", "1.0"},
+		{"1.0_alpha1_p2", ">", "1.0_alpha1"},
+		// all but the first component are compared lexically if either has a leading zero
+		{"1.01", "<", "1.1"},
+		{"1.001", "<", "1.01"},
+		{"1.010", ">", "1.01"},
+		{"01.1", "=", "1.1"},
+	})
+}
+
+func TestAlpineVersionParseErrors(t *testing.T) {
+	expectParseErrors(t, "Alpine", []string{
+		"foo",
+		"r1",
+		"1..0",
+		"1.0-",
+		"1.0-r",
+		"1.0_foo",
+		"1.0ab",
+	})
+}
+
+func TestAlpineVersionCanonical(t *testing.T) {
+	tests := []struct {
+		version   string
+		canonical string
+		pre       bool
+	}{
+		{"1.0", "1.0", false},
+		{"1.0-r0", "1.0", false},
+		{"1.0_rc0-r2", "1.0_rc-r2", true},
+		{"1.2.3a_p1", "1.2.3a_p1", false},
+		{"1.0_beta2", "1.0_beta2", true},
+	}
+
+	for _, tt := range tests {
+		v := MustParse(tt.version, "Alpine")
+
+		if got := v.Canonical(); got != tt.canonical {
+			t.Errorf("Canonical(%q) = %q, want %q", tt.version, got, tt.canonical)
+		}
+
+		if got := v.IsPrerelease(); got != tt.pre {
+			t.Errorf("IsPrerelease(%q) = %v, want %v", tt.version, got, tt.pre)
+		}
+	}
+}
@@ -37,7 +37,10 @@ func splitDebianDigitPrefix(str string) (*big.Int, string) {
 		i = len(str)
 	}
 
-	return convertToBigIntOrPanic(str[:i]), str[i:]
+	// the prefix is made up of only digits, so this cannot fail
+	prefix, _ := convertToBigInt(str[:i])
+
+	return prefix, str[i:]
 }
 
 func splitDebianNonDigitPrefix(str string) (string, string) {
@@ -118,13 +121,13 @@ func compareDebianVersions(a, b string) int {
 
 // DebianVersion defines the Debian Version String
 type DebianVersion struct {
+	original string
 	epoch    *big.Int
 	upstream string
 	revision string
 }
 
-// Compare Debian Version structs
-func (v DebianVersion) Compare(w DebianVersion) int {
+func (v DebianVersion) compare(w DebianVersion) int {
 	if diff := v.epoch.Cmp(w.epoch); diff != 0 {
 		return diff
 	}
@@ -138,29 +141,107 @@ func (v DebianVersion) Compare(w DebianVersion) int {
 	return 0
 }
 
+// Compare Debian Version structs
+func (v DebianVersion) Compare(w Version) int {
+	if dw, ok := w.(DebianVersion); ok {
+		return v.compare(dw)
+	}
+
+	return v.CompareStr(w.String())
+}
+
 // CompareStr Debian Version strings
 func (v DebianVersion) CompareStr(str string) int {
-	return v.Compare(parseDebianVersion(str))
+	w, _ := parseDebianVersion(str)
+
+	return v.compare(w)
+}
+
+// String returns the Debian Version as it was originally given
+func (v DebianVersion) String() string {
+	return v.original
+}
+
+// Canonical returns the Debian Version without a zero epoch or revision
+func (v DebianVersion) Canonical() string {
+	str := v.upstream
+
+	if v.epoch.Sign() != 0 {
+		str = v.epoch.String() + ":" + str
+	}
+
+	if v.revision != "0" && v.revision != "" {
+		str += "-" + v.revision
+	}
+
+	return str
+}
+
+// IsPrerelease checks if the upstream version of the Debian Version has a "~",
+// which is how Debian packages mark versions that sort before a release
+func (v DebianVersion) IsPrerelease() bool {
+	return strings.Contains(v.upstream, "~")
+}
+
+// Release returns the numeric components at the start of the upstream version
+func (v DebianVersion) Release() Components {
+	return leadingComponents(v.upstream).padded()
+}
+
+// validateDebianVersion checks the upstream version and revision against the rules enforced by dpkg,
+// along with that their dots separate components rather than being repeated or at either end
+//
+// See https://www.debian.org/doc/debian-policy/ch-controlfields.html#version
+func validateDebianVersion(upstream, revision string, hasRevision bool) error {
+	switch {
+	case upstream == "":
+		return &ParseError{Token: upstream, Reason: "empty upstream version"}
+	case !isDigit(upstream[0]):
+		return &ParseError{Token: upstream, Reason: "upstream version does not start with a digit"}
+	case !MustCompile(`^[A-Za-z0-9.+~:-]+$`).MatchString(upstream):
+		return &ParseError{Token: upstream, Reason: "invalid character in upstream version"}
+	case hasRevision && revision == "":
+		return &ParseError{Token: revision, Reason: "empty revision"}
+	case hasRevision && !MustCompile(`^[A-Za-z0-9.+~]+$`).MatchString(revision):
+		return &ParseError{Token: revision, Reason: "invalid character in revision"}
+	}
+
+	if err := validateSeparators(upstream); err != nil {
+		return err
+	}
+
+	return validateSeparators(revision)
 }
 
-func parseDebianVersion(str string) DebianVersion {
+func parseDebianVersion(str string) (DebianVersion, error) {
 	var upstream, revision string
+	var err error
 
+	original := str
 	str = strings.TrimSpace(str)
 	epoch := big.NewInt(0)
 
 	if strings.Contains(str, ":") {
 		var e string
 		e, str = splitAround(str, ":", false)
-		epoch = convertToBigIntOrPanic(e)
+
+		if epoch, err = convertToBigIntOrError(e); err != nil {
+			epoch = big.NewInt(0)
+		}
 	}
 
-	if strings.Contains(str, "-") {
+	hasRevision := strings.Contains(str, "-")
+
+	if hasRevision {
 		upstream, revision = splitAround(str, "-", true)
 	} else {
 		upstream = str
 		revision = "0"
 	}
 
-	return DebianVersion{epoch, upstream, revision}
+	if err == nil {
+		err = validateDebianVersion(upstream, revision, hasRevision)
+	}
+
+	return DebianVersion{original, epoch, upstream, revision}, err
 }
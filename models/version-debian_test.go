@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestDebianVersionCompare(t *testing.T) {
+	for _, ecosystem := range []Ecosystem{"Debian", "Ubuntu"} {
+		expectComparisons(t, ecosystem, []versionComparison{
+			{"1.0", "=", "1.0"},
+			{"1.0", "=", "0:1.0"},
+			{"1.0", "=", "1.0-0"},
+			{"1.0", "<", "1.0-1"},
+			{"1.0-1", "<", "1.0-2"},
+			{"1.0-9", "<", "1.0-10"},
+			{"1:0.1", ">", "9.9"},
+			{"1.0~rc1", "<", "1.0"},
+			{"1.0~~", "<", "1.0~"},
+			{"1.0~", "<", "1.0"},
+			{"1.0", "<", "1.0+dfsg"},
+			{"1.0", "<", "1.0a"},
+			{"1.0a", "<", "1.0+"},
+			{"2.30-1+deb12u1", ">", "2.30-1"},
+			{"1.2.3-1ubuntu0.1", "<", "1.2.3-1ubuntu0.2"},
+			{"7.88.1-10+deb12u5", "<", "7.88.1-10+deb12u6"},
+			{"1:2.3-4", "=", "1:2.3-4"},
+		})
+	}
+}
+
+func TestDebianVersionParseErrors(t *testing.T) {
+	expectParseErrors(t, "Debian", []string{
+		"foo",
+		"v1.0",
+		".1",
+		"1..2",
+		"1.0.",
+		"1.0-",
+		"1.0_p1",
+		"1.0-1.el8_1",
+		"a:1.0",
+		"1:",
+	})
+}
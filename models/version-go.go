@@ -0,0 +1,158 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// GoVersion defines a Go module Version string, which may be a pseudo-version
+// (i.e. v0.0.0-20250811191247-51f88131bc50) and/or have a +incompatible suffix
+type GoVersion struct {
+	Original     string
+	canonical    string
+	incompatible bool
+	pseudo       bool
+	pseudoBase   string
+	pseudoTime   time.Time
+}
+
+// comparePseudo compares two pseudo-versions that are derived from the same base
+// version based on the time of the commit they were generated from, returning
+// false if either version is not a pseudo-version or their bases differ
+func (v GoVersion) comparePseudo(w GoVersion) (int, bool) {
+	if !v.pseudo || !w.pseudo || v.pseudoBase != w.pseudoBase {
+		return 0, false
+	}
+
+	if v.pseudoTime.Before(w.pseudoTime) {
+		return -1, true
+	}
+	if v.pseudoTime.After(w.pseudoTime) {
+		return +1, true
+	}
+
+	return 0, false
+}
+
+func (v GoVersion) compare(w GoVersion) int {
+	// versions that are not valid semver cannot be compared by the go tooling,
+	// so fallback to our more lenient semver implementation
+	if v.canonical == "" || w.canonical == "" {
+		sv, _ := parseSemverVersion(v.Original)
+
+		return sv.CompareStr(w.Original)
+	}
+
+	if diff, ok := v.comparePseudo(w); ok {
+		return diff
+	}
+
+	return semver.Compare(v.canonical, w.canonical)
+}
+
+// Compare Go Version structs
+//
+// Pseudo-versions are constructed so that they sort after their base version
+// and before the next release, so plain semver precedence orders them by base
+// and then by timestamp; the +incompatible suffix is build metadata and so is
+// not considered when comparing.
+func (v GoVersion) Compare(w Version) int {
+	if gw, ok := w.(GoVersion); ok {
+		return v.compare(gw)
+	}
+
+	return v.CompareStr(w.String())
+}
+
+// CompareStr Go Version strings
+func (v GoVersion) CompareStr(str string) int {
+	w, _ := parseGoVersion(str)
+
+	return v.compare(w)
+}
+
+// String returns the Go Version as it was originally given
+func (v GoVersion) String() string {
+	return v.Original
+}
+
+// Canonical returns the Go Version in the form used by the go tooling, with
+// a leading "v", all three components and any +incompatible suffix
+func (v GoVersion) Canonical() string {
+	if v.canonical == "" {
+		return v.Original
+	}
+
+	if v.incompatible {
+		return v.canonical + "+incompatible"
+	}
+
+	return v.canonical
+}
+
+// IsPrerelease checks if the Go Version has a prerelease, which includes pseudo-versions
+func (v GoVersion) IsPrerelease() bool {
+	if v.canonical == "" {
+		sv, _ := parseSemverVersion(v.Original)
+
+		return sv.IsPrerelease()
+	}
+
+	return semver.Prerelease(v.canonical) != ""
+}
+
+// Release returns the major, minor and patch components of the Go Version
+func (v GoVersion) Release() Components {
+	if v.canonical == "" {
+		sv, _ := parseSemverVersion(v.Original)
+
+		return sv.Release()
+	}
+
+	return leadingComponents(strings.TrimPrefix(v.canonical, "v")).padded()
+}
+
+func parseGoVersion(str string) (GoVersion, error) {
+	str = strings.TrimSpace(str)
+	v := GoVersion{Original: str}
+
+	// OSV records Go versions without the leading "v" used by the go tooling
+	vstr := str
+	if !strings.HasPrefix(vstr, "v") {
+		vstr = "v" + vstr
+	}
+
+	// versions that are not valid semver are still accepted as long as
+	// they can be compared using our more lenient semver implementation
+	if !semver.IsValid(vstr) {
+		_, err := parseSemverVersion(str)
+
+		return v, err
+	}
+
+	v.canonical = semver.Canonical(vstr)
+	v.incompatible = semver.Build(vstr) == "+incompatible"
+
+	if module.IsPseudoVersion(vstr) {
+		base, err := module.PseudoVersionBase(vstr)
+		if err != nil {
+			return v, &ParseError{Token: vstr, Reason: err.Error()}
+		}
+
+		t, err := module.PseudoVersionTime(vstr)
+		if err != nil {
+			return v, &ParseError{Token: vstr, Reason: err.Error()}
+		}
+
+		v.pseudo = true
+		v.pseudoBase = base
+		v.pseudoTime = t
+	}
+
+	return v, nil
+}
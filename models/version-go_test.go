@@ -0,0 +1,66 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestGoVersionCompare(t *testing.T) {
+	expectComparisons(t, "Go", []versionComparison{
+		{"1.2.3", "=", "v1.2.3"},
+		{"v1.2", "=", "v1.2.0"},
+		{"v1.2.3", "<", "v1.2.10"},
+		{"v1.2.3-pre", "<", "v1.2.3"},
+		{"v1.2.3-alpha", "<", "v1.2.3-beta"},
+		// pseudo-versions without a base sort before every release
+		{"v0.0.0-20191109021931-daa7c04131f5", "<", "v0.1.0"},
+		{"v0.0.0-20191109021931-daa7c04131f5", "<", "v0.0.0-20200101000000-0123456789ab"},
+		// pseudo-versions after a release sort after it and before the next patch
+		{"v1.2.4-0.20191109021931-daa7c04131f5", ">", "v1.2.3"},
+		{"v1.2.4-0.20191109021931-daa7c04131f5", "<", "v1.2.4"},
+		{"v1.2.4-0.20191109021931-daa7c04131f5", "<", "v1.2.4-0.20200101000000-0123456789ab"},
+		// pseudo-versions after a prerelease sort after it and before the release
+		{"v1.2.3-pre.0.20191109021931-daa7c04131f5", ">", "v1.2.3-pre"},
+		{"v1.2.3-pre.0.20191109021931-daa7c04131f5", "<", "v1.2.3"},
+		// the +incompatible suffix is build metadata and not compared
+		{"v2.0.0+incompatible", "=", "v2.0.0"},
+		{"v2.0.0+incompatible", "<", "v2.0.1+incompatible"},
+		{"v2.1.0+incompatible", ">", "v2.0.5"},
+	})
+}
+
+func TestGoVersionCanonical(t *testing.T) {
+	tests := []struct {
+		version   string
+		canonical string
+		pre       bool
+		release   []int64
+	}{
+		{"1.2", "v1.2.0", false, []int64{1, 2, 0}},
+		{"v1.2.3", "v1.2.3", false, []int64{1, 2, 3}},
+		{"v2.0.0+incompatible", "v2.0.0+incompatible", false, []int64{2, 0, 0}},
+		{"v1.2.4-0.20191109021931-daa7c04131f5", "v1.2.4-0.20191109021931-daa7c04131f5", true, []int64{1, 2, 4}},
+		{"v1.0.0-rc.1", "v1.0.0-rc.1", true, []int64{1, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		v := MustParse(tt.version, "Go")
+
+		if got := v.Canonical(); got != tt.canonical {
+			t.Errorf("Canonical(%q) = %q, want %q", tt.version, got, tt.canonical)
+		}
+
+		if got := v.IsPrerelease(); got != tt.pre {
+			t.Errorf("IsPrerelease(%q) = %v, want %v", tt.version, got, tt.pre)
+		}
+
+		expectRelease(t, v, tt.release)
+	}
+}
+
+func TestGoVersionParseErrors(t *testing.T) {
+	expectParseErrors(t, "Go", []string{
+		"foo",
+		"v",
+		"vX.Y.Z",
+	})
+}
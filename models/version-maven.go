@@ -3,7 +3,6 @@
 package models
 
 import (
-	"fmt"
 	"sort"
 	"strings"
 )
@@ -21,19 +20,16 @@ func (vt *mavenVersionToken) qualifierOrder() int {
 		if vt.prefix == "-" {
 			return 2
 		}
-		if vt.prefix == "." {
-			return 3
-		}
+
+		return 3
 	}
 
 	if vt.prefix == "-" {
 		return 1
 	}
-	if vt.prefix == "." {
-		return 0
-	}
 
-	panic(fmt.Sprintf("unknown prefix '%s'", vt.prefix))
+	// the first token has no preceding prefix, which is treated the same as "."
+	return 0
 }
 
 func (vt *mavenVersionToken) shouldTrim() bool {
@@ -101,7 +97,8 @@ func (vt *mavenVersionToken) lessThan(wt mavenVersionToken) bool {
 
 // MavenVersion defines a maven version token
 type MavenVersion struct {
-	tokens []mavenVersionToken
+	original string
+	tokens   []mavenVersionToken
 }
 
 func (mv MavenVersion) equal(mw MavenVersion) bool {
@@ -119,22 +116,20 @@ func (mv MavenVersion) equal(mw MavenVersion) bool {
 }
 
 func newMavenNullVersionToken(token mavenVersionToken) mavenVersionToken {
-	if token.prefix == "." {
-		value := "0"
-
-		// "sp" is the only qualifier that comes after an empty value, and because
-		// of the way the comparator is implemented, we have to express that here
-		if token.value == "sp" {
-			value = ""
-		}
-
-		return mavenVersionToken{".", value, true}
-	}
 	if token.prefix == "-" {
 		return mavenVersionToken{"-", "", true}
 	}
 
-	panic(fmt.Sprintf("unknown prefix '%s' (value: '%s')", token.prefix, token.value))
+	// the first token has no preceding prefix, which is treated the same as "."
+	value := "0"
+
+	// "sp" is the only qualifier that comes after an empty value, and because
+	// of the way the comparator is implemented, we have to express that here
+	if token.value == "sp" {
+		value = ""
+	}
+
+	return mavenVersionToken{".", value, true}
 }
 
 func (mv MavenVersion) lessThan(mw MavenVersion) bool {
@@ -301,11 +296,10 @@ func newMavenVersion(str string) MavenVersion {
 		i--
 	}
 
-	return MavenVersion{tokens}
+	return MavenVersion{str, tokens}
 }
 
-// Compare Maven Version structs
-func (mv MavenVersion) Compare(w MavenVersion) int {
+func (mv MavenVersion) compare(w MavenVersion) int {
 	if mv.equal(w) {
 		return 0
 	}
@@ -316,11 +310,80 @@ func (mv MavenVersion) Compare(w MavenVersion) int {
 	return +1
 }
 
+// Compare Maven Version structs
+func (mv MavenVersion) Compare(w Version) int {
+	if mw, ok := w.(MavenVersion); ok {
+		return mv.compare(mw)
+	}
+
+	return mv.CompareStr(w.String())
+}
+
 // CompareStr Maven Version strings
 func (mv MavenVersion) CompareStr(str string) int {
-	return mv.Compare(parseMavenVersion(str))
+	mw, _ := parseMavenVersion(str)
+
+	return mv.compare(mw)
+}
+
+// String returns the Maven Version as it was originally given
+func (mv MavenVersion) String() string {
+	return mv.original
+}
+
+// Canonical returns the Maven Version rebuilt from its tokens, so that
+// qualifiers are lowercase and aliases and trailing "null" values are removed
+func (mv MavenVersion) Canonical() string {
+	var sb strings.Builder
+
+	for i, token := range mv.tokens {
+		if i > 0 {
+			sb.WriteString(token.prefix)
+		}
+
+		sb.WriteString(token.value)
+	}
+
+	return sb.String()
+}
+
+// IsPrerelease checks if the Maven Version has a qualifier that comes before a release,
+// such as "alpha", "beta", "milestone", "rc" or "snapshot"
+func (mv MavenVersion) IsPrerelease() bool {
+	for _, token := range mv.tokens {
+		if _, isNumber := convertToBigInt(token.value); !isNumber && findKeywordOrder(token.value) < findKeywordOrder("") {
+			return true
+		}
+	}
+
+	return false
 }
 
-func parseMavenVersion(str string) MavenVersion {
-	return newMavenVersion(str)
+// Release returns the numeric tokens at the start of the Maven Version
+func (mv MavenVersion) Release() Components {
+	var components Components
+
+	for i, token := range mv.tokens {
+		n, isNumber := convertToBigInt(token.value)
+
+		if !isNumber || (i > 0 && token.prefix != ".") {
+			break
+		}
+
+		components = append(components, n)
+	}
+
+	return components.padded()
+}
+
+func parseMavenVersion(str string) (MavenVersion, error) {
+	v := newMavenVersion(str)
+
+	// any string is a Maven version, but only those that start with a number and
+	// have no empty tokens are versions that are released, rather than a typo
+	if !MustCompile(`^\d[A-Za-z0-9_+]*(?:[.-][A-Za-z0-9_+]+)*$`).MatchString(str) {
+		return v, &ParseError{Token: str, Reason: "not a valid maven version"}
+	}
+
+	return v, nil
 }
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestMavenVersionCompare(t *testing.T) {
+	// based on the cases of ComparableVersionTest in maven
+	expectComparisons(t, "Maven", []versionComparison{
+		{"1", "=", "1.0"},
+		{"1", "=", "1.0.0"},
+		{"1.0", "=", "1-0"},
+		{"1", "=", "1-ga"},
+		{"1", "=", "1-final"},
+		{"1", "=", "1.0.0-GA"},
+		{"1-rc", "=", "1-cr"},
+		{"1a1", "=", "1-alpha-1"},
+		{"1b2", "=", "1-beta-2"},
+		{"1m3", "=", "1-milestone-3"},
+		{"1X", "=", "1x"},
+		{"1", "<", "2"},
+		{"1.5", "<", "2"},
+		{"1", "<", "2.5"},
+		{"1.0", "<", "1.1"},
+		{"1.1", "<", "1.2"},
+		{"1.0.0", "<", "1.1"},
+		{"1.0-alpha-1", "<", "1.0"},
+		{"1.0-alpha-1", "<", "1.0-alpha-2"},
+		{"1.0-alpha-1", "<", "1.0-beta-1"},
+		{"1.0-beta-1", "<", "1.0-SNAPSHOT"},
+		{"1.0-SNAPSHOT", "<", "1.0"},
+		{"1.0-alpha-1-SNAPSHOT", "<", "1.0-alpha-1"},
+		{"1.0", "<", "1.0-1"},
+		{"1.0-1", "<", "1.0-2"},
+		{"1.0.0", "<", "1.0-1"},
+		{"2.0-1", "<", "2.0.1"},
+		{"2.0.1-klm", "<", "2.0.1-lmn"},
+		{"2.0.1", "<", "2.0.1-xyz"},
+		{"2.0.1", "<", "2.0.1-123"},
+		{"2.0.1-xyz", "<", "2.0.1-123"},
+		{"1.0-RC1", "<", "1.0"},
+		{"1.0", "<", "1.0-sp"},
+		{"5.3.0.RELEASE", "=", "5.3.0"},
+	})
+}
+
+func TestMavenVersionParseErrors(t *testing.T) {
+	expectParseErrors(t, "Maven", []string{
+		"foo",
+		"v1",
+		".1",
+		"1..2",
+		"1.0-",
+		"1.0.",
+		"1.0~rc1",
+	})
+}
@@ -9,8 +9,7 @@ type NuGetVersion struct {
 	SemverLikeVersion
 }
 
-// Compare Nuget Version structs
-func (v NuGetVersion) Compare(w NuGetVersion) int {
+func (v NuGetVersion) compare(w NuGetVersion) int {
 	if diff := v.Components.Cmp(w.Components); diff != 0 {
 		return diff
 	}
@@ -18,11 +17,43 @@ func (v NuGetVersion) Compare(w NuGetVersion) int {
 	return compareBuildComponents(strings.ToLower(v.Build), strings.ToLower(w.Build))
 }
 
+// Compare Nuget Version structs
+func (v NuGetVersion) Compare(w Version) int {
+	if nw, ok := w.(NuGetVersion); ok {
+		return v.compare(nw)
+	}
+
+	return v.CompareStr(w.String())
+}
+
 // CompareStr Nuget Version strings
 func (v NuGetVersion) CompareStr(str string) int {
-	return v.Compare(parseNuGetVersion(str))
+	w, _ := parseNuGetVersion(str)
+
+	return v.compare(w)
+}
+
+// Canonical returns the Nuget Version in its normalized form, where the fourth
+// component is only kept if it is not zero and the prerelease is lowercase
+func (v NuGetVersion) Canonical() string {
+	if len(v.Components) == 4 && v.Components[3].Sign() == 0 {
+		v.Components = v.Components[:3]
+	}
+
+	return strings.ToLower(v.canonical(3))
+}
+
+// IsPrerelease checks if the Nuget Version has a prerelease
+func (v NuGetVersion) IsPrerelease() bool {
+	return removeBuildMetadata(v.Build) != ""
 }
 
-func parseNuGetVersion(str string) NuGetVersion {
-	return NuGetVersion{ParseSemverLikeVersion(str, 4)}
+func parseNuGetVersion(str string) (NuGetVersion, error) {
+	v := NuGetVersion{ParseSemverLikeVersion(str, 4)}
+
+	if len(v.Components) == 0 {
+		return v, &ParseError{Token: v.Build, Reason: "no numeric components"}
+	}
+
+	return v, nil
 }
@@ -110,19 +110,74 @@ type PackagistVersion struct {
 	Components []string
 }
 
-func parsePackagistVersion(str string) PackagistVersion {
-	return PackagistVersion{
+// isPackagistVersion checks if the string is a version that Composer can normalize, which is either
+// up to four numbers or a date, followed by an optional stability with a number, and "dev"
+//
+// See https://github.com/composer/semver/blob/main/src/VersionParser.php
+func isPackagistVersion(str string) bool {
+	modifier := `(?:[._-]?(?:stable|beta|b|rc|alpha|a|patch|pl|p)(?:[.-]?\d+)*)?(?:[.-]?dev)?(?:\+[0-9a-z.-]+)?$`
+
+	return MustCompile(`(?i)^v?\d+(?:\.\d+){0,3}`+modifier).MatchString(str) ||
+		MustCompile(`(?i)^v?\d{4}(?:[.:-]?\d{2}){1,6}(?:[.:-]?\d{1,3}){0,2}`+modifier).MatchString(str)
+}
+
+func parsePackagistVersion(str string) (PackagistVersion, error) {
+	v := PackagistVersion{
 		str,
 		strings.Split(canonicalizePackagistVersion(str), "."),
 	}
+
+	if !isPackagistVersion(strings.TrimSpace(str)) {
+		return v, &ParseError{Token: str, Reason: "not a valid composer version"}
+	}
+
+	return v, nil
 }
 
 // Compare Packagist Version structs
-func (v PackagistVersion) Compare(w PackagistVersion) int {
-	return comparePackagistComponents(v.Components, w.Components)
+func (v PackagistVersion) Compare(w Version) int {
+	if pw, ok := w.(PackagistVersion); ok {
+		return comparePackagistComponents(v.Components, pw.Components)
+	}
+
+	return v.CompareStr(w.String())
 }
 
 // CompareStr Packagist Version strings
 func (v PackagistVersion) CompareStr(str string) int {
-	return v.Compare(parsePackagistVersion(str))
+	w, _ := parsePackagistVersion(str)
+
+	return comparePackagistComponents(v.Components, w.Components)
+}
+
+// String returns the Packagist Version as it was originally given
+func (v PackagistVersion) String() string {
+	return v.Original
+}
+
+// Canonical returns the components of the Packagist Version separated by dots,
+// i.e. "1.0.0.RC.1" for "v1.0.0-RC1"
+func (v PackagistVersion) Canonical() string {
+	return strings.Join(v.Components, ".")
+}
+
+// IsPrerelease checks if the Packagist Version has a component that
+// comes before a release, such as "dev", "alpha", "beta" or "RC"
+func (v PackagistVersion) IsPrerelease() bool {
+	for _, c := range v.Components {
+		if _, isNumber := convertToBigInt(c); isNumber || c == "" {
+			continue
+		}
+
+		if weighPackagistBuildCharacter(c) < weighPackagistBuildCharacter("#") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Release returns the numeric components at the start of the Packagist Version
+func (v PackagistVersion) Release() Components {
+	return leadingComponents(v.Canonical()).padded()
 }
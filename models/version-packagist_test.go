@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestPackagistVersionCompare(t *testing.T) {
+	expectComparisons(t, "Packagist", []versionComparison{
+		{"1.0", "=", "1.0"},
+		{"v1.0.0", "=", "1.0.0"},
+		{"1.0.0-dev", "<", "1.0.0-alpha1"},
+		{"1.0.0-alpha1", "<", "1.0.0-beta1"},
+		{"1.0.0-beta1", "<", "1.0.0-RC1"},
+		{"1.0.0-RC1", "<", "1.0.0"},
+		{"1.0.0", "<", "1.0.0-p1"},
+		{"1.0.0-RC1", "<", "1.0.0-RC2"},
+		{"1.2.3", "<", "1.2.10"},
+		{"1.0.0.0", "=", "1.0.0.0"},
+		{"2024-01-02", "<", "2024-01-03"},
+	})
+}
+
+func TestPackagistVersionParseErrors(t *testing.T) {
+	expectParseErrors(t, "Packagist", []string{
+		"foo",
+		".1",
+		"1..2",
+		"1.0-",
+		"1.2.3.4.5",
+		"1.0~rc1",
+		"1.0.0-SNAPSHOT",
+	})
+}
@@ -3,6 +3,7 @@
 package models
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"strings"
@@ -10,13 +11,14 @@ import (
 
 // PyPIVersion defines te PyPI Version string
 type PyPIVersion struct {
-	epoch   *big.Int
-	release Components
-	pre     letterAndNumber
-	post    letterAndNumber
-	dev     letterAndNumber
-	local   []string
-	legacy  []string
+	original string
+	epoch    *big.Int
+	release  Components
+	pre      letterAndNumber
+	post     letterAndNumber
+	dev      letterAndNumber
+	local    []string
+	legacy   []string
 }
 
 type letterAndNumber struct {
@@ -24,7 +26,7 @@ type letterAndNumber struct {
 	number *big.Int
 }
 
-func parseLetterVersion(letter, number string) letterAndNumber {
+func parseLetterVersion(letter, number string) (letterAndNumber, error) {
 	if letter != "" {
 		// we consider there to be an implicit 0 in a pre-release
 		// if there is not a numeral associated with it
@@ -54,7 +56,9 @@ func parseLetterVersion(letter, number string) letterAndNumber {
 			letter = "post"
 		}
 
-		return letterAndNumber{letter, convertToBigIntOrPanic(number)}
+		n, err := convertToBigIntOrError(number)
+
+		return letterAndNumber{letter, n}, err
 	}
 
 	if number != "" {
@@ -62,10 +66,12 @@ func parseLetterVersion(letter, number string) letterAndNumber {
 		// the implicit post release syntax (e.g. 1.0-1)
 		letter = "post"
 
-		return letterAndNumber{letter, convertToBigIntOrPanic(number)}
+		n, err := convertToBigIntOrError(number)
+
+		return letterAndNumber{letter, n}, err
 	}
 
-	return letterAndNumber{}
+	return letterAndNumber{}, nil
 }
 
 func parseLocalVersion(local string) (parts []string) {
@@ -135,30 +141,53 @@ func parsePyPILegacyVersion(str string) PyPIVersion {
 	return PyPIVersion{epoch: big.NewInt(-1), legacy: parts}
 }
 
-func parsePyPIVersion(str string) PyPIVersion {
-	str = strings.ToLower(str)
+func parsePyPIVersion(str string) (PyPIVersion, error) {
+	v, err := parsePEP440Version(strings.ToLower(str))
+	v.original = str
+
+	// report the version as it was given rather than lowercased
+	var perr *ParseError
+	if errors.As(err, &perr) && perr.Token == strings.ToLower(str) {
+		perr.Token = str
+	}
+
+	return v, err
+}
 
+func parsePEP440Version(str string) (PyPIVersion, error) {
 	// from https://peps.python.org/pep-0440/#appendix-b-parsing-version-strings-with-regular-expressions
 	re := MustCompile(`^\s*v?(?:(?:(?P<epoch>[0-9]+)!)?(?P<release>[0-9]+(?:\.[0-9]+)*)(?P<pre>[-_\.]?(?P<pre_l>(a|b|c|rc|alpha|beta|pre|preview))[-_\.]?(?P<pre_n>[0-9]+)?)?(?P<post>(?:-(?P<post_n1>[0-9]+))|(?:[-_\.]?(?P<post_l>post|rev|r)[-_\.]?(?P<post_n2>[0-9]+)?))?(?P<dev>[-_\.]?(?P<dev_l>dev)[-_\.]?(?P<dev_n>[0-9]+)?)?)(?:\+(?P<local>[a-z0-9]+(?:[-_\.][a-z0-9]+)*))?\s*$`)
 	match := re.FindStringSubmatch(str)
 
+	// versions that are not PEP 440 are still compared using the legacy rules of setuptools,
+	// but are reported as invalid as the ordering of such versions is not well defined
 	if len(match) == 0 {
-		return parsePyPILegacyVersion(str)
+		return parsePyPILegacyVersion(str), &ParseError{Token: str, Reason: "not a PEP 440 version"}
 	}
 
 	var version PyPIVersion
+	var err error
 
 	version.epoch = big.NewInt(0)
 
 	if epoch := match[re.SubexpIndex("epoch")]; epoch != "" {
-		version.epoch = convertToBigIntOrPanic(epoch)
+		if version.epoch, err = convertToBigIntOrError(epoch); err != nil {
+			return parsePyPILegacyVersion(str), err
+		}
 	}
 
 	for _, r := range strings.Split(match[re.SubexpIndex("release")], ".") {
-		version.release = append(version.release, convertToBigIntOrPanic(r))
+		n, err := convertToBigIntOrError(r)
+		if err != nil {
+			return parsePyPILegacyVersion(str), err
+		}
+
+		version.release = append(version.release, n)
 	}
 
-	version.pre = parseLetterVersion(match[re.SubexpIndex("pre_l")], match[re.SubexpIndex("pre_n")])
+	if version.pre, err = parseLetterVersion(match[re.SubexpIndex("pre_l")], match[re.SubexpIndex("pre_n")]); err != nil {
+		return parsePyPILegacyVersion(str), err
+	}
 
 	post := match[re.SubexpIndex("post_n1")]
 
@@ -166,11 +195,17 @@ func parsePyPIVersion(str string) PyPIVersion {
 		post = match[re.SubexpIndex("post_n2")]
 	}
 
-	version.post = parseLetterVersion(match[re.SubexpIndex("post_l")], post)
-	version.dev = parseLetterVersion(match[re.SubexpIndex("dev_l")], match[re.SubexpIndex("dev_n")])
+	if version.post, err = parseLetterVersion(match[re.SubexpIndex("post_l")], post); err != nil {
+		return parsePyPILegacyVersion(str), err
+	}
+
+	if version.dev, err = parseLetterVersion(match[re.SubexpIndex("dev_l")], match[re.SubexpIndex("dev_n")]); err != nil {
+		return parsePyPILegacyVersion(str), err
+	}
+
 	version.local = parseLocalVersion(match[re.SubexpIndex("local")])
 
-	return version
+	return version, nil
 }
 
 // Compares the epoch segments of each version
@@ -186,13 +221,17 @@ func (pv PyPIVersion) compareRelease(pw PyPIVersion) int {
 }
 
 func (pv PyPIVersion) preIndex() int {
-	for i, pre := range []string{"a", "b", "rc"} {
+	pres := []string{"a", "b", "rc"}
+
+	for i, pre := range pres {
 		if pre == pv.pre.letter {
 			return i
 		}
 	}
 
-	panic(fmt.Sprintf("unknown prefix %s", pv.pre.letter))
+	// parseLetterVersion normalizes all pre-release letters, so this cannot
+	// happen, but if it does then sort the unknown letter last
+	return len(pres)
 }
 
 // Checks if this PyPIVersion should apply a sort trick when comparing pre,
@@ -378,11 +417,74 @@ func pypiCompareVersion(v, w PyPIVersion) int {
 }
 
 // Compare PyPI Version structs
-func (pv PyPIVersion) Compare(pw PyPIVersion) int {
-	return pypiCompareVersion(pv, pw)
+func (pv PyPIVersion) Compare(w Version) int {
+	if pw, ok := w.(PyPIVersion); ok {
+		return pypiCompareVersion(pv, pw)
+	}
+
+	return pv.CompareStr(w.String())
 }
 
 // CompareStr PyPI Version strings
 func (pv PyPIVersion) CompareStr(str string) int {
-	return pv.Compare(parsePyPIVersion(str))
+	pw, _ := parsePyPIVersion(str)
+
+	return pypiCompareVersion(pv, pw)
+}
+
+// String returns the PyPI Version as it was originally given
+func (pv PyPIVersion) String() string {
+	return pv.original
+}
+
+// Canonical returns the PyPI Version in the normalized form described by PEP 440,
+// or lowercased if it is a legacy version
+//
+// See https://peps.python.org/pep-0440/#normalization
+func (pv PyPIVersion) Canonical() string {
+	if pv.legacy != nil {
+		return strings.ToLower(strings.TrimSpace(pv.original))
+	}
+
+	var sb strings.Builder
+
+	if pv.epoch.Sign() != 0 {
+		sb.WriteString(pv.epoch.String() + "!")
+	}
+
+	release := make([]string, 0, len(pv.release))
+
+	for _, r := range pv.release {
+		release = append(release, r.String())
+	}
+
+	sb.WriteString(strings.Join(release, "."))
+
+	if pv.pre.letter != "" {
+		sb.WriteString(pv.pre.letter + pv.pre.number.String())
+	}
+
+	if pv.post.letter != "" {
+		sb.WriteString(".post" + pv.post.number.String())
+	}
+
+	if pv.dev.letter != "" {
+		sb.WriteString(".dev" + pv.dev.number.String())
+	}
+
+	if local := strings.Join(pv.local, "."); local != "" {
+		sb.WriteString("+" + local)
+	}
+
+	return sb.String()
+}
+
+// IsPrerelease checks if the PyPI Version is a pre-release or developmental release
+func (pv PyPIVersion) IsPrerelease() bool {
+	return pv.pre.letter != "" || pv.dev.letter != ""
+}
+
+// Release returns the release segment of the PyPI Version
+func (pv PyPIVersion) Release() Components {
+	return pv.release.padded()
 }
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestPyPIVersionCompare(t *testing.T) {
+	// the ordering examples of PEP 440
+	expectComparisons(t, "PyPI", []versionComparison{
+		{"1.0", "=", "1.0.0"},
+		{"1.0", "=", "v1.0"},
+		{"1.0.dev456", "<", "1.0a1"},
+		{"1.0a1", "<", "1.0a2.dev456"},
+		{"1.0a2.dev456", "<", "1.0a12.dev456"},
+		{"1.0a12.dev456", "<", "1.0a12"},
+		{"1.0a12", "<", "1.0b1.dev456"},
+		{"1.0b1.dev456", "<", "1.0b2"},
+		{"1.0b2", "<", "1.0b2.post345.dev456"},
+		{"1.0b2.post345.dev456", "<", "1.0b2.post345"},
+		{"1.0b2.post345", "<", "1.0rc1.dev456"},
+		{"1.0rc1.dev456", "<", "1.0rc1"},
+		{"1.0rc1", "<", "1.0"},
+		{"1.0", "<", "1.0+abc.5"},
+		{"1.0+abc.5", "<", "1.0+abc.7"},
+		{"1.0+abc.7", "<", "1.0+5"},
+		{"1.0+5", "<", "1.0.post456.dev34"},
+		{"1.0.post456.dev34", "<", "1.0.post456"},
+		{"1.0.post456", "<", "1.1.dev1"},
+		{"1.0-1", "=", "1.0.post1"},
+		{"1.0alpha1", "=", "1.0a1"},
+		{"1.0c1", "=", "1.0rc1"},
+		{"1.0RC1", "=", "1.0rc1"},
+		{"2.0", "<", "1!1.0"},
+	})
+}
+
+func TestPyPIVersionParseErrors(t *testing.T) {
+	expectParseErrors(t, "PyPI", []string{
+		"foo",
+		"1..2",
+		"1.0-",
+		".1",
+		"1.0~rc1",
+		"1.0.0-SNAPSHOT",
+		"dev-master",
+	})
+}
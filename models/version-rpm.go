@@ -0,0 +1,277 @@
+// Package models defines the structures and functions used to determine if a
+// SBOM package is affected by a OSV.DEV vulnerabity.
+package models
+
+import (
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+func isRPMAlphaNumeric(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isRPMDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isRPMSeparator(r rune) bool {
+	if r == '~' || r == '^' {
+		return false
+	}
+
+	return r > unicode.MaxASCII || !isRPMAlphaNumeric(byte(r))
+}
+
+// splitRPMSegment returns the leading run of characters in str
+// that all match the given func, along with the rest of the string
+func splitRPMSegment(str string, match func(c byte) bool) (string, string) {
+	i := 0
+
+	for i < len(str) && match(str[i]) {
+		i++
+	}
+
+	return str[:i], str[i:]
+}
+
+// compareRPMVersions compares two version (or release) strings using the same
+// algorithm as rpmvercmp, which splits each string into alternating runs of
+// digits and letters and compares them in turn.
+//
+// See https://github.com/rpm-software-management/rpm/blob/master/rpmio/rpmvercmp.cc
+func compareRPMVersions(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	for a != "" || b != "" {
+		// discard any leading separators, which are everything that is not
+		// alphanumeric other than the tilde and caret operators
+		a = strings.TrimLeftFunc(a, isRPMSeparator)
+		b = strings.TrimLeftFunc(b, isRPMSeparator)
+
+		// a tilde sorts before everything else, even the end of the version
+		if strings.HasPrefix(a, "~") || strings.HasPrefix(b, "~") {
+			if !strings.HasPrefix(a, "~") {
+				return +1
+			}
+			if !strings.HasPrefix(b, "~") {
+				return -1
+			}
+
+			a, b = a[1:], b[1:]
+
+			continue
+		}
+
+		// a caret sorts after the end of the version, but before everything else
+		if strings.HasPrefix(a, "^") || strings.HasPrefix(b, "^") {
+			if a == "" {
+				return -1
+			}
+			if b == "" {
+				return +1
+			}
+			if !strings.HasPrefix(a, "^") {
+				return +1
+			}
+			if !strings.HasPrefix(b, "^") {
+				return -1
+			}
+
+			a, b = a[1:], b[1:]
+
+			continue
+		}
+
+		if a == "" || b == "" {
+			break
+		}
+
+		var as, bs string
+
+		isNumber := isRPMDigit(a[0])
+
+		if isNumber {
+			as, a = splitRPMSegment(a, isRPMDigit)
+			bs, b = splitRPMSegment(b, isRPMDigit)
+		} else {
+			isAlpha := func(c byte) bool { return isRPMAlphaNumeric(c) && !isRPMDigit(c) }
+
+			as, a = splitRPMSegment(a, isAlpha)
+			bs, b = splitRPMSegment(b, isAlpha)
+		}
+
+		// this cannot happen, as we skipped over all separators above,
+		// but it's the behaviour of rpm so we mirror it to be safe
+		if as == "" {
+			return -1
+		}
+
+		// numeric segments are always newer than alpha segments
+		if bs == "" {
+			if isNumber {
+				return +1
+			}
+
+			return -1
+		}
+
+		if isNumber {
+			ai, _ := convertToBigInt(as)
+			bi, _ := convertToBigInt(bs)
+
+			if diff := ai.Cmp(bi); diff != 0 {
+				return diff
+			}
+
+			continue
+		}
+
+		if diff := strings.Compare(as, bs); diff != 0 {
+			return diff
+		}
+	}
+
+	// whichever version still has characters left is the newer one
+	if a == "" && b == "" {
+		return 0
+	}
+	if a == "" {
+		return -1
+	}
+
+	return +1
+}
+
+// RPMVersion defines the RPM Version string, in the form of [epoch:]version[-release]
+type RPMVersion struct {
+	original string
+	epoch    *big.Int
+	version  string
+	release  string
+}
+
+func (v RPMVersion) compare(w RPMVersion) int {
+	if diff := v.epoch.Cmp(w.epoch); diff != 0 {
+		return diff
+	}
+	if diff := compareRPMVersions(v.version, w.version); diff != 0 {
+		return diff
+	}
+	if diff := compareRPMVersions(v.release, w.release); diff != 0 {
+		return diff
+	}
+
+	return 0
+}
+
+// Compare RPM Version structs
+func (v RPMVersion) Compare(w Version) int {
+	if rw, ok := w.(RPMVersion); ok {
+		return v.compare(rw)
+	}
+
+	return v.CompareStr(w.String())
+}
+
+// CompareStr RPM Version strings
+func (v RPMVersion) CompareStr(str string) int {
+	w, _ := parseRPMVersion(str)
+
+	return v.compare(w)
+}
+
+// String returns the RPM Version as it was originally given
+func (v RPMVersion) String() string {
+	return v.original
+}
+
+// Canonical returns the RPM Version without a zero epoch
+func (v RPMVersion) Canonical() string {
+	str := v.version
+
+	if v.epoch.Sign() != 0 {
+		str = v.epoch.String() + ":" + str
+	}
+
+	if v.release != "" {
+		str += "-" + v.release
+	}
+
+	return str
+}
+
+// IsPrerelease checks if the version of the RPM Version has a "~",
+// which is how RPM packages mark versions that sort before a release
+func (v RPMVersion) IsPrerelease() bool {
+	return strings.Contains(v.version, "~")
+}
+
+// Release returns the numeric components at the start of the version
+func (v RPMVersion) Release() Components {
+	return leadingComponents(v.version).padded()
+}
+
+func parseRPMVersion(str string) (RPMVersion, error) {
+	var version, release string
+	var err error
+
+	original := str
+	str = strings.TrimSpace(str)
+	epoch := big.NewInt(0)
+
+	if strings.Contains(str, ":") {
+		var e string
+		e, str = splitAround(str, ":", false)
+
+		if e != "" {
+			if epoch, err = convertToBigIntOrError(e); err != nil {
+				epoch = big.NewInt(0)
+			}
+		}
+	}
+
+	hasRelease := strings.Contains(str, "-")
+
+	if hasRelease {
+		version, release = splitAround(str, "-", true)
+	} else {
+		version = str
+	}
+
+	if err == nil {
+		err = validateRPMVersion(version, release, hasRelease)
+	}
+
+	return RPMVersion{original, epoch, version, release}, err
+}
+
+// validateRPMVersion checks that the version starts with a digit and that neither the version
+// nor release have characters other than those allowed by rpm, or repeated separators
+func validateRPMVersion(version, release string, hasRelease bool) error {
+	switch {
+	case version == "":
+		return &ParseError{Token: version, Reason: "empty version"}
+	case !isDigit(version[0]):
+		return &ParseError{Token: version, Reason: "version does not start with a digit"}
+	case !MustCompile(`^[A-Za-z0-9._+~^]+$`).MatchString(version):
+		return &ParseError{Token: version, Reason: "invalid character in version"}
+	case hasRelease && release == "":
+		return &ParseError{Token: release, Reason: "empty release"}
+	case hasRelease && !MustCompile(`^[A-Za-z0-9._+~^]+$`).MatchString(release):
+		return &ParseError{Token: release, Reason: "invalid character in release"}
+	}
+
+	if err := validateSeparators(version); err != nil {
+		return err
+	}
+
+	if hasRelease {
+		return validateSeparators(release)
+	}
+
+	return nil
+}
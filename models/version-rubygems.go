@@ -135,19 +135,59 @@ type RubyGemsVersion struct {
 	Segments []string
 }
 
-func parseRubyGemsVersion(str string) RubyGemsVersion {
-	return RubyGemsVersion{
+func parseRubyGemsVersion(str string) (RubyGemsVersion, error) {
+	v := RubyGemsVersion{
 		str,
 		canonicalSegments(strings.Split(canonicalizeRubyGemVersion(str), ".")),
 	}
+
+	// the pattern that Gem::Version accepts
+	if !MustCompile(`^\s*[0-9]+(?:\.[0-9a-zA-Z]+)*(?:-[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?\s*$`).MatchString(str) {
+		return v, &ParseError{Token: str, Reason: "not a valid gem version"}
+	}
+
+	return v, nil
 }
 
 // Compare RubyGems Version structs
-func (v RubyGemsVersion) Compare(w RubyGemsVersion) int {
-	return compareRubyGemsComponents(v.Segments, w.Segments)
+func (v RubyGemsVersion) Compare(w Version) int {
+	if rw, ok := w.(RubyGemsVersion); ok {
+		return compareRubyGemsComponents(v.Segments, rw.Segments)
+	}
+
+	return v.CompareStr(w.String())
 }
 
 // CompareStr RubyGems Version string
 func (v RubyGemsVersion) CompareStr(str string) int {
-	return v.Compare(parseRubyGemsVersion(str))
+	w, _ := parseRubyGemsVersion(str)
+
+	return compareRubyGemsComponents(v.Segments, w.Segments)
+}
+
+// String returns the RubyGems Version as it was originally given
+func (v RubyGemsVersion) String() string {
+	return v.Original
+}
+
+// Canonical returns the canonical segments of the RubyGems Version, which
+// have any trailing zeros removed, i.e. "1.2" for both "1.2.0" and "1.2"
+func (v RubyGemsVersion) Canonical() string {
+	if len(v.Segments) == 0 {
+		return "0"
+	}
+
+	return strings.Join(v.Segments, ".")
+}
+
+// IsPrerelease checks if the RubyGems Version has any letters, as RubyGems does
+func (v RubyGemsVersion) IsPrerelease() bool {
+	return MustCompile(`[a-zA-Z]`).MatchString(v.Original)
+}
+
+// Release returns the numeric segments at the start of the RubyGems Version
+func (v RubyGemsVersion) Release() Components {
+	numbers, _ := groupSegments(v.Segments)
+
+	return leadingComponents(strings.Join(numbers, ".")).padded()
 }
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestRubyGemsVersionCompare(t *testing.T) {
+	// based on the cases of test_gem_version.rb in rubygems
+	expectComparisons(t, "RubyGems", []versionComparison{
+		{"1.0", "=", "1.0.0"},
+		{"1.0", "=", "1"},
+		{"1.0", "<", "1.0.1"},
+		{"1.0.a", "<", "1.0"},
+		{"1.0.a", "<", "1.0.b"},
+		{"1.0.a1", "<", "1.0.a2"},
+		{"1.0.a10", ">", "1.0.a9"},
+		{"1.0.rc1", "<", "1.0"},
+		{"1.0.0-rc1", "<", "1.0"},
+		{"1.2.3", "<", "1.2.10"},
+		{"5.a", "<", "5.0.0.rc2"},
+		{"5.x", ">", "5.0.0.rc2"},
+		{"1.9.3", "<", "1.9.3.1"},
+		{"1.0.0.pre.1", "<", "1.0.0"},
+	})
+}
+
+func TestRubyGemsVersionParseErrors(t *testing.T) {
+	expectParseErrors(t, "RubyGems", []string{
+		"foo",
+		"v1.0",
+		".1",
+		"1..2",
+		"1.0.",
+		"1.0-",
+		"1.0_p1",
+		"1.0~rc1",
+	})
+}
@@ -18,6 +18,28 @@ type SemverLikeVersion struct {
 	Original   string
 }
 
+// String returns the version as it was originally given
+func (v SemverLikeVersion) String() string {
+	return v.Original
+}
+
+// Release returns the numeric components of the version
+func (v SemverLikeVersion) Release() Components {
+	return v.Components.padded()
+}
+
+// canonical returns the numeric components padded to at least the given number, followed
+// by the build string without any build metadata, i.e. "1.2.0-rc.1" for "v1.2-rc.1+abc"
+func (v SemverLikeVersion) canonical(minComponents int) string {
+	numbers := make([]string, 0, max(len(v.Components), minComponents))
+
+	for i := 0; i < max(len(v.Components), minComponents); i++ {
+		numbers = append(numbers, v.Components.Fetch(i).String())
+	}
+
+	return strings.Join(numbers, ".") + removeBuildMetadata(v.Build)
+}
+
 func (v *SemverLikeVersion) fetchComponentsAndBuild(maxComponents int) (Components, string) {
 	if len(v.Components) <= maxComponents {
 		return v.Components, v.Build
@@ -89,12 +89,17 @@ type SemverVersion struct {
 	SemverLikeVersion
 }
 
-func parseSemverVersion(str string) SemverVersion {
-	return SemverVersion{ParseSemverLikeVersion(str, 3)}
+func parseSemverVersion(str string) (SemverVersion, error) {
+	v := SemverVersion{ParseSemverLikeVersion(str, 3)}
+
+	if len(v.Components) == 0 {
+		return v, &ParseError{Token: v.Build, Reason: "no numeric components"}
+	}
+
+	return v, nil
 }
 
-// Compare Semver Version structs
-func (v SemverVersion) Compare(w SemverVersion) int {
+func (v SemverVersion) compare(w SemverVersion) int {
 	if diff := v.Components.Cmp(w.Components); diff != 0 {
 		return diff
 	}
@@ -102,7 +107,28 @@ func (v SemverVersion) Compare(w SemverVersion) int {
 	return compareBuildComponents(v.Build, w.Build)
 }
 
+// Compare Semver Version structs
+func (v SemverVersion) Compare(w Version) int {
+	if sw, ok := w.(SemverVersion); ok {
+		return v.compare(sw)
+	}
+
+	return v.CompareStr(w.String())
+}
+
 // CompareStr Semver Version strings
 func (v SemverVersion) CompareStr(str string) int {
-	return v.Compare(parseSemverVersion(str))
+	w, _ := parseSemverVersion(str)
+
+	return v.compare(w)
+}
+
+// Canonical returns the Semver Version without a leading "v" or build metadata
+func (v SemverVersion) Canonical() string {
+	return v.canonical(3)
+}
+
+// IsPrerelease checks if the Semver Version has a prerelease
+func (v SemverVersion) IsPrerelease() bool {
+	return removeBuildMetadata(v.Build) != ""
 }
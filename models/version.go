@@ -3,9 +3,9 @@
 package models
 
 import (
-	"fmt"
 	"math/big"
 	"regexp"
+	"strings"
 	"sync"
 )
 
@@ -21,13 +21,43 @@ func MustCompile(exp string) *regexp.Regexp {
 	return compiled.(*regexp.Regexp)
 }
 
-// Version deinfes the interface to the CompareStr func
+// Version deinfes the interface to a version parsed for a specific ecosystem,
+// allowing it to be compared, sorted and classified without re-parsing
 type Version interface {
+	// String returns the version as it was originally given.
+	String() string
+
+	// Canonical returns the normalized form of the version, so that versions
+	// which are equal in the ecosystem have the same canonical form as much
+	// as the ecosystem allows, i.e. "v1.2" and "1.2.0" for Semver.
+	Canonical() string
+
+	// Compare returns an integer representing the sort order of the given Version
+	// relative to the subject Version.
+	//
+	// The result will be 0 if v == w, -1 if v < w, or +1 if v > w.
+	//
+	// If the given Version is for a different ecosystem, it is compared as
+	// though its string had been parsed as the concrete Version.
+	Compare(w Version) int
+
 	// CompareStr returns an integer representing the sort order of the given string
 	// when parsed as the concrete Version relative to the subject Version.
 	//
 	// The result will be 0 if v == w, -1 if v < w, or +1 if v > w.
+	//
+	// If the string cannot be parsed, it is compared using as much of it as could be
+	// parsed; use Parse beforehand to detect such strings.
 	CompareStr(str string) int
+
+	// IsPrerelease returns true if the version is a prerelease
+	// (i.e. an alpha, beta or release candidate) by the rules of the ecosystem.
+	IsPrerelease() bool
+
+	// Release returns the leading numeric components of the version, padded with zeros
+	// to at least the major, minor and patch numbers, so that versions which are equal
+	// such as "1.0" and "1.0.0" have the same release however trailing zeros are written.
+	Release() Components
 }
 
 // Components defines a list of IDs
@@ -57,12 +87,76 @@ func (components *Components) Cmp(b Components) int {
 	return 0
 }
 
-func convertToBigIntOrPanic(str string) *big.Int {
+// Major returns the first component, or 0 if there are no components
+func (components Components) Major() *big.Int {
+	return components.Fetch(0)
+}
+
+// Minor returns the second component, or 0 if there are less than two components
+func (components Components) Minor() *big.Int {
+	return components.Fetch(1)
+}
+
+// Patch returns the third component, or 0 if there are less than three components
+func (components Components) Patch() *big.Int {
+	return components.Fetch(2)
+}
+
+// padded returns a copy of the components with zeros appended so that there
+// are at least major, minor and patch components, i.e. [1 0 0] for [1]
+func (components Components) padded() Components {
+	components = append(Components{}, components...)
+
+	for len(components) < 3 {
+		components = append(components, big.NewInt(0))
+	}
+
+	return components
+}
+
+// leadingComponents returns the dot separated numbers at the start of the string, i.e. [1 2] for "1.2rc1"
+func leadingComponents(str string) Components {
+	var components Components
+
+	numbers := MustCompile(`^\d+(?:\.\d+)*`).FindString(str)
+
+	if numbers == "" {
+		return components
+	}
+
+	for _, n := range strings.Split(numbers, ".") {
+		c, _ := convertToBigInt(n)
+
+		components = append(components, c)
+	}
+
+	return components
+}
+
+// isDigit checks if the character is a digit from 0 to 9
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// validateSeparators checks that the dots of the version separate components,
+// rather than being repeated or at the start or end of the version
+func validateSeparators(str string) error {
+	switch {
+	case strings.Contains(str, ".."):
+		return &ParseError{Token: str, Reason: "empty component"}
+	case strings.HasPrefix(str, ".") || strings.HasSuffix(str, "."):
+		return &ParseError{Token: str, Reason: "empty component"}
+	}
+
+	return nil
+}
+
+func convertToBigIntOrError(str string) (*big.Int, error) {
 	if num, isNumber := convertToBigInt(str); isNumber {
-		return num
+		return num, nil
 	}
 
-	panic(fmt.Sprintf("failed to convert %s to a number", str))
+	return nil, &ParseError{Token: str, Reason: "not a number"}
 }
 
 func convertToBigInt(str string) (*big.Int, bool) {
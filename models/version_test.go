@@ -0,0 +1,177 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+// versionComparison is a pair of versions along with how the first compares to the second,
+// which is one of "<", "=" or ">"
+type versionComparison struct {
+	a, op, b string
+}
+
+// expectComparisons checks that each pair of versions compares as expected for the ecosystem,
+// both ways round and whether the second version is given as a Version or a string
+func expectComparisons(t *testing.T, ecosystem Ecosystem, comparisons []versionComparison) {
+	t.Helper()
+
+	ops := map[string]int{"<": -1, "=": 0, ">": +1}
+
+	for _, tt := range comparisons {
+		want, ok := ops[tt.op]
+		if !ok {
+			t.Fatalf("unknown operator %q", tt.op)
+		}
+
+		a, err := Parse(tt.a, ecosystem)
+		if err != nil {
+			t.Errorf("Parse(%q, %s) returned %v", tt.a, ecosystem, err)
+			continue
+		}
+
+		b, err := Parse(tt.b, ecosystem)
+		if err != nil {
+			t.Errorf("Parse(%q, %s) returned %v", tt.b, ecosystem, err)
+			continue
+		}
+
+		if got := a.Compare(b); got != want {
+			t.Errorf("%q.Compare(%q) = %d, want %d", tt.a, tt.b, got, want)
+		}
+
+		if got := a.CompareStr(tt.b); got != want {
+			t.Errorf("%q.CompareStr(%q) = %d, want %d", tt.a, tt.b, got, want)
+		}
+
+		if got := b.Compare(a); got != -want {
+			t.Errorf("%q.Compare(%q) = %d, want %d", tt.b, tt.a, got, -want)
+		}
+	}
+}
+
+// expectParseErrors checks that each of the versions fails to parse for the ecosystem with a *ParseError
+func expectParseErrors(t *testing.T, ecosystem Ecosystem, versions []string) {
+	t.Helper()
+
+	for _, str := range versions {
+		v, err := Parse(str, ecosystem)
+
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("Parse(%q, %s) = %v, %v, want a *ParseError", str, ecosystem, v, err)
+			continue
+		}
+
+		if perr.Ecosystem != ecosystem || perr.Input != str || perr.Reason == "" {
+			t.Errorf("Parse(%q, %s) returned %#v, want the ecosystem, input and reason", str, ecosystem, perr)
+		}
+	}
+}
+
+// expectRelease checks that the release of the version has the given components
+func expectRelease(t *testing.T, v Version, want []int64) {
+	t.Helper()
+
+	got := v.Release()
+
+	if len(got) != len(want) {
+		t.Errorf("Release(%q) = %v, want %v", v, got, want)
+		return
+	}
+
+	for i := range want {
+		if got[i].Int64() != want[i] {
+			t.Errorf("Release(%q) = %v, want %v", v, got, want)
+			return
+		}
+	}
+}
+
+func TestVersionCanonical(t *testing.T) {
+	tests := []struct {
+		ecosystem Ecosystem
+		version   string
+		canonical string
+	}{
+		{"npm", "v1.2", "1.2.0"},
+		{"npm", "1.2.3-rc.1+build", "1.2.3-rc.1"},
+		{"NuGet", "1.2", "1.2.0"},
+		{"PyPI", "1.0RC1", "1.0rc1"},
+		{"PyPI", "1.0-1", "1.0.post1"},
+		{"Debian", "0:1.0-0", "1.0"},
+		{"Debian", "1:1.0-1", "1:1.0-1"},
+		{"Rocky Linux", "0:1.0-1.el8", "1.0-1.el8"},
+		{"RubyGems", "1.2.0", "1.2"},
+		{"Packagist", "v1.0.0-RC1", "1.0.0.RC.1"},
+		{"Maven", "1.0.0-GA", "1"},
+		{"Maven", "1.0-RC1", "1-rc-1"},
+	}
+
+	for _, tt := range tests {
+		v, err := Parse(tt.version, tt.ecosystem)
+		if err != nil {
+			t.Errorf("Parse(%q, %s) returned %v", tt.version, tt.ecosystem, err)
+			continue
+		}
+
+		if got := v.Canonical(); got != tt.canonical {
+			t.Errorf("Canonical(%q, %s) = %q, want %q", tt.version, tt.ecosystem, got, tt.canonical)
+		}
+
+		// the canonical form is the same version
+		if got := v.CompareStr(tt.canonical); got != 0 {
+			t.Errorf("%q.CompareStr(%q) = %d, want 0", tt.version, tt.canonical, got)
+		}
+	}
+
+	// versions that are not valid have no canonical form, rather than one made up of what could be read
+	invalid := []struct {
+		ecosystem Ecosystem
+		version   string
+	}{
+		{"Packagist", "1.0-"},
+		{"Maven", "v1"},
+		{"RubyGems", "1.0-"},
+	}
+
+	for _, tt := range invalid {
+		if v, err := Parse(tt.version, tt.ecosystem); err == nil {
+			t.Errorf("Parse(%q, %s) = %q, want an error", tt.version, tt.ecosystem, v.Canonical())
+		}
+	}
+}
+
+func TestVersionRelease(t *testing.T) {
+	tests := []struct {
+		ecosystem Ecosystem
+		version   string
+		release   []int64
+	}{
+		{"npm", "1.2.3-rc.1", []int64{1, 2, 3}},
+		{"NuGet", "1.2", []int64{1, 2, 0}},
+		{"PyPI", "1", []int64{1, 0, 0}},
+		{"PyPI", "1.2.3.4", []int64{1, 2, 3, 4}},
+		{"Debian", "1:2.30-1", []int64{2, 30, 0}},
+		{"Rocky Linux", "1.0-1.el8", []int64{1, 0, 0}},
+		{"Alpine", "1.2_rc1-r0", []int64{1, 2, 0}},
+		{"Go", "v1.2", []int64{1, 2, 0}},
+		// trailing zeros that are trimmed when comparing are still part of the release
+		{"Maven", "1.0.0", []int64{1, 0, 0}},
+		{"Maven", "2.0-RC1", []int64{2, 0, 0}},
+		{"Maven", "1.2.3.4", []int64{1, 2, 3, 4}},
+		{"RubyGems", "1.0.0", []int64{1, 0, 0}},
+		{"RubyGems", "2.1.0.rc1", []int64{2, 1, 0}},
+		{"Packagist", "1.0", []int64{1, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		v, err := Parse(tt.version, tt.ecosystem)
+		if err != nil {
+			t.Errorf("Parse(%q, %s) returned %v", tt.version, tt.ecosystem, err)
+			continue
+		}
+
+		expectRelease(t, v, tt.release)
+	}
+}
@@ -196,61 +196,167 @@ func eventVersion(e Event) string {
 	return ""
 }
 
-func rangeContainsVersion(ar Range, pkg PackageDetails) bool {
-	if ar.Type != RangeEcosystem && ar.Type != RangeSemVer {
-		return false
+// parsedEvent pairs an Event with its version parsed for the package ecosystem
+type parsedEvent struct {
+	Event
+	version Version
+}
+
+// isUnboundedEvent checks if the event is an introduced "0" or limit "*",
+// which respectively represent the lowest and highest possible version
+func isUnboundedEvent(e Event) bool {
+	return e.Introduced == "0" || e.Limit == "*"
+}
+
+// bound returns where the event sorts relative to events with a version,
+// which is before them for an introduced "0" and after them for a limit "*"
+func (pe parsedEvent) bound() int {
+	if pe.Introduced == "0" {
+		return -1
 	}
-	// todo: we should probably warn here
-	if len(ar.Events) == 0 {
-		return false
+	if pe.Limit == "*" {
+		return +1
 	}
 
-	vp := MustParse(pkg.Version, pkg.CompareAs)
+	return 0
+}
+
+// sortEvents parses the version of each event for the given ecosystem and sorts them,
+// with any introduced "0" first and any limit "*" last
+func sortEvents(unsorted []Event, ecosystem Ecosystem) ([]parsedEvent, error) {
+	var err error
 
-	sort.Slice(ar.Events, func(i, j int) bool {
-		a := ar.Events[i]
-		b := ar.Events[j]
+	// parse every event upfront so that sorting them cannot fail part way through
+	events := make([]parsedEvent, 0, len(unsorted))
 
-		if a.Introduced == "0" {
-			return true
+	for _, e := range unsorted {
+		pe := parsedEvent{Event: e}
+
+		if !isUnboundedEvent(e) {
+			if pe.version, err = Parse(eventVersion(e), ecosystem); err != nil {
+				return nil, err
+			}
+		}
+
+		events = append(events, pe)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		a := events[i]
+		b := events[j]
+
+		if a.bound() != b.bound() {
+			return a.bound() < b.bound()
 		}
 
-		if b.Introduced == "0" {
+		if a.version == nil {
 			return false
 		}
 
-		return MustParse(eventVersion(a), pkg.CompareAs).CompareStr(eventVersion(b)) < 0
+		return a.version.Compare(b.version) < 0
 	})
 
+	return events, nil
+}
+
+// rangeContainsVersion checks if the given version is within the range using
+// the evaluation algorithm defined by the OSV schema, where the events are
+// sorted by version and then applied in turn, using the events that were
+// already parsed and sorted for the ecosystem of the package if there are any.
+//
+// See: https://ossf.github.io/osv-schema/#evaluation
+func rangeContainsVersion(ar Range, pkg PackageDetails, events []parsedEvent) (bool, error) {
+	// todo: we should probably warn here
+	if len(ar.Events) == 0 || pkg.Version == "" {
+		return false, nil
+	}
+
+	vp, err := Parse(pkg.Version, pkg.CompareAs)
+	if err != nil {
+		return false, err
+	}
+
+	if events == nil {
+		if events, err = sortEvents(ar.Events, pkg.CompareAs); err != nil {
+			return false, err
+		}
+	}
+
 	var affected bool
-	for _, e := range ar.Events {
-		if affected {
-			if e.Fixed != "" {
-				affected = vp.CompareStr(e.Fixed) < 0
-			} else if e.LastAffected != "" {
-				affected = e.LastAffected == pkg.Version || vp.CompareStr(e.LastAffected) <= 0
+	var limited bool
+	var belowLimit bool
+
+	for _, e := range events {
+		switch {
+		case e.Introduced != "":
+			if e.Introduced == "0" || vp.Compare(e.version) >= 0 {
+				affected = true
+			}
+		case e.Fixed != "":
+			if vp.Compare(e.version) >= 0 {
+				affected = false
+			}
+		case e.LastAffected != "":
+			if vp.Compare(e.version) > 0 {
+				affected = false
+			}
+		case e.Limit != "":
+			// versions at or above every limit are outside the range entirely
+			limited = true
+
+			if e.Limit == "*" || vp.Compare(e.version) < 0 {
+				belowLimit = true
 			}
-		} else if e.Introduced != "" {
-			affected = e.Introduced == "0" || vp.CompareStr(e.Introduced) >= 0
 		}
 	}
 
-	return affected
+	if limited && !belowLimit {
+		return false, nil
+	}
+
+	return affected, nil
 }
 
-// rangeAffectsVersion checks if the given version is within the range
-// specified by the events of any "Ecosystem" or "Semver" type ranges
-func rangeAffectsVersion(a []Range, pkg PackageDetails) bool {
-	for _, r := range a {
-		if r.Type != RangeEcosystem && r.Type != RangeSemVer {
-			return false
+// rangeAffectsVersion checks if the given version or commit is within any of
+// the "Ecosystem", "Semver" or "Git" type ranges, skipping any other types,
+// and returns the first error if no range could be shown to match, where
+// events returns the already parsed events of a range, or nil if it has none
+func rangeAffectsVersion(a []Range, pkg PackageDetails, events func(i int) []parsedEvent) (bool, error) {
+	var indeterminate error
+
+	for i, r := range a {
+		var affected bool
+		var err error
+
+		switch r.Type {
+		case RangeEcosystem, RangeSemVer:
+			affected, err = rangeContainsVersion(r, pkg, events(i))
+		case RangeGit:
+			affected, err = rangeContainsCommit(r, pkg)
+		default:
+			continue
 		}
-		if rangeContainsVersion(r, pkg) {
-			return true
+
+		if err != nil {
+			if indeterminate == nil {
+				indeterminate = err
+			}
+
+			continue
+		}
+
+		if affected {
+			return true, nil
 		}
 	}
 
-	return false
+	return false, indeterminate
+}
+
+// RangeContains checks if the version or commit of the package is within
+// the range, using the same evaluation that IsAffected uses for each range
+func RangeContains(r Range, pkg PackageDetails) (bool, error) {
+	return rangeAffectsVersion([]Range{r}, pkg, noParsedEvents)
 }
 
 // AffectsEcosystem checks a vulnerabilities' ecosystem with the ecosystem passed in
@@ -264,12 +370,39 @@ func AffectsEcosystem(v Vulnerability, ecosystem Ecosystem) bool {
 	return false
 }
 
-// IsAffected checks a package for vulnerabilities
-func IsAffected(v Vulnerability, pkg PackageDetails) bool {
-	for _, affected := range v.Affected {
-		ecosystem, _, _ := strings.Cut(string(affected.Package.Ecosystem), ":")
-		if ecosystem == string(pkg.Ecosystem) &&
-			affected.Package.Name == pkg.Name {
+// affectsPackage checks if the affected entry is for the same ecosystem, distro release and name, once normalized, as the package
+func affectsPackage(affected Affected, pkg PackageDetails) bool {
+	ecosystem, _, _ := strings.Cut(string(affected.Package.Ecosystem), ":")
+
+	return ecosystem == string(pkg.Ecosystem) &&
+		NormalizeName(pkg.Ecosystem, affected.Package.Name) == NormalizeName(pkg.Ecosystem, pkg.Name) &&
+		affectsDistro(affected, pkg)
+}
+
+// IsAffected checks a package for vulnerabilities.
+//
+// Whether the vulnerability has been withdrawn is not considered, so that
+// withdrawn vulnerabilities can be reported when asked for; use a
+// VulnerabilityFilter to exclude them beforehand.
+//
+// If the package is not shown to be affected but one or more of the affected
+// entries could not be evaluated, such as when a version fails to parse, the
+// match is indeterminate and the error explaining why is returned.
+func IsAffected(v Vulnerability, pkg PackageDetails) (bool, error) {
+	return isAffected(v, pkg, func(int) func(int) []parsedEvent { return noParsedEvents })
+}
+
+// noParsedEvents is used for ranges whose events have not been parsed already
+func noParsedEvents(int) []parsedEvent {
+	return nil
+}
+
+// isAffected implements IsAffected, where events returns the already parsed events of the ranges of an affected entry
+func isAffected(v Vulnerability, pkg PackageDetails, events func(i int) func(int) []parsedEvent) (bool, error) {
+	var indeterminate error
+
+	for i, affected := range v.Affected {
+		if affectsPackage(affected, pkg) {
 			if len(affected.Ranges) == 0 && len(affected.Versions) == 0 {
 				_, _ = fmt.Fprintf(
 					os.Stderr,
@@ -281,20 +414,29 @@ func IsAffected(v Vulnerability, pkg PackageDetails) bool {
 			}
 
 			if slices.Contains(affected.Versions, pkg.Version) {
-				return true
+				return true, nil
 			}
 
-			if rangeAffectsVersion(affected.Ranges, pkg) {
-				return true
+			// if a package does not have a version or commit, assume it is vulnerable
+			// as false positives are better than false negatives here
+			if pkg.Version == "" && pkg.Commit == "" {
+				return true, nil
 			}
 
-			// if a package does not have a version, assume it is vulnerable
-			// as false positives are better than false negatives here
-			if pkg.Version == "" {
-				return true
+			isAffected, err := rangeAffectsVersion(affected.Ranges, pkg, events(i))
+			if err != nil {
+				if indeterminate == nil {
+					indeterminate = err
+				}
+
+				continue
+			}
+
+			if isAffected {
+				return true, nil
 			}
 		}
 	}
 
-	return false
+	return false, indeterminate
 }
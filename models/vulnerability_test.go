@@ -0,0 +1,87 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+// ecosystemVulnerability returns a vulnerability of the package that is introduced and fixed in the given versions
+func ecosystemVulnerability(id string, ecosystem Ecosystem, name, introduced, fixed string) Vulnerability {
+	return Vulnerability{
+		ID: id,
+		Affected: []Affected{{
+			Package: Package{Ecosystem: ecosystem, Name: name},
+			Ranges: []Range{{
+				Type:   RangeEcosystem,
+				Events: []Event{{Introduced: introduced}, {Fixed: fixed}},
+			}},
+		}},
+	}
+}
+
+func TestIsAffected(t *testing.T) {
+	tests := []struct {
+		ecosystem Ecosystem
+		version   string
+		want      bool
+	}{
+		{"PyPI", "1.0", true},
+		{"PyPI", "2.0", false},
+		{"PyPI", "2.0rc1", true},
+		{"Debian", "1.0-1", true},
+		{"Debian", "2.0-1", false},
+		{"Maven", "1.9.9", true},
+		{"Maven", "2.0.0", false},
+		{"RubyGems", "1.5", true},
+		{"Packagist", "1.5.0", true},
+		{"Rocky Linux", "1.0-1.el8", true},
+	}
+
+	for _, tt := range tests {
+		v := ecosystemVulnerability("TEST-1", tt.ecosystem, "pkg", "0", "2.0")
+
+		got, err := IsAffected(v, PackageDetails{Name: "pkg", Version: tt.version, Ecosystem: tt.ecosystem, CompareAs: tt.ecosystem})
+		if err != nil {
+			t.Errorf("IsAffected(%s %s) returned %v", tt.ecosystem, tt.version, err)
+		}
+
+		if got != tt.want {
+			t.Errorf("IsAffected(%s %s) = %v, want %v", tt.ecosystem, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestIsAffectedIndeterminate(t *testing.T) {
+	// a malformed version of either the package or the advisory cannot be shown to be
+	// outside of the range, so the match is indeterminate rather than unaffected
+	tests := []struct {
+		ecosystem Ecosystem
+		version   string
+		fixed     string
+	}{
+		{"PyPI", "foo", "2.0"},
+		{"PyPI", "1.0", "1..2"},
+		{"Debian", "foo", "2.0-1"},
+		{"Debian", "1.0-1", "1..2"},
+		{"RubyGems", "foo", "2.0"},
+		{"Packagist", "1.0-", "2.0"},
+		{"Maven", "1.0", "v2"},
+		{"Rocky Linux", "foo", "2.0-1.el8"},
+	}
+
+	for _, tt := range tests {
+		v := ecosystemVulnerability("TEST-1", tt.ecosystem, "pkg", "0", tt.fixed)
+
+		got, err := IsAffected(v, PackageDetails{Name: "pkg", Version: tt.version, Ecosystem: tt.ecosystem, CompareAs: tt.ecosystem})
+
+		var perr *ParseError
+		if got || !errors.As(err, &perr) {
+			t.Errorf("IsAffected(%s %s, fixed %s) = %v, %v, want false and a *ParseError", tt.ecosystem, tt.version, tt.fixed, got, err)
+			continue
+		}
+
+		if perr.Ecosystem != tt.ecosystem || perr.Reason == "" {
+			t.Errorf("IsAffected(%s %s, fixed %s) returned %#v", tt.ecosystem, tt.version, tt.fixed, perr)
+		}
+	}
+}
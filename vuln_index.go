@@ -0,0 +1,224 @@
+// Ortelius v11 package Microservice that handles creating and retrieving Dependencies
+package main
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/ortelius/scec-deppkg/models"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+)
+
+var vulnIndex = models.NewVulnerabilityIndex() // vulnerabilities held in memory for GetCVEs, keyed by purl and package name
+
+// vulnIndexStatus records the outcome of the last refresh of the vulnerability index
+var vulnIndexStatus struct {
+	sync.Mutex
+	checked time.Time
+	err     error
+}
+
+// VulnIndexMetrics represents the size and staleness of the in-memory vulnerability index
+type VulnIndexMetrics struct {
+	models.IndexStats
+	StaleSeconds float64 `json:"stale_seconds"`
+	LastChecked  string  `json:"last_checked,omitempty"`
+	LastError    string  `json:"last_error,omitempty"`
+	HeapAlloc    uint64  `json:"heap_alloc"`
+}
+
+// vulnsRevision returns the revision of the vulnerabilities in the database, which is the revision and key of the latest
+// import-osv run along with the latest modified time and the number of vulnerabilities, so that changes made outside
+// of the importer are picked up too, including those that add or delete vulnerabilities modified before the latest one
+func vulnsRevision(ctx context.Context) (string, error) {
+	aql := `LET modified = MAX(FOR vuln IN vulns RETURN vuln.modified)
+			LET imported = FIRST(FOR i IN osvimports SORT i.finished DESC, i._key DESC LIMIT 1 RETURN CONCAT_SEPARATOR("/", i.revision, i._key))
+			RETURN CONCAT_SEPARATOR("@", imported, modified, LENGTH(vulns))`
+
+	cursor, err := dbconn.Database.Query(ctx, aql, nil)
+	if err != nil {
+		// the osvimports collection only exists once import-osv has been run
+		aql = `RETURN CONCAT_SEPARATOR("@", MAX(FOR vuln IN vulns RETURN vuln.modified), LENGTH(vulns))`
+
+		if cursor, err = dbconn.Database.Query(ctx, aql, nil); err != nil {
+			return "", errors.Wrap(err, "failed to query the vulns revision")
+		}
+	}
+
+	defer cursor.Close()
+
+	var revision string
+
+	if _, err = cursor.ReadDocument(ctx, &revision); err != nil {
+		return "", errors.Wrap(err, "failed to read the vulns revision")
+	}
+
+	return revision, nil
+}
+
+// vulnsModified returns the modified time of each of the vulnerabilities in the database, by key
+func vulnsModified(ctx context.Context) (map[string]time.Time, error) {
+	aql := `FOR vuln IN vulns
+				RETURN { "key": vuln._key, "modified": vuln.modified }`
+
+	cursor, err := dbconn.Database.Query(ctx, aql, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query the vuln modified times")
+	}
+
+	defer cursor.Close()
+
+	modified := make(map[string]time.Time)
+
+	for cursor.HasMore() {
+		var doc struct {
+			Key      string    `json:"key"`
+			Modified time.Time `json:"modified"`
+		}
+
+		if _, err = cursor.ReadDocument(ctx, &doc); err != nil {
+			return nil, errors.Wrap(err, "failed to read vuln modified time")
+		}
+
+		modified[doc.Key] = doc.Modified
+	}
+
+	return modified, nil
+}
+
+// refreshVulnIndex loads the vulnerabilities that are new or whose modified time differs from that in the index,
+// and removes those that are no longer in the database, when the revision of the vulnerabilities in the database
+// differs from that of the index. It returns the number of vulnerabilities that were loaded and removed.
+func refreshVulnIndex(ctx context.Context) (int, int, error) {
+	revision, err := vulnsRevision(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if vulnIndex.Ready() && revision == vulnIndex.Revision() {
+		vulnIndex.SetRevision(revision, time.Now())
+		return 0, 0, nil
+	}
+
+	aql := `FOR vuln IN vulns
+				RETURN merge({ID: vuln._key}, vuln)`
+
+	parameters := map[string]interface{}{}
+	removed := 0
+
+	// once the index is loaded, the modified time of each vulnerability is compared with the index, as an import
+	// can add vulnerabilities that were modified before the latest one in the index or delete vulnerabilities
+	if vulnIndex.Ready() {
+		modified, err := vulnsModified(ctx)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		keys := make(map[string]bool, len(modified))
+
+		for key := range modified {
+			keys[key] = true
+		}
+
+		removed = vulnIndex.Retain(keys)
+
+		changed := vulnIndex.Changed(modified)
+		if len(changed) == 0 {
+			vulnIndex.SetRevision(revision, time.Now())
+			return 0, removed, nil
+		}
+
+		aql = `FOR vuln IN vulns
+				FILTER vuln._key IN @keys
+				RETURN merge({ID: vuln._key}, vuln)`
+
+		parameters["keys"] = changed
+	}
+
+	cursor, err := dbconn.Database.Query(ctx, aql, &arangodb.QueryOptions{BindVars: parameters})
+	if err != nil {
+		return 0, removed, errors.Wrap(err, "failed to query vulns")
+	}
+
+	defer cursor.Close()
+
+	loaded := 0
+
+	for cursor.HasMore() {
+		var vuln models.Vulnerability
+
+		if _, err = cursor.ReadDocument(ctx, &vuln); err != nil {
+			return loaded, removed, errors.Wrap(err, "failed to read vuln")
+		}
+
+		vulnIndex.Add(vuln)
+		loaded++
+	}
+
+	vulnIndex.SetRevision(revision, time.Now())
+
+	return loaded, removed, nil
+}
+
+// refreshVulnIndexEvery refreshes the vulnerability index straight away and then at every interval,
+// leaving GetCVEs to query the database until the first refresh succeeds
+func refreshVulnIndexEvery(interval time.Duration) {
+	for {
+		started := time.Now()
+		loaded, removed, err := refreshVulnIndex(context.Background())
+
+		vulnIndexStatus.Lock()
+		vulnIndexStatus.checked = started
+		vulnIndexStatus.err = err
+		vulnIndexStatus.Unlock()
+
+		if err != nil {
+			logger.Sugar().Errorf("Failed to refresh the vulnerability index: %v", err)
+		} else if loaded > 0 || removed > 0 {
+			stats := vulnIndex.Stats()
+			logger.Sugar().Infof("Loaded %d and removed %d vulnerabilities of the index in %v: %d vulnerabilities, %d packages, %d bytes",
+				loaded, removed, time.Since(started), stats.Vulnerabilities, stats.Packages, stats.Bytes)
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+// GetVulnIndexMetrics godoc
+// @Summary Get the metrics of the vulnerability index
+// @Description Get the size and staleness of the in-memory vulnerability index that GetCVEs uses instead of querying the database per package.
+// @Description The bytes are an approximation of the memory used by the vulnerabilities, while heap_alloc is that of the whole microservice.
+// @Tags vulnindex
+// @Produce json
+// @Success 200
+// @Router /msapi/vulnindex [get]
+func GetVulnIndexMetrics(c *fiber.Ctx) error {
+	var mem runtime.MemStats
+
+	runtime.ReadMemStats(&mem)
+
+	metrics := VulnIndexMetrics{
+		IndexStats: vulnIndex.Stats(),
+		HeapAlloc:  mem.HeapAlloc,
+	}
+
+	if metrics.Ready {
+		metrics.StaleSeconds = time.Since(metrics.Refreshed).Seconds()
+	}
+
+	vulnIndexStatus.Lock()
+	if !vulnIndexStatus.checked.IsZero() {
+		metrics.LastChecked = formatTime(vulnIndexStatus.checked)
+	}
+	if vulnIndexStatus.err != nil {
+		metrics.LastError = vulnIndexStatus.err.Error()
+	}
+	vulnIndexStatus.Unlock()
+
+	return c.JSON(metrics)
+}